@@ -0,0 +1,181 @@
+// Package apitest menjalankan seluruh route API di atas database SQLite
+// sementara untuk pengujian integrasi. Setiap Server memiliki app.App sendiri
+// (database, storage, outbox email dan konfigurasi), sehingga test yang
+// memakai Server dapat dijalankan paralel.
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"forum_asisten/app"
+	"forum_asisten/config"
+	"forum_asisten/mailer"
+	"forum_asisten/migrasi"
+	"forum_asisten/models"
+	"forum_asisten/routes"
+	"forum_asisten/storage"
+	"forum_asisten/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// Server adalah router API lengkap beserta database dan data awalnya.
+type Server struct {
+	t      *testing.T
+	App    *app.App
+	Router *gin.Engine
+	DB     *gorm.DB
+	Mailer *mailer.Memori
+	Data   Fixture
+}
+
+// Baru menyiapkan database SQLite baru yang sudah dimigrasi dan diisi
+// Fixture, lalu memasang route API di atasnya. Konfigurasi bawaan dapat
+// diubah per Server lewat ubah.
+func Baru(t *testing.T, ubah ...func(*config.Config)) *Server {
+	t.Helper()
+
+	cfg := config.Bawaan()
+	cfg.JWTSecret = "rahasia-uji-yang-panjangnya-cukup-32"
+	// Biaya bcrypt minimum agar test yang mengganti password tetap cepat
+	cfg.BcryptCost = bcrypt.MinCost
+	// Jeda login dimatikan agar test login gagal tidak saling menunggu
+	cfg.BatasLogin.JedaAwal = 0
+	for _, u := range ubah {
+		u(&cfg)
+	}
+
+	db, err := config.BukaDB("sqlite", filepath.Join(t.TempDir(), "uji.db"))
+	if err != nil {
+		t.Fatalf("membuka database uji: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatalf("migrasi database uji: %v", err)
+	}
+
+	penyimpanan, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("menyiapkan storage uji: %v", err)
+	}
+	kotakSurat := mailer.NewMemori()
+
+	a := &app.App{
+		DB:      db,
+		Config:  cfg,
+		JWT:     utils.NewJWT(cfg.JWTSecret),
+		Storage: penyimpanan,
+		Mailer:  kotakSurat,
+	}
+
+	r := gin.New()
+	routes.SetupRoutes(r, a)
+
+	s := &Server{t: t, App: a, Router: r, DB: db, Mailer: kotakSurat}
+	s.Data = isiFixture(t, db, cfg.Presensi.Lokasi)
+	return s
+}
+
+// Token menerbitkan access token untuk user tanpa melalui login.
+func (s *Server) Token(user models.User) string {
+	s.t.Helper()
+	nim := ""
+	if user.NIM != nil {
+		nim = *user.NIM
+	}
+	token, err := s.App.JWT.Generate(user.ID, user.Email, user.Nama, nim, user.Role, user.VersiToken, time.Hour)
+	if err != nil {
+		s.t.Fatalf("menerbitkan token: %v", err)
+	}
+	return token
+}
+
+// TokenAdmin dan TokenAsisten adalah token untuk user Fixture.
+func (s *Server) TokenAdmin() string   { return s.Token(s.Data.Admin) }
+func (s *Server) TokenAsisten() string { return s.Token(s.Data.Asisten) }
+
+// Respons adalah hasil satu permintaan ke router.
+type Respons struct {
+	t    *testing.T
+	Kode int
+	Body []byte
+}
+
+// JSON mengurai body respons ke v; test gagal jika body bukan JSON yang sah.
+func (r Respons) JSON(v interface{}) {
+	r.t.Helper()
+	if err := json.Unmarshal(r.Body, v); err != nil {
+		r.t.Fatalf("respons bukan JSON yang sah: %v\n%s", err, r.Body)
+	}
+}
+
+// Harus menggagalkan test jika kode status respons bukan kode.
+func (r Respons) Harus(kode int) Respons {
+	r.t.Helper()
+	if r.Kode != kode {
+		r.t.Fatalf("kode status = %d, want %d\n%s", r.Kode, kode, r.Body)
+	}
+	return r
+}
+
+// Minta mengirim permintaan ke router. body di-encode sebagai JSON jika
+// tidak nil; token kosong berarti tanpa header Authorization.
+func (s *Server) Minta(method, path, token string, body interface{}) Respons {
+	s.t.Helper()
+	var isi io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			s.t.Fatalf("encode body: %v", err)
+		}
+		isi = bytes.NewReader(b)
+	}
+
+	req := httptest.NewRequest(method, path, isi)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	s.Router.ServeHTTP(w, req)
+	return Respons{t: s.t, Kode: w.Code, Body: w.Body.Bytes()}
+}
+
+// Rekap mengambil rekapitulasi asisten pada periode Fixture langsung dari
+// database. Rekap kosong dikembalikan jika belum ada.
+func (s *Server) Rekap(asistenID uint) models.Rekapitulasi {
+	s.t.Helper()
+	var rekap models.Rekapitulasi
+	err := s.DB.Where("asisten_id = ? AND periode_id = ?", asistenID, s.Data.Periode.ID).
+		Limit(1).Find(&rekap).Error
+	if err != nil {
+		s.t.Fatalf("mengambil rekapitulasi: %v", err)
+	}
+	return rekap
+}
+
+// id menulis id sebagai segmen path.
+func id(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
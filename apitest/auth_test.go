@@ -0,0 +1,263 @@
+package apitest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"forum_asisten/config"
+	"forum_asisten/models"
+)
+
+func TestLogin(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	if err := s.DB.Model(&s.Data.Asisten2).Update("status", "non-aktif").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		nama       string
+		identifier string
+		password   string
+		want       int
+	}{
+		{"email", "asisten1@uji.local", Password, http.StatusOK},
+		{"email huruf besar", "ASISTEN1@uji.local", Password, http.StatusOK},
+		{"nim", "2100018001", Password, http.StatusOK},
+		{"password salah", "asisten1@uji.local", "salah", http.StatusUnauthorized},
+		{"user tidak ada", "tidakada@uji.local", Password, http.StatusUnauthorized},
+		{"akun non-aktif", "asisten2@uji.local", Password, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			r := s.Minta("POST", "/api/login", "", map[string]string{
+				"identifier": tt.identifier,
+				"password":   tt.password,
+			}).Harus(tt.want)
+
+			if tt.want != http.StatusOK {
+				return
+			}
+			var hasil struct {
+				Token        string `json:"token"`
+				RefreshToken string `json:"refresh_token"`
+			}
+			r.JSON(&hasil)
+			if hasil.Token == "" || hasil.RefreshToken == "" {
+				t.Fatalf("login berhasil tanpa token: %s", r.Body)
+			}
+			s.Minta("GET", "/api/me", hasil.Token, nil).Harus(http.StatusOK)
+		})
+	}
+}
+
+func TestLoginDikunciSetelahGagalBerulang(t *testing.T) {
+	t.Parallel()
+	s := Baru(t, func(cfg *config.Config) { cfg.BatasLogin.MaksGagal = 3 })
+
+	masuk := func(password string) Respons {
+		return s.Minta("POST", "/api/login", "", map[string]string{
+			"identifier": "asisten1@uji.local",
+			"password":   password,
+		})
+	}
+	for i := 0; i < 3; i++ {
+		masuk("salah").Harus(http.StatusUnauthorized)
+	}
+	// Password benar pun ditolak selama akun terkunci
+	masuk(Password).Harus(http.StatusTooManyRequests)
+
+	s.Minta("PUT", "/api/admin/users/"+id(s.Data.Asisten.ID)+"/unlock", s.TokenAdmin(), nil).Harus(http.StatusOK)
+	masuk(Password).Harus(http.StatusOK)
+}
+
+// TestBukaKunciTermasukIP memastikan membuka kunci akun juga melepas kunci
+// per IP yang terpicu oleh kegagalan login akun itu.
+func TestBukaKunciTermasukIP(t *testing.T) {
+	t.Parallel()
+	s := Baru(t, func(cfg *config.Config) {
+		cfg.BatasLogin.MaksGagal = 0
+		cfg.BatasLogin.MaksGagalIP = 3
+	})
+
+	for i := 0; i < 3; i++ {
+		s.login("ASISTEN1@uji.local", "salah").Harus(http.StatusUnauthorized)
+	}
+	s.login(s.Data.Asisten.Email, Password).Harus(http.StatusTooManyRequests)
+
+	s.Minta("PUT", "/api/admin/users/"+id(s.Data.Asisten.ID)+"/unlock", s.TokenAdmin(), nil).Harus(http.StatusOK)
+	s.login(s.Data.Asisten.Email, Password).Harus(http.StatusOK)
+}
+
+// TestLoginDiblokirTidakMemperpanjangKunci memastikan percobaan selama akun
+// terkunci hanya diaudit, tanpa menambah hitungan atau memperpanjang kunci.
+func TestLoginDiblokirTidakMemperpanjangKunci(t *testing.T) {
+	t.Parallel()
+	s := Baru(t, func(cfg *config.Config) { cfg.BatasLogin.MaksGagal = 3 })
+
+	masuk := func(password string) Respons {
+		return s.Minta("POST", "/api/login", "", map[string]string{
+			"identifier": "asisten1@uji.local",
+			"password":   password,
+		})
+	}
+	for i := 0; i < 3; i++ {
+		masuk("salah").Harus(http.StatusUnauthorized)
+	}
+	var awal models.PercobaanLogin
+	if err := s.DB.Where("kunci = ?", "id:asisten1@uji.local").First(&awal).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		masuk("salah").Harus(http.StatusTooManyRequests)
+	}
+
+	var akhir models.PercobaanLogin
+	s.DB.Where("kunci = ?", "id:asisten1@uji.local").First(&akhir)
+	if akhir.Gagal != 3 || !akhir.TerkunciSampai.Equal(*awal.TerkunciSampai) {
+		t.Errorf("setelah diblokir: gagal %d, terkunci sampai %v; want 3, %v", akhir.Gagal, akhir.TerkunciSampai, awal.TerkunciSampai)
+	}
+	var diblokir int64
+	s.DB.Model(&models.LoginGagal{}).Where("alasan = ?", "diblokir").Count(&diblokir)
+	if diblokir != 2 {
+		t.Errorf("jejak diblokir = %d, want 2", diblokir)
+	}
+}
+
+// TestLoginGagalBersamaan memastikan kegagalan yang datang bersamaan semuanya
+// terhitung.
+func TestLoginGagalBersamaan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t, func(cfg *config.Config) {
+		cfg.BatasLogin.MaksGagal = 0
+		cfg.BatasLogin.MaksGagalIP = 0
+	})
+
+	const n = 8
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Minta("POST", "/api/login", "", map[string]string{
+				"identifier": "tidak.ada@uji.local",
+				"password":   "salah",
+			})
+		}()
+	}
+	wg.Wait()
+
+	var p models.PercobaanLogin
+	if err := s.DB.Where("kunci = ?", "id:tidak.ada@uji.local").First(&p).Error; err != nil {
+		t.Fatal(err)
+	}
+	if p.Gagal != n {
+		t.Errorf("gagal = %d, want %d", p.Gagal, n)
+	}
+
+	// Kegagalan yang sudah lewat masa kunci tidak lagi dihitung
+	s.DB.Model(&p).Update("terakhir_gagal", time.Now().Add(-s.App.Config.BatasLogin.KunciSelama-time.Minute))
+	s.Minta("POST", "/api/login", "", map[string]string{
+		"identifier": "tidak.ada@uji.local",
+		"password":   "salah",
+	}).Harus(http.StatusUnauthorized)
+	s.DB.First(&p, p.ID)
+	if p.Gagal != 1 {
+		t.Errorf("gagal setelah masa kunci lewat = %d, want 1", p.Gagal)
+	}
+}
+
+func TestAksesRoute(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+
+	tests := []struct {
+		nama   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"tanpa token", "GET", "/api/me", "", http.StatusUnauthorized},
+		{"token rusak", "GET", "/api/me", "bukan.token.jwt", http.StatusUnauthorized},
+		{"asisten ke profil", "GET", "/api/me", s.TokenAsisten(), http.StatusOK},
+		{"asisten ke route admin", "GET", "/api/admin/users", s.TokenAsisten(), http.StatusForbidden},
+		{"admin ke route admin", "GET", "/api/admin/users", s.TokenAdmin(), http.StatusOK},
+		{"asisten kelola plotting", "GET", "/api/admin/asisten-kelas", s.TokenAsisten(), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			s.Minta(tt.method, tt.path, tt.token, nil).Harus(tt.want)
+		})
+	}
+}
+
+func TestLogoutMencabutToken(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	token := s.TokenAsisten()
+
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusOK)
+	s.Minta("POST", "/api/logout", token, nil).Harus(http.StatusOK)
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusUnauthorized)
+}
+
+// masuk login sebagai asisten Fixture dan mengembalikan access token beserta
+// refresh token.
+func (s *Server) masuk() (token, refresh string) {
+	s.t.Helper()
+	var hasil struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	s.Minta("POST", "/api/login", "", map[string]string{
+		"identifier": s.Data.Asisten.Email,
+		"password":   Password,
+	}).Harus(http.StatusOK).JSON(&hasil)
+	return hasil.Token, hasil.RefreshToken
+}
+
+func TestNonaktifkanUserMenggugurkanToken(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	admin := s.TokenAdmin()
+	ubahStatus := func(status string) {
+		s.Minta("PUT", "/api/admin/users/"+id(s.Data.Asisten.ID)+"/status", admin,
+			map[string]string{"status": status}).Harus(http.StatusOK)
+	}
+	perbarui := func(refresh string) Respons {
+		return s.Minta("POST", "/api/refresh", "", map[string]string{"refresh_token": refresh})
+	}
+
+	// Status yang tidak berubah tidak mencabut sesi
+	token, refresh := s.masuk()
+	ubahStatus("aktif")
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusOK)
+
+	ubahStatus("non-aktif")
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusUnauthorized)
+	perbarui(refresh).Harus(http.StatusUnauthorized)
+
+	// Mengaktifkan kembali tidak menghidupkan token lama
+	ubahStatus("aktif")
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusUnauthorized)
+	perbarui(refresh).Harus(http.StatusUnauthorized)
+
+	token, refresh = s.masuk()
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusOK)
+	perbarui(refresh).Harus(http.StatusOK)
+}
+
+func TestTokenHanyaBerlakuDiAppPenerbit(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	lain := Baru(t, func(cfg *config.Config) { cfg.JWTSecret = "rahasia-lain" })
+
+	lain.Minta("GET", "/api/me", lain.TokenAsisten(), nil).Harus(http.StatusOK)
+	lain.Minta("GET", "/api/me", s.TokenAsisten(), nil).Harus(http.StatusUnauthorized)
+}
@@ -0,0 +1,51 @@
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+// TestAksesBuktiPresensi memastikan bukti presensi hanya dapat diunduh
+// pemiliknya dan pengelola presensi program studi jadwalnya.
+func TestAksesBuktiPresensi(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+
+	isi := "bukti izin"
+	if err := s.App.Storage.Put(context.Background(), "presensi/uji.pdf", strings.NewReader(isi), int64(len(isi)), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	berkas := models.Berkas{PemilikID: s.Data.Asisten.ID, Kategori: "bukti_izin", Key: "presensi/uji.pdf", ContentType: "application/pdf", Ukuran: int64(len(isi))}
+	if err := s.DB.Create(&berkas).Error; err != nil {
+		t.Fatal(err)
+	}
+	var presensi struct {
+		Data models.Presensi `json:"data"`
+	}
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "izin").Harus(http.StatusCreated).JSON(&presensi)
+	s.DB.Model(&presensi.Data).Update("bukti_izin_id", berkas.ID)
+
+	lain := models.ProgramStudi{Nama: "Sistem Informasi"}
+	s.DB.Create(&lain)
+	koordinator := func(user models.User, prodiID uint) string {
+		if err := s.DB.Create(&models.UserRole{UserID: user.ID, Role: "koordinator", ProgramStudiID: &prodiID}).Error; err != nil {
+			t.Fatal(err)
+		}
+		return s.Token(user)
+	}
+	koordinatorLain := koordinator(s.Data.Asisten2, lain.ID)
+	asisten2 := s.Token(s.Data.Asisten2)
+
+	path := berkas.URL()
+	s.Minta("GET", path, s.TokenAsisten(), nil).Harus(http.StatusOK)
+	s.Minta("GET", path, s.TokenAdmin(), nil).Harus(http.StatusOK)
+	s.Minta("GET", path, koordinatorLain, nil).Harus(http.StatusForbidden)
+
+	koordinator(s.Data.Asisten2, s.Data.ProgramStudi.ID)
+	s.Minta("GET", path, asisten2, nil).Harus(http.StatusOK)
+}
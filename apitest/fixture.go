@@ -0,0 +1,143 @@
+package apitest
+
+import (
+	"testing"
+	"time"
+
+	"forum_asisten/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// Password adalah password semua user Fixture.
+const Password = "Rahasia#Uji2024"
+
+// Fixture adalah data awal setiap Server: satu admin, dua asisten aktif yang
+// belum diplot, satu jadwal pada periode aktif, dan dua pertemuan (hari ini
+// dan besok) yang jendela presensinya mencakup sepanjang hari.
+type Fixture struct {
+	Admin    models.User
+	Asisten  models.User
+	Asisten2 models.User
+
+	ProgramStudi models.ProgramStudi
+	MataKuliah   models.MataKuliah
+	Dosen        models.Dosen
+	Periode      models.Periode
+	Jadwal       models.Jadwal
+
+	PertemuanHariIni models.Pertemuan
+	PertemuanBesok   models.Pertemuan
+}
+
+func isiFixture(t *testing.T, db *gorm.DB, lokasi *time.Location) Fixture {
+	t.Helper()
+
+	// Biaya bcrypt minimum: fixture dibuat ulang di setiap test
+	hash, err := bcrypt.GenerateFromPassword([]byte(Password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("hash password fixture: %v", err)
+	}
+	user := func(nama, email, nim, role string) models.User {
+		return models.User{
+			Nama:     nama,
+			Email:    email,
+			Password: string(hash),
+			Role:     role,
+			NIM:      &nim,
+			Status:   "aktif",
+		}
+	}
+
+	sekarang := time.Now().In(lokasi)
+	hariIni := time.Date(sekarang.Year(), sekarang.Month(), sekarang.Day(), 0, 0, 0, 0, time.UTC)
+
+	f := Fixture{
+		Admin:        user("Admin Lab", "admin@uji.local", "A0001", "admin"),
+		Asisten:      user("Asisten Satu", "asisten1@uji.local", "2100018001", "asisten"),
+		Asisten2:     user("Asisten Dua", "asisten2@uji.local", "2100018002", "asisten"),
+		ProgramStudi: models.ProgramStudi{Nama: "Informatika"},
+		Dosen:        models.Dosen{Nama: "Dr. Dosen Uji"},
+		Periode: models.Periode{
+			Nama:           "Periode Uji",
+			TahunAjaran:    "2025/2026",
+			Semester:       "ganjil",
+			TanggalMulai:   hariIni.AddDate(0, 0, -30),
+			TanggalSelesai: hariIni.AddDate(0, 0, 60),
+			Aktif:          true,
+		},
+	}
+
+	langkah := []func() error{
+		func() error { return db.Create(&f.Admin).Error },
+		func() error { return db.Create(&f.Asisten).Error },
+		func() error { return db.Create(&f.Asisten2).Error },
+		func() error { return db.Create(&f.ProgramStudi).Error },
+		func() error { return db.Create(&f.Dosen).Error },
+		func() error { return db.Create(&f.Periode).Error },
+		func() error {
+			f.MataKuliah = models.MataKuliah{
+				Nama:           "Praktikum Basis Data",
+				Semester:       3,
+				Kode:           "IF301P",
+				ProgramStudiID: f.ProgramStudi.ID,
+				FaktorHonor:    1,
+			}
+			return db.Create(&f.MataKuliah).Error
+		},
+		func() error {
+			f.Jadwal = models.Jadwal{
+				MataKuliahID: f.MataKuliah.ID,
+				DosenID:      f.Dosen.ID,
+				Hari:         "Senin",
+				JamMulai:     "00:00",
+				JamSelesai:   "23:59",
+				Lab:          "Lab 1",
+				Kelas:        "A",
+				Semester:     3,
+				PeriodeID:    f.Periode.ID,
+			}
+			return db.Create(&f.Jadwal).Error
+		},
+		func() error {
+			f.PertemuanHariIni = pertemuan(f.Jadwal, 1, hariIni)
+			return db.Create(&f.PertemuanHariIni).Error
+		},
+		func() error {
+			f.PertemuanBesok = pertemuan(f.Jadwal, 2, hariIni.AddDate(0, 0, 1))
+			return db.Create(&f.PertemuanBesok).Error
+		},
+	}
+	for _, l := range langkah {
+		if err := l(); err != nil {
+			t.Fatalf("mengisi fixture: %v", err)
+		}
+	}
+	return f
+}
+
+func pertemuan(jadwal models.Jadwal, ke int, tanggal time.Time) models.Pertemuan {
+	return models.Pertemuan{
+		JadwalID:   jadwal.ID,
+		PeriodeID:  jadwal.PeriodeID,
+		Ke:         ke,
+		Tanggal:    tanggal,
+		JamMulai:   jadwal.JamMulai,
+		JamSelesai: jadwal.JamSelesai,
+	}
+}
+
+// Plot menugaskan asisten pada jadwal Fixture langsung lewat database.
+func (s *Server) Plot(asisten models.User) models.AsistenKelas {
+	s.t.Helper()
+	plotting := models.AsistenKelas{
+		JadwalID:  s.Data.Jadwal.ID,
+		AsistenID: asisten.ID,
+		PeriodeID: s.Data.Periode.ID,
+	}
+	if err := s.DB.Create(&plotting).Error; err != nil {
+		s.t.Fatalf("membuat plotting: %v", err)
+	}
+	return plotting
+}
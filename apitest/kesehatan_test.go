@@ -0,0 +1,70 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"forum_asisten/versi"
+)
+
+func TestHealthz(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Minta("GET", "/healthz", "", nil).Harus(http.StatusOK)
+}
+
+func TestReadyz(t *testing.T) {
+	t.Parallel()
+
+	type hasil struct {
+		Status  string            `json:"status"`
+		Periksa map[string]string `json:"periksa"`
+	}
+	tests := []struct {
+		nama    string
+		siapkan func(t *testing.T, s *Server)
+		kode    int
+		status  string
+		gagal   string // pemeriksaan yang diharapkan gagal
+	}{
+		{"semua siap", func(t *testing.T, s *Server) {}, http.StatusOK, "siap", ""},
+		{"migrasi tertunda", func(t *testing.T, s *Server) {
+			if err := s.DB.Exec("DELETE FROM schema_migrations WHERE versi = 2").Error; err != nil {
+				t.Fatal(err)
+			}
+		}, http.StatusServiceUnavailable, "tidak siap", "migrasi"},
+		{"database tertutup", func(t *testing.T, s *Server) {
+			if err := s.App.Tutup(); err != nil {
+				t.Fatal(err)
+			}
+		}, http.StatusServiceUnavailable, "tidak siap", "database"},
+		{"server berhenti", func(t *testing.T, s *Server) { s.App.MulaiBerhenti() }, http.StatusServiceUnavailable, "berhenti", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			t.Parallel()
+			s := Baru(t)
+			tt.siapkan(t, s)
+
+			var h hasil
+			s.Minta("GET", "/readyz", "", nil).Harus(tt.kode).JSON(&h)
+			if h.Status != tt.status {
+				t.Fatalf("status = %q, want %q", h.Status, tt.status)
+			}
+			if tt.gagal != "" && h.Periksa[tt.gagal] != "gagal" {
+				t.Fatalf("periksa = %v, want %s gagal", h.Periksa, tt.gagal)
+			}
+		})
+	}
+}
+
+func TestVersi(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+
+	var info versi.Info
+	s.Minta("GET", "/api/versi", "", nil).Harus(http.StatusOK).JSON(&info)
+	if info.Versi != versi.Versi || info.Go == "" {
+		t.Fatalf("versi = %+v", info)
+	}
+}
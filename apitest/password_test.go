@@ -0,0 +1,141 @@
+package apitest
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"forum_asisten/models"
+)
+
+const passwordBaru = "Sandi#Baru2025"
+
+func (s *Server) login(identifier, password string) Respons {
+	return s.Minta("POST", "/api/login", "", map[string]string{"identifier": identifier, "password": password})
+}
+
+var polaTokenReset = regexp.MustCompile(`reset-password\?token=(\S+)`)
+
+// mintaReset meminta tautan reset untuk email lalu mengambil token dari
+// email terakhir di outbox.
+func (s *Server) mintaReset(email string) string {
+	s.t.Helper()
+	s.Minta("POST", "/api/password/forgot", "", map[string]string{"email": email}).Harus(http.StatusOK)
+	pesan := s.Mailer.Pesan()
+	if len(pesan) == 0 {
+		s.t.Fatal("email reset tidak terkirim")
+	}
+	terakhir := pesan[len(pesan)-1]
+	if terakhir.Kepada != email {
+		s.t.Fatalf("email reset dikirim ke %q, want %q", terakhir.Kepada, email)
+	}
+	cocok := polaTokenReset.FindStringSubmatch(terakhir.Isi)
+	if cocok == nil {
+		s.t.Fatalf("email reset tanpa tautan:\n%s", terakhir.Isi)
+	}
+	token, err := url.QueryUnescape(cocok[1])
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return token
+}
+
+func (s *Server) reset(token, password string) Respons {
+	return s.Minta("POST", "/api/password/reset", "", map[string]string{"token": token, "password_baru": password})
+}
+
+func TestChangePassword(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	email := s.Data.Asisten.Email
+	token, refresh := s.masuk()
+
+	ganti := func(lama, baru string) Respons {
+		return s.Minta("POST", "/api/password/change", token, map[string]string{"password_lama": lama, "password_baru": baru})
+	}
+	ganti("SalahLama#2025", passwordBaru).Harus(http.StatusUnauthorized)
+	ganti(Password, Password).Harus(http.StatusBadRequest)
+	ganti(Password, "pendek").Harus(http.StatusBadRequest)
+	s.login(email, Password).Harus(http.StatusOK)
+
+	var hasil struct {
+		Token        string `json:"token"`
+		RefreshToken string `json:"refresh_token"`
+	}
+	ganti(Password, passwordBaru).Harus(http.StatusOK).JSON(&hasil)
+
+	// Sesi lama dicabut, sesi baru dari respons langsung berlaku
+	s.Minta("GET", "/api/me", token, nil).Harus(http.StatusUnauthorized)
+	s.Minta("POST", "/api/refresh", "", map[string]string{"refresh_token": refresh}).Harus(http.StatusUnauthorized)
+	s.Minta("GET", "/api/me", hasil.Token, nil).Harus(http.StatusOK)
+
+	s.login(email, Password).Harus(http.StatusUnauthorized)
+	s.login(email, passwordBaru).Harus(http.StatusOK)
+}
+
+func TestForgotPasswordTidakMembocorkanEmail(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	if err := s.DB.Model(&s.Data.Asisten2).Update("status", "non-aktif").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, email := range []string{"tidakada@uji.local", s.Data.Asisten2.Email} {
+		s.Minta("POST", "/api/password/forgot", "", map[string]string{"email": email}).Harus(http.StatusOK)
+	}
+	if pesan := s.Mailer.Pesan(); len(pesan) != 0 {
+		t.Fatalf("email terkirim untuk akun yang tidak ada/non-aktif: %+v", pesan)
+	}
+}
+
+func TestResetPasswordSekaliPakai(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	email := s.Data.Asisten.Email
+	tokenSesi, _ := s.masuk()
+	token := s.mintaReset(email)
+
+	// Password yang ditolak validasi tidak menghabiskan token
+	s.reset(token, "pendek").Harus(http.StatusBadRequest)
+	s.reset(token, passwordBaru).Harus(http.StatusOK)
+	s.reset(token, "Sandi#Lain2025").Harus(http.StatusBadRequest)
+
+	s.login(email, Password).Harus(http.StatusUnauthorized)
+	s.login(email, passwordBaru).Harus(http.StatusOK)
+	s.Minta("GET", "/api/me", tokenSesi, nil).Harus(http.StatusUnauthorized)
+}
+
+func TestResetPasswordTokenTidakBerlaku(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	email := s.Data.Asisten.Email
+
+	tests := []struct {
+		nama  string
+		token func() string
+	}{
+		{"token tidak dikenal", func() string { return "token-yang-tidak-pernah-diterbitkan" }},
+		{"token kedaluwarsa", func() string {
+			token := s.mintaReset(email)
+			err := s.DB.Model(&models.ResetPassword{}).Where("user_id = ?", s.Data.Asisten.ID).
+				Update("kedaluwarsa_pada", time.Now().Add(-time.Minute)).Error
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}},
+		{"token lama setelah permintaan baru", func() string {
+			lama := s.mintaReset(email)
+			s.mintaReset(email)
+			return lama
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			s.reset(tt.token(), passwordBaru).Harus(http.StatusBadRequest)
+			s.login(email, Password).Harus(http.StatusOK)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package apitest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+// TestSetujuiPenggantian memastikan sesi asisten asal tercatat izin dengan
+// keterangan penggantian, sementara bukti_izin tetap kosong karena hanya
+// berisi URL berkas.
+func TestSetujuiPenggantian(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	pengganti := s.Token(s.Data.Asisten2)
+
+	var hasil struct {
+		Data models.Penggantian `json:"data"`
+	}
+	s.Minta("POST", "/api/penggantian", s.TokenAsisten(), map[string]interface{}{
+		"pertemuan_id":         s.Data.PertemuanBesok.ID,
+		"asisten_pengganti_id": s.Data.Asisten2.ID,
+		"alasan":               "Sakit",
+	}).Harus(http.StatusCreated).JSON(&hasil)
+	penggantianID := id(hasil.Data.ID)
+
+	s.Minta("PUT", "/api/penggantian/"+penggantianID+"/terima", pengganti, nil).Harus(http.StatusOK)
+	s.Minta("PUT", "/api/admin/penggantian/"+penggantianID+"/setujui", s.TokenAdmin(),
+		map[string]string{"catatan": "Disetujui koordinator"}).Harus(http.StatusOK)
+
+	var presensi models.Presensi
+	if err := s.DB.Where("pertemuan_id = ? AND asisten_id = ?", s.Data.PertemuanBesok.ID, s.Data.Asisten.ID).
+		First(&presensi).Error; err != nil {
+		t.Fatal(err)
+	}
+	if presensi.Status != "izin" || presensi.BuktiIzin != "" || presensi.Keterangan != "Digantikan oleh "+s.Data.Asisten2.Nama {
+		t.Fatalf("presensi asal = status %q, bukti_izin %q, keterangan %q", presensi.Status, presensi.BuktiIzin, presensi.Keterangan)
+	}
+
+	var penggantian models.Penggantian
+	s.DB.First(&penggantian, hasil.Data.ID)
+	if penggantian.CatatanAdmin != "Disetujui koordinator" {
+		t.Errorf("catatan_admin = %q", penggantian.CatatanAdmin)
+	}
+}
+
+// TestKeputusanPenggantianBersamaan memastikan pembatalan dan persetujuan
+// yang datang bersamaan tidak keduanya berhasil: yang kalah mendapat 409 dan
+// presensi asal mengikuti keputusan yang menang.
+func TestKeputusanPenggantianBersamaan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+
+	var hasil struct {
+		Data models.Penggantian `json:"data"`
+	}
+	s.Minta("POST", "/api/penggantian", s.TokenAsisten(), map[string]interface{}{
+		"pertemuan_id":         s.Data.PertemuanBesok.ID,
+		"asisten_pengganti_id": s.Data.Asisten2.ID,
+		"alasan":               "Sakit",
+	}).Harus(http.StatusCreated).JSON(&hasil)
+	penggantianID := id(hasil.Data.ID)
+	s.Minta("PUT", "/api/penggantian/"+penggantianID+"/terima", s.Token(s.Data.Asisten2), nil).Harus(http.StatusOK)
+
+	var batal, setujui Respons
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		batal = s.Minta("PUT", "/api/penggantian/"+penggantianID+"/batal", s.TokenAsisten(), nil)
+	}()
+	go func() {
+		defer wg.Done()
+		setujui = s.Minta("PUT", "/api/admin/penggantian/"+penggantianID+"/setujui", s.TokenAdmin(), nil)
+	}()
+	wg.Wait()
+
+	berhasil := func(r Respons) bool { return r.Kode == http.StatusOK }
+	if berhasil(batal) == berhasil(setujui) || batal.Kode != http.StatusConflict && setujui.Kode != http.StatusConflict {
+		t.Fatalf("batal = %d, setujui = %d; want satu 200 dan satu 409", batal.Kode, setujui.Kode)
+	}
+
+	var penggantian models.Penggantian
+	s.DB.First(&penggantian, hasil.Data.ID)
+	var presensi int64
+	s.DB.Model(&models.Presensi{}).
+		Where("pertemuan_id = ? AND asisten_id = ?", s.Data.PertemuanBesok.ID, s.Data.Asisten.ID).Count(&presensi)
+	if want := map[bool]string{true: "dibatalkan", false: "disetujui"}[berhasil(batal)]; penggantian.Status != want {
+		t.Errorf("status = %q, want %q", penggantian.Status, want)
+	}
+	if (penggantian.Status == "disetujui") != (presensi == 1) {
+		t.Errorf("status %q dengan %d presensi asal", penggantian.Status, presensi)
+	}
+}
@@ -0,0 +1,39 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+// TestSusunUlangPertemuan memastikan penyusunan ulang setelah jam jadwal
+// berubah tidak menyentuh pertemuan yang sudah berisi presensi.
+func TestSusunUlangPertemuan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir").Harus(http.StatusCreated)
+
+	if err := s.DB.Model(&s.Data.Jadwal).Updates(map[string]interface{}{"jam_mulai": "09:00", "jam_selesai": "11:00"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	s.Minta("POST", "/api/admin/jadwal/"+id(s.Data.Jadwal.ID)+"/pertemuan", s.TokenAdmin(), nil).Harus(http.StatusOK)
+
+	var list []models.Pertemuan
+	s.DB.Where("jadwal_id = ?", s.Data.Jadwal.ID).Order("tanggal").Find(&list)
+	if len(list) < 2 {
+		t.Fatalf("%d pertemuan tersusun, want setidaknya 2", len(list))
+	}
+	for _, p := range list {
+		if p.ID == s.Data.PertemuanHariIni.ID {
+			if p.Ke != 1 || p.JamMulai != "00:00" || p.JamSelesai != "23:59" {
+				t.Errorf("pertemuan berpresensi berubah: ke %d, %s-%s", p.Ke, p.JamMulai, p.JamSelesai)
+			}
+			continue
+		}
+		if p.JamMulai != "09:00" || p.JamSelesai != "11:00" {
+			t.Errorf("pertemuan %s tidak mengikuti jam baru: %s-%s", p.Tanggal.Format("2006-01-02"), p.JamMulai, p.JamSelesai)
+		}
+	}
+}
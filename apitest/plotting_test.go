@@ -0,0 +1,91 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+func TestPilihJadwalAsisten(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	token := s.TokenAsisten()
+	pilih := func(jadwalID uint) Respons {
+		return s.Minta("POST", "/api/asisten-kelas", token, map[string]uint{"jadwal_id": jadwalID})
+	}
+
+	pilih(s.Data.Jadwal.ID).Harus(http.StatusOK)
+	pilih(s.Data.Jadwal.ID).Harus(http.StatusBadRequest)
+	pilih(9999).Harus(http.StatusBadRequest)
+
+	var jumlah int64
+	s.DB.Model(&models.AsistenKelas{}).Where("asisten_id = ?", s.Data.Asisten.ID).Count(&jumlah)
+	if jumlah != 1 {
+		t.Fatalf("jumlah plotting = %d, want 1", jumlah)
+	}
+}
+
+func TestAdminPlotting(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	admin := s.TokenAdmin()
+	plot := func(asisten models.User) Respons {
+		return s.Minta("POST", "/api/admin/asisten-kelas", admin, map[string]uint{
+			"jadwal_id":  s.Data.Jadwal.ID,
+			"asisten_id": asisten.ID,
+		})
+	}
+	lepas := func(asisten models.User) Respons {
+		return s.Minta("DELETE", "/api/admin/asisten-kelas/"+id(s.Data.Jadwal.ID)+"/"+id(asisten.ID), admin, nil)
+	}
+
+	tests := []struct {
+		nama string
+		aksi func() Respons
+		want int
+	}{
+		{"plot asisten", func() Respons { return plot(s.Data.Asisten) }, http.StatusOK},
+		{"plot ganda ditolak", func() Respons { return plot(s.Data.Asisten) }, http.StatusBadRequest},
+		{"plot asisten kedua", func() Respons { return plot(s.Data.Asisten2) }, http.StatusOK},
+		{"lepas plotting", func() Respons { return lepas(s.Data.Asisten) }, http.StatusOK},
+		{"lepas lagi tidak ditemukan", func() Respons { return lepas(s.Data.Asisten) }, http.StatusNotFound},
+		// Plotting di tempat sampah tidak menghalangi plotting ulang
+		{"plot ulang setelah dilepas", func() Respons { return plot(s.Data.Asisten) }, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			tt.aksi().Harus(tt.want)
+		})
+	}
+
+	var list []models.AsistenKelas
+	s.Minta("GET", "/api/admin/asisten-kelas", admin, nil).Harus(http.StatusOK).JSON(&list)
+	if len(list) != 2 {
+		t.Fatalf("plotting aktif = %d, want 2", len(list))
+	}
+}
+
+func TestAsistenHanyaMelepasPlottingSendiri(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	s.Plot(s.Data.Asisten2)
+
+	path := func(asisten models.User) string {
+		return "/api/asisten-kelas/" + id(s.Data.Jadwal.ID) + "/" + id(asisten.ID)
+	}
+	s.Minta("DELETE", path(s.Data.Asisten2), s.TokenAsisten(), nil).Harus(http.StatusForbidden)
+	s.Minta("DELETE", path(s.Data.Asisten), s.TokenAsisten(), nil).Harus(http.StatusOK)
+}
+
+func TestPlottingPeriodeDitutup(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	if err := s.DB.Model(&s.Data.Periode).Update("ditutup", true).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.Minta("POST", "/api/asisten-kelas", s.TokenAsisten(),
+		map[string]uint{"jadwal_id": s.Data.Jadwal.ID}).Harus(http.StatusConflict)
+}
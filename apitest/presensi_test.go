@@ -0,0 +1,131 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+// isiPresensi mengirim presensi asisten pemilik token pada satu pertemuan.
+func (s *Server) isiPresensi(token string, pertemuan models.Pertemuan, status string) Respons {
+	s.t.Helper()
+	body := map[string]interface{}{"pertemuan_id": pertemuan.ID, "status": status}
+	if status == "hadir" {
+		body["isi_materi"] = "Normalisasi tabel"
+	}
+	return s.Minta("POST", "/api/presensi", token, body)
+}
+
+func TestCreatePresensi(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+
+	tests := []struct {
+		nama      string
+		token     string
+		pertemuan models.Pertemuan
+		status    string
+		want      int
+	}{
+		{"asisten tidak diplot", s.Token(s.Data.Asisten2), s.Data.PertemuanHariIni, "hadir", http.StatusForbidden},
+		{"status tidak dikenal", s.TokenAsisten(), s.Data.PertemuanHariIni, "sakit", http.StatusBadRequest},
+		{"hadir sebelum pertemuan dibuka", s.TokenAsisten(), s.Data.PertemuanBesok, "hadir", http.StatusForbidden},
+		{"hadir hari ini", s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir", http.StatusCreated},
+		{"presensi ganda", s.TokenAsisten(), s.Data.PertemuanHariIni, "izin", http.StatusConflict},
+		{"izin sebelum pertemuan", s.TokenAsisten(), s.Data.PertemuanBesok, "izin", http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			s.isiPresensi(tt.token, tt.pertemuan, tt.status).Harus(tt.want)
+		})
+	}
+}
+
+// TestCounterRekapitulasi memastikan setiap perubahan presensi langsung
+// tercermin pada counter rekapitulasi asisten.
+func TestCounterRekapitulasi(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	admin := s.TokenAdmin()
+
+	var presensiID uint
+	tests := []struct {
+		nama               string
+		aksi               func() Respons
+		kode               int
+		hadir, izin, alpha int
+	}{
+		{
+			"hadir",
+			func() Respons {
+				r := s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir")
+				var hasil struct {
+					Data models.Presensi `json:"data"`
+				}
+				if r.Kode == http.StatusCreated {
+					r.JSON(&hasil)
+					presensiID = hasil.Data.ID
+				}
+				return r
+			},
+			http.StatusCreated, 1, 0, 0,
+		},
+		{
+			"izin pertemuan berikutnya",
+			func() Respons { return s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanBesok, "izin") },
+			http.StatusCreated, 1, 1, 0,
+		},
+		{
+			"admin ubah hadir menjadi alpha",
+			func() Respons {
+				return s.Minta("PUT", "/api/admin/presensi/"+id(presensiID), admin, map[string]string{"status": "alpha"})
+			},
+			http.StatusOK, 0, 1, 1,
+		},
+		{
+			"admin hapus presensi",
+			func() Respons { return s.Minta("DELETE", "/api/admin/presensi/"+id(presensiID), admin, nil) },
+			http.StatusOK, 0, 1, 0,
+		},
+		{
+			"pulihkan presensi dari tempat sampah",
+			func() Respons {
+				return s.Minta("PUT", "/api/admin/sampah/presensi/"+id(presensiID)+"/pulihkan", admin, nil)
+			},
+			http.StatusOK, 0, 1, 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			tt.aksi().Harus(tt.kode)
+
+			rekap := s.Rekap(s.Data.Asisten.ID)
+			if rekap.JumlahHadir != tt.hadir || rekap.JumlahIzin != tt.izin || rekap.JumlahAlpha != tt.alpha {
+				t.Fatalf("counter = hadir %d, izin %d, alpha %d; want %d, %d, %d",
+					rekap.JumlahHadir, rekap.JumlahIzin, rekap.JumlahAlpha, tt.hadir, tt.izin, tt.alpha)
+			}
+		})
+	}
+}
+
+func TestIsiUlangPresensiSetelahDihapus(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+
+	var hasil struct {
+		Data models.Presensi `json:"data"`
+	}
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "izin").Harus(http.StatusCreated).JSON(&hasil)
+	s.Minta("DELETE", "/api/admin/presensi/"+id(hasil.Data.ID), s.TokenAdmin(), nil).Harus(http.StatusOK)
+
+	// Presensi di tempat sampah tidak menghalangi pengisian ulang
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir").Harus(http.StatusCreated)
+	if rekap := s.Rekap(s.Data.Asisten.ID); rekap.JumlahHadir != 1 || rekap.JumlahIzin != 0 {
+		t.Fatalf("counter = hadir %d, izin %d; want 1, 0", rekap.JumlahHadir, rekap.JumlahIzin)
+	}
+}
@@ -0,0 +1,96 @@
+package apitest
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+var polaTokenEmail = regexp.MustCompile(`konfirmasi-email\?token=(\S+)`)
+
+// TestGantiEmailPerluKonfirmasi memastikan PUT /me tidak langsung mengganti
+// email: alamat baru berlaku setelah tautan di kotak masuknya dibuka.
+func TestGantiEmailPerluKonfirmasi(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	token := s.TokenAsisten()
+	lama := s.Data.Asisten.Email
+	baru := "asisten.baru@uji.local"
+
+	profil := func() string {
+		var hasil struct {
+			Data struct {
+				Email string `json:"email"`
+			} `json:"data"`
+		}
+		s.Minta("GET", "/api/me", token, nil).Harus(http.StatusOK).JSON(&hasil)
+		return hasil.Data.Email
+	}
+
+	var hasil struct {
+		Menunggu string `json:"email_menunggu_konfirmasi"`
+	}
+	s.Minta("PUT", "/api/me", token, map[string]string{"email": baru, "nama": "Asisten Ganti"}).
+		Harus(http.StatusOK).JSON(&hasil)
+	if hasil.Menunggu != baru {
+		t.Errorf("email_menunggu_konfirmasi = %q, want %q", hasil.Menunggu, baru)
+	}
+	if got := profil(); got != lama {
+		t.Fatalf("email berubah sebelum konfirmasi: %q", got)
+	}
+
+	pesan := s.Mailer.Pesan()
+	if len(pesan) == 0 || pesan[len(pesan)-1].Kepada != baru {
+		t.Fatalf("email konfirmasi tidak dikirim ke %s: %+v", baru, pesan)
+	}
+	cocok := polaTokenEmail.FindStringSubmatch(pesan[len(pesan)-1].Isi)
+	if cocok == nil {
+		t.Fatalf("email konfirmasi tanpa tautan:\n%s", pesan[len(pesan)-1].Isi)
+	}
+	tokenEmail, _ := url.QueryUnescape(cocok[1])
+
+	konfirmasi := func(tokenEmail string) Respons {
+		return s.Minta("POST", "/api/email/konfirmasi", "", map[string]string{"token": tokenEmail})
+	}
+	konfirmasi("bukan-token").Harus(http.StatusBadRequest)
+	konfirmasi(tokenEmail).Harus(http.StatusOK)
+	konfirmasi(tokenEmail).Harus(http.StatusBadRequest)
+	if got := profil(); got != baru {
+		t.Errorf("email setelah konfirmasi = %q, want %q", got, baru)
+	}
+	s.login(baru, Password).Harus(http.StatusOK)
+	s.login(lama, Password).Harus(http.StatusUnauthorized)
+}
+
+// TestAuditPerubahanUser memastikan pembuatan user oleh admin dan perubahan
+// profil sendiri tercatat di audit log beserta pelakunya.
+func TestAuditPerubahanUser(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+
+	s.Minta("POST", "/api/admin/users", s.TokenAdmin(), map[string]string{
+		"nama": "Asisten Baru", "email": "asisten.baru@uji.local", "password": passwordBaru,
+	}).Harus(http.StatusOK)
+	s.Minta("PUT", "/api/me", s.TokenAsisten(), map[string]string{"nama": "Asisten Diubah"}).Harus(http.StatusOK)
+
+	tests := []struct {
+		aksi   string
+		pelaku uint
+	}{
+		{"buat", s.Data.Admin.ID},
+		{"ubah_profil", s.Data.Asisten.ID},
+	}
+	for _, tt := range tests {
+		var entri models.AuditLog
+		if err := s.DB.Where("entitas = ? AND aksi = ?", "user", tt.aksi).First(&entri).Error; err != nil {
+			t.Errorf("audit %s tidak tercatat: %v", tt.aksi, err)
+			continue
+		}
+		if entri.UserID == nil || *entri.UserID != tt.pelaku {
+			t.Errorf("audit %s oleh %v, want %d", tt.aksi, entri.UserID, tt.pelaku)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"forum_asisten/models"
+)
+
+// TestTotalHonorTersimpan memastikan total honor hasil hitungan ikut tersimpan
+// di database, bukan hanya pada respons.
+func TestTotalHonorTersimpan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	// Menggantikan tarif awal dari migrasi
+	tarif := models.TarifHonor{Kode: "A", Nominal: 50000, BerlakuMulai: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := s.DB.Create(&tarif).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir").Harus(http.StatusCreated)
+	s.Minta("POST", "/api/admin/rekapitulasi", s.TokenAdmin(), map[string]interface{}{
+		"asisten_id": s.Data.Asisten.ID,
+		"tipe_honor": "A",
+	}).Harus(http.StatusOK)
+
+	rekap := s.Rekap(s.Data.Asisten.ID)
+	if rekap.TotalHonor != 50000 || rekap.RincianHonor.Total != 50000 {
+		t.Fatalf("total_honor tersimpan = %d, rincian %d; want 50000", rekap.TotalHonor, rekap.RincianHonor.Total)
+	}
+}
+
+// TestUpdateRekapitulasiMenolakCounter memastikan counter tidak dapat diubah
+// lewat API karena selalu dihitung ulang dari presensi.
+func TestUpdateRekapitulasiMenolakCounter(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	s.Plot(s.Data.Asisten)
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "hadir").Harus(http.StatusCreated)
+	rekap := s.Rekap(s.Data.Asisten.ID)
+	admin := s.TokenAdmin()
+
+	s.Minta("PUT", "/api/admin/rekapitulasi/"+id(rekap.ID), admin, map[string]interface{}{
+		"asisten_id":   s.Data.Asisten.ID,
+		"jumlah_hadir": 10,
+	}).Harus(http.StatusBadRequest)
+	s.Minta("PUT", "/api/admin/rekapitulasi/"+id(rekap.ID), admin, map[string]interface{}{
+		"asisten_id":  s.Data.Asisten.ID,
+		"jumlah_izin": 0,
+	}).Harus(http.StatusBadRequest)
+
+	s.Minta("PUT", "/api/admin/rekapitulasi/"+id(rekap.ID), admin, map[string]interface{}{
+		"asisten_id": s.Data.Asisten.ID,
+	}).Harus(http.StatusOK)
+	if got := s.Rekap(s.Data.Asisten.ID); got.JumlahHadir != 1 {
+		t.Fatalf("jumlah_hadir = %d, want 1", got.JumlahHadir)
+	}
+}
@@ -0,0 +1,75 @@
+package apitest
+
+import (
+	"net/http"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+func TestTambahRoleUser(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	admin := s.TokenAdmin()
+	path := "/api/admin/users/" + id(s.Data.Asisten.ID) + "/roles"
+	prodi := s.Data.ProgramStudi.ID
+
+	tests := []struct {
+		nama string
+		body map[string]interface{}
+		want int
+	}{
+		{"admin per prodi", map[string]interface{}{"role": "admin", "program_studi_id": prodi}, http.StatusBadRequest},
+		{"admin semua prodi", map[string]interface{}{"role": "admin"}, http.StatusBadRequest},
+		{"asisten", map[string]interface{}{"role": "asisten", "program_studi_id": prodi}, http.StatusBadRequest},
+		{"koordinator prodi", map[string]interface{}{"role": "koordinator", "program_studi_id": prodi}, http.StatusCreated},
+		{"koordinator ganda", map[string]interface{}{"role": "koordinator", "program_studi_id": prodi}, http.StatusConflict},
+		{"koordinator prodi tidak ada", map[string]interface{}{"role": "koordinator", "program_studi_id": 999}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			s.Minta("POST", path, admin, tt.body).Harus(tt.want)
+		})
+	}
+
+	// Koordinator tidak mendapat izin mengelola role
+	s.Minta("POST", "/api/admin/users/"+id(s.Data.Asisten2.ID)+"/roles", s.TokenAsisten(),
+		map[string]interface{}{"role": "koordinator"}).Harus(http.StatusForbidden)
+}
+
+// TestRoleTambahanLamaDiabaikan memastikan baris user_roles berisi role
+// selain koordinator, mis. dari data lama, tidak memberi izin apa pun.
+func TestRoleTambahanLamaDiabaikan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	prodi := s.Data.ProgramStudi.ID
+	if err := s.DB.Create(&models.UserRole{UserID: s.Data.Asisten.ID, Role: "admin", ProgramStudiID: &prodi}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s.Minta("POST", "/api/admin/users/"+id(s.Data.Asisten.ID)+"/roles", s.TokenAsisten(),
+		map[string]interface{}{"role": "koordinator"}).Harus(http.StatusForbidden)
+	s.Minta("GET", "/api/admin/presensi", s.TokenAsisten(), nil).Harus(http.StatusForbidden)
+}
+
+// TestPerubahanRoleUtamaLangsungBerlaku memastikan admin yang diturunkan
+// kehilangan akses admin tanpa menunggu tokennya kedaluwarsa.
+func TestPerubahanRoleUtamaLangsungBerlaku(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	path := "/api/admin/users/" + id(s.Data.Asisten.ID)
+
+	s.Minta("PUT", path, s.TokenAdmin(), map[string]string{"role": "admin"}).Harus(http.StatusOK)
+	token, _ := s.masuk()
+	s.Minta("GET", "/api/admin/users", token, nil).Harus(http.StatusOK)
+
+	s.Minta("PUT", path, s.TokenAdmin(), map[string]string{"role": "asisten"}).Harus(http.StatusOK)
+	s.Minta("GET", "/api/admin/users", token, nil).Harus(http.StatusUnauthorized)
+
+	// Role pada token tidak dipercaya meski sesi belum dicabut
+	var user models.User
+	s.DB.First(&user, s.Data.Asisten2.ID)
+	user.Role = "admin"
+	basi := s.Token(user)
+	s.Minta("GET", "/api/admin/users", basi, nil).Harus(http.StatusForbidden)
+}
@@ -0,0 +1,98 @@
+package apitest
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+// TestUnikTermasukSampah memastikan nilai unik milik data di tempat sampah
+// ditolak dengan 409 (bukan galat indeks unik dari database) dan admin
+// diarahkan ke endpoint pemulihan.
+func TestUnikTermasukSampah(t *testing.T) {
+	t.Parallel()
+	s, penyedia := siapkanSSO(t)
+	admin := s.TokenAdmin()
+
+	t.Run("email user", func(t *testing.T) {
+		email := s.Data.Asisten2.Email
+		daftar := map[string]string{"nama": "Asisten Baru", "email": email, "password": passwordBaru}
+
+		s.Minta("POST", "/api/register", "", map[string]string{
+			"nama": "Asisten Baru", "email": s.Data.Asisten.Email, "password": passwordBaru,
+		}).Harus(http.StatusConflict)
+
+		s.Minta("DELETE", "/api/admin/users/"+id(s.Data.Asisten2.ID), admin, nil).Harus(http.StatusOK)
+		s.Minta("POST", "/api/register", "", daftar).Harus(http.StatusConflict)
+
+		var hasil struct {
+			TerhapusID uint   `json:"terhapus_id"`
+			Pulihkan   string `json:"pulihkan"`
+		}
+		s.Minta("POST", "/api/admin/users", admin, daftar).Harus(http.StatusConflict).JSON(&hasil)
+		if hasil.TerhapusID != s.Data.Asisten2.ID {
+			t.Errorf("terhapus_id = %d, want %d", hasil.TerhapusID, s.Data.Asisten2.ID)
+		}
+		s.Minta("PUT", "/api/admin/users/"+id(s.Data.Asisten.ID), admin, map[string]string{"email": email}).
+			Harus(http.StatusConflict)
+
+		// SSO tidak membuat akun baru untuk email di tempat sampah
+		lokasi, cookie := s.mulaiSSO()
+		kode, err := penyedia.Otorisasi(lokasi, map[string]any{"email": email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, _ := url.Parse(lokasi)
+		query := url.Values{"state": {u.Query().Get("state")}, "code": {kode}}
+		if got := s.callbackSSO(query, cookie); got.Get("error") != "akun_dihapus" {
+			t.Errorf("callback SSO = %v, want error akun_dihapus", got)
+		}
+
+		s.Minta("PUT", hasil.Pulihkan, admin, nil).Harus(http.StatusOK)
+		s.login(email, Password).Harus(http.StatusOK)
+	})
+
+	t.Run("nama program studi", func(t *testing.T) {
+		var prodi struct {
+			ID uint `json:"id"`
+		}
+		s.Minta("POST", "/api/admin/program-studi", admin, map[string]string{"nama": "Sistem Informasi"}).
+			Harus(http.StatusCreated).JSON(&prodi)
+		s.Minta("POST", "/api/admin/program-studi", admin, map[string]string{"nama": "Sistem Informasi"}).
+			Harus(http.StatusConflict)
+
+		s.Minta("DELETE", "/api/admin/program-studi/"+id(prodi.ID), admin, nil).Harus(http.StatusOK)
+		var hasil struct {
+			Pulihkan string `json:"pulihkan"`
+		}
+		s.Minta("POST", "/api/admin/program-studi", admin, map[string]string{"nama": "Sistem Informasi"}).
+			Harus(http.StatusConflict).JSON(&hasil)
+		s.Minta("PUT", "/api/admin/program-studi/"+id(s.Data.ProgramStudi.ID), admin, map[string]string{"nama": "Sistem Informasi"}).
+			Harus(http.StatusConflict)
+		// Mengubah data tanpa mengganti nilai uniknya tetap boleh
+		s.Minta("PUT", "/api/admin/program-studi/"+id(s.Data.ProgramStudi.ID), admin, map[string]string{"nama": s.Data.ProgramStudi.Nama}).
+			Harus(http.StatusOK)
+
+		s.Minta("PUT", hasil.Pulihkan, admin, nil).Harus(http.StatusOK)
+	})
+}
+
+// TestProfilUnikTermasukSampah memastikan PUT /me juga menolak email dan NIM
+// milik user di tempat sampah dengan 409, bukan galat indeks unik.
+func TestProfilUnikTermasukSampah(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	token := s.TokenAsisten()
+	nim := "2201001"
+
+	s.Minta("PUT", "/api/me", s.Token(s.Data.Asisten2), map[string]string{"nim": nim}).Harus(http.StatusOK)
+	s.Minta("PUT", "/api/me", token, map[string]string{"nim": nim}).Harus(http.StatusConflict)
+
+	s.Minta("DELETE", "/api/admin/users/"+id(s.Data.Asisten2.ID), s.TokenAdmin(), nil).Harus(http.StatusOK)
+	s.Minta("PUT", "/api/me", token, map[string]string{"nim": nim}).Harus(http.StatusConflict)
+	s.Minta("PUT", "/api/me", token, map[string]string{"email": s.Data.Asisten2.Email}).Harus(http.StatusConflict)
+	if pesan := s.Mailer.Pesan(); len(pesan) != 0 {
+		t.Errorf("email konfirmasi dikirim untuk email milik akun lain: %+v", pesan)
+	}
+	s.Minta("PUT", "/api/me", token, map[string]string{"nim": ""}).Harus(http.StatusOK)
+}
@@ -0,0 +1,217 @@
+package apitest
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"forum_asisten/models"
+)
+
+// siapkanSanggah membuat presensi izin milik asisten Fixture beserta
+// rekapitulasinya, lalu mengembalikan keduanya.
+func siapkanSanggah(s *Server) (models.Presensi, models.Rekapitulasi) {
+	s.t.Helper()
+	s.Plot(s.Data.Asisten)
+
+	var hasil struct {
+		Data models.Presensi `json:"data"`
+	}
+	s.isiPresensi(s.TokenAsisten(), s.Data.PertemuanHariIni, "izin").Harus(http.StatusCreated).JSON(&hasil)
+	return hasil.Data, s.Rekap(s.Data.Asisten.ID)
+}
+
+func TestBuatSanggah(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	presensi, rekap := siapkanSanggah(s)
+
+	tests := []struct {
+		nama  string
+		token string
+		body  map[string]interface{}
+		want  int
+	}{
+		{
+			"rekap milik asisten lain",
+			s.Token(s.Data.Asisten2),
+			map[string]interface{}{"rekapitulasi_id": rekap.ID, "isi_sanggahan": "Saya hadir"},
+			http.StatusForbidden,
+		},
+		{
+			"rekap tidak ada",
+			s.TokenAsisten(),
+			map[string]interface{}{"rekapitulasi_id": 9999, "isi_sanggahan": "Saya hadir"},
+			http.StatusBadRequest,
+		},
+		{
+			"tanpa isi",
+			s.TokenAsisten(),
+			map[string]interface{}{"rekapitulasi_id": rekap.ID},
+			http.StatusBadRequest,
+		},
+		{
+			"usulan status tidak dikenal",
+			s.TokenAsisten(),
+			map[string]interface{}{
+				"rekapitulasi_id": rekap.ID,
+				"isi_sanggahan":   "Saya hadir",
+				"presensi":        []map[string]interface{}{{"presensi_id": presensi.ID, "status_usulan": "sakit"}},
+			},
+			http.StatusBadRequest,
+		},
+		{
+			"sanggahan sah",
+			s.TokenAsisten(),
+			map[string]interface{}{
+				"rekapitulasi_id": rekap.ID,
+				"isi_sanggahan":   "Saya hadir, bukan izin",
+				"presensi":        []map[string]interface{}{{"presensi_id": presensi.ID, "status_usulan": "hadir"}},
+			},
+			http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			s.Minta("POST", "/api/sanggah", tt.token, tt.body).Harus(tt.want)
+		})
+	}
+}
+
+// TestAlurSanggah menjalankan sanggahan dari diajukan sampai diterima dan
+// memastikan koreksinya diterapkan ke presensi dan rekapitulasi.
+func TestAlurSanggah(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	presensi, rekap := siapkanSanggah(s)
+	admin := s.TokenAdmin()
+
+	var dibuat struct {
+		Data models.Sanggah `json:"data"`
+	}
+	s.Minta("POST", "/api/sanggah", s.TokenAsisten(), map[string]interface{}{
+		"rekapitulasi_id": rekap.ID,
+		"isi_sanggahan":   "Saya hadir, bukan izin",
+		"presensi":        []map[string]interface{}{{"presensi_id": presensi.ID, "status_usulan": "hadir"}},
+	}).Harus(http.StatusOK).JSON(&dibuat)
+	sanggahPath := "/api/sanggah/" + id(dibuat.Data.ID)
+	statusPath := "/api/admin/sanggah/" + id(dibuat.Data.ID) + "/status"
+
+	tests := []struct {
+		nama string
+		aksi func() Respons
+		want int
+	}{
+		{"asisten lain tidak bisa melihat", func() Respons {
+			return s.Minta("GET", sanggahPath, s.Token(s.Data.Asisten2), nil)
+		}, http.StatusForbidden},
+		{"pemilik melihat", func() Respons {
+			return s.Minta("GET", sanggahPath, s.TokenAsisten(), nil)
+		}, http.StatusOK},
+		{"admin membalas", func() Respons {
+			return s.Minta("POST", sanggahPath+"/balasan", admin, map[string]string{"isi": "Sedang dicek"})
+		}, http.StatusCreated},
+		{"asisten tidak bisa memutuskan", func() Respons {
+			return s.Minta("PUT", statusPath, s.TokenAsisten(), map[string]string{"status": "diterima"})
+		}, http.StatusForbidden},
+		{"admin menerima", func() Respons {
+			return s.Minta("PUT", statusPath, admin, map[string]string{"status": "diterima", "catatan": "Bukti valid"})
+		}, http.StatusOK},
+		{"sanggahan selesai tidak bisa diubah", func() Respons {
+			return s.Minta("PUT", statusPath, admin, map[string]string{"status": "ditolak"})
+		}, http.StatusConflict},
+		{"sanggahan selesai tidak bisa dibalas", func() Respons {
+			return s.Minta("POST", sanggahPath+"/balasan", s.TokenAsisten(), map[string]string{"isi": "Terima kasih"})
+		}, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			tt.aksi().Harus(tt.want)
+		})
+	}
+
+	var hasil models.Sanggah
+	s.Minta("GET", sanggahPath, s.TokenAsisten(), nil).Harus(http.StatusOK).JSON(&hasil)
+	if hasil.Status != "diterima" || len(hasil.Balasan) != 2 {
+		t.Fatalf("sanggahan = status %q, %d balasan; want diterima, 2", hasil.Status, len(hasil.Balasan))
+	}
+
+	var dikoreksi models.Presensi
+	s.DB.First(&dikoreksi, presensi.ID)
+	if dikoreksi.Status != "hadir" {
+		t.Fatalf("status presensi = %q, want hadir", dikoreksi.Status)
+	}
+	if r := s.Rekap(s.Data.Asisten.ID); r.JumlahHadir != 1 || r.JumlahIzin != 0 {
+		t.Fatalf("counter = hadir %d, izin %d; want 1, 0", r.JumlahHadir, r.JumlahIzin)
+	}
+}
+
+// ajukanSanggah menyiapkan presensi izin, mengajukan koreksinya menjadi
+// hadir, lalu mengembalikan path untuk mengubah status sanggahannya.
+func ajukanSanggah(s *Server) string {
+	s.t.Helper()
+	presensi, rekap := siapkanSanggah(s)
+	var dibuat struct {
+		Data models.Sanggah `json:"data"`
+	}
+	s.Minta("POST", "/api/sanggah", s.TokenAsisten(), map[string]interface{}{
+		"rekapitulasi_id": rekap.ID,
+		"isi_sanggahan":   "Saya hadir, bukan izin",
+		"presensi":        []map[string]interface{}{{"presensi_id": presensi.ID, "status_usulan": "hadir"}},
+	}).Harus(http.StatusOK).JSON(&dibuat)
+	return "/api/admin/sanggah/" + id(dibuat.Data.ID) + "/status"
+}
+
+// TestKeputusanSanggahBersamaan memastikan dua keputusan yang datang
+// bersamaan tidak sama-sama diterapkan.
+func TestKeputusanSanggahBersamaan(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	statusPath := ajukanSanggah(s)
+	admin := s.TokenAdmin()
+
+	hasil := make([]Respons, 2)
+	var wg sync.WaitGroup
+	for i, status := range []string{"diterima", "ditolak"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			hasil[i] = s.Minta("PUT", statusPath, admin, map[string]string{"status": status})
+		}()
+	}
+	wg.Wait()
+
+	berhasil := 0
+	for _, r := range hasil {
+		switch r.Kode {
+		case http.StatusOK:
+			berhasil++
+		case http.StatusConflict:
+		default:
+			t.Errorf("kode = %d, body %s", r.Kode, r.Body)
+		}
+	}
+	if berhasil != 1 {
+		t.Errorf("%d keputusan berhasil, want 1", berhasil)
+	}
+}
+
+// TestSanggahPresensiTerhapus memastikan sanggahan atas presensi yang sudah
+// dihapus ditolak dengan 409 tanpa membocorkan galat internal.
+func TestSanggahPresensiTerhapus(t *testing.T) {
+	t.Parallel()
+	s := Baru(t)
+	statusPath := ajukanSanggah(s)
+	var presensi models.Presensi
+	s.DB.Where("asisten_id = ?", s.Data.Asisten.ID).First(&presensi)
+
+	if err := s.DB.Delete(&models.Presensi{}, presensi.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	var hasil map[string]interface{}
+	s.Minta("PUT", statusPath, s.TokenAdmin(), map[string]string{"status": "diterima"}).
+		Harus(http.StatusConflict).JSON(&hasil)
+	if _, ada := hasil["detail"]; ada {
+		t.Errorf("respons membawa detail galat: %v", hasil)
+	}
+}
@@ -0,0 +1,127 @@
+package apitest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"forum_asisten/config"
+	"forum_asisten/sso"
+	"forum_asisten/sso/ssotest"
+)
+
+// siapkanSSO memasang klien OIDC yang mengarah ke penyedia tiruan.
+func siapkanSSO(t *testing.T) (*Server, *ssotest.Penyedia) {
+	t.Helper()
+	penyedia, err := ssotest.Baru("forum-asisten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(penyedia.Close)
+
+	klien := sso.Config{
+		Issuer:      penyedia.URL,
+		ClientID:    "forum-asisten",
+		RedirectURL: "http://localhost/api/sso/callback",
+		KlaimNIM:    "nim",
+	}
+	s := Baru(t, func(cfg *config.Config) {
+		cfg.SSO.Klien = klien
+		cfg.FrontendURL = "http://frontend.uji"
+	})
+	if s.App.SSO, err = sso.New(context.Background(), klien); err != nil {
+		t.Fatal(err)
+	}
+	return s, penyedia
+}
+
+// mulaiSSO memanggil /api/sso/login dan mengembalikan URL otorisasi beserta
+// cookie state yang dipasang untuk browser.
+func (s *Server) mulaiSSO() (string, *http.Cookie) {
+	s.t.Helper()
+	w := httptest.NewRecorder()
+	s.Router.ServeHTTP(w, httptest.NewRequest("GET", "/api/sso/login", nil))
+	if w.Code != http.StatusFound {
+		s.t.Fatalf("kode status = %d, want %d\n%s", w.Code, http.StatusFound, w.Body)
+	}
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "sso_state" {
+			return w.Header().Get("Location"), c
+		}
+	}
+	s.t.Fatal("cookie sso_state tidak dipasang")
+	return "", nil
+}
+
+// callbackSSO memanggil /api/sso/callback dan mengembalikan isi fragment URL
+// redirect ke frontend.
+func (s *Server) callbackSSO(query url.Values, cookie *http.Cookie) url.Values {
+	s.t.Helper()
+	req := httptest.NewRequest("GET", "/api/sso/callback?"+query.Encode(), nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	s.Router.ServeHTTP(w, req)
+
+	lokasi, err := url.Parse(w.Header().Get("Location"))
+	if w.Code != http.StatusFound || err != nil || lokasi.Path != "/sso/callback" {
+		s.t.Fatalf("callback = %d ke %q, want redirect ke /sso/callback", w.Code, w.Header().Get("Location"))
+	}
+	hasil, err := url.ParseQuery(lokasi.Fragment)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return hasil
+}
+
+func TestSSOCookieState(t *testing.T) {
+	t.Parallel()
+	s, penyedia := siapkanSSO(t)
+	klaim := map[string]any{"email": "asisten1@uji.local"}
+
+	mulai := func() (url.Values, *http.Cookie) {
+		lokasi, cookie := s.mulaiSSO()
+		if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/api/sso/callback" {
+			t.Errorf("cookie = %+v, want HttpOnly, SameSite Lax, path callback", cookie)
+		}
+		kode, err := penyedia.Otorisasi(lokasi, klaim)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, _ := url.Parse(lokasi)
+		return url.Values{"state": {u.Query().Get("state")}, "code": {kode}}, cookie
+	}
+
+	t.Run("tanpa cookie", func(t *testing.T) {
+		query, _ := mulai()
+		if got := s.callbackSSO(query, nil); got.Get("error") != "sso_tidak_valid" {
+			t.Errorf("hasil = %v, want error sso_tidak_valid", got)
+		}
+	})
+
+	t.Run("cookie milik login lain", func(t *testing.T) {
+		// Penyerang memulai login sendiri lalu mengirim callback-nya ke korban
+		query, _ := mulai()
+		_, cookieKorban := mulai()
+		if got := s.callbackSSO(query, cookieKorban); got.Get("error") != "sso_tidak_valid" {
+			t.Errorf("hasil = %v, want error sso_tidak_valid", got)
+		}
+	})
+
+	t.Run("cookie cocok", func(t *testing.T) {
+		query, cookie := mulai()
+		got := s.callbackSSO(query, cookie)
+		if got.Get("token") == "" || got.Get("refresh_token") == "" {
+			t.Fatalf("hasil = %v, want token", got)
+		}
+		s.Minta("GET", "/api/me", got.Get("token"), nil).Harus(http.StatusOK)
+
+		// State sekali pakai meskipun cookie masih dikirim ulang
+		if got := s.callbackSSO(query, cookie); got.Get("error") != "sso_tidak_valid" {
+			t.Errorf("callback ulang = %v, want error sso_tidak_valid", got)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+// Package app merangkai dependensi yang dipakai handler: database,
+// konfigurasi, penanda tangan JWT, storage, mailer dan klien SSO. Tidak ada
+// state global, sehingga beberapa App dengan database dan konfigurasi
+// berbeda dapat berjalan dalam satu proses.
+package app
+
+import (
+	"sync/atomic"
+
+	"forum_asisten/config"
+	"forum_asisten/mailer"
+	"forum_asisten/sso"
+	"forum_asisten/storage"
+	"forum_asisten/utils"
+
+	"gorm.io/gorm"
+)
+
+// App adalah wadah dependensi server.
+type App struct {
+	DB      *gorm.DB
+	Config  config.Config
+	JWT     *utils.JWT
+	Storage storage.Storage
+	Mailer  mailer.Mailer
+	SSO     *sso.Klien // nil jika login OIDC tidak dikonfigurasi
+
+	berhenti atomic.Bool
+}
+
+// New menyiapkan App dari konfigurasi yang sudah divalidasi: membuka database
+// (menolak skema yang belum dimigrasi), lalu storage, mailer dan SSO.
+func New(cfg config.Config) (*App, error) {
+	db, err := config.InitDB(cfg.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	penyimpanan, err := config.InitStorage(cfg.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	kotakSurat, err := config.InitMailer(cfg.Mail)
+	if err != nil {
+		return nil, err
+	}
+
+	return &App{
+		DB:      db,
+		Config:  cfg,
+		JWT:     utils.NewJWT(cfg.JWTSecret),
+		Storage: penyimpanan,
+		Mailer:  kotakSurat,
+		SSO:     config.InitSSO(cfg.SSO),
+	}, nil
+}
+
+// MulaiBerhenti menandai server sedang dimatikan sehingga pemeriksaan
+// readiness gagal selama request yang berjalan diselesaikan.
+func (a *App) MulaiBerhenti() {
+	a.berhenti.Store(true)
+}
+
+// Berhenti melaporkan apakah MulaiBerhenti sudah dipanggil.
+func (a *App) Berhenti() bool {
+	return a.berhenti.Load()
+}
+
+// Tutup menutup koneksi database.
+func (a *App) Tutup() error {
+	sqlDB, err := a.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,17 @@
+// Package authz berisi aturan otorisasi yang bergantung pada kepemilikan
+// data, di luar pengecekan role pada middleware.
+package authz
+
+// Aktor adalah user yang sedang melakukan request, diambil dari claims token.
+type Aktor struct {
+	UserID uint
+	Role   string
+}
+
+func (a Aktor) Admin() bool {
+	return a.Role == "admin"
+}
+
+func (a Aktor) pemilik(userID uint) bool {
+	return a.UserID != 0 && a.UserID == userID
+}
@@ -0,0 +1,83 @@
+package authz
+
+// Izin adalah hak melakukan sekelompok aksi.
+type Izin string
+
+const (
+	IsiPresensi    Izin = "presensi.isi"
+	KelolaPresensi Izin = "presensi.kelola"
+	KelolaPlotting Izin = "plotting.kelola"
+	KelolaRole     Izin = "role.kelola"
+)
+
+// izinRole memetakan role ke izin yang dimilikinya. Admin memiliki semua izin.
+var izinRole = map[string][]Izin{
+	"admin":       {IsiPresensi, KelolaPresensi, KelolaPlotting, KelolaRole},
+	"koordinator": {KelolaPresensi, KelolaPlotting},
+	"asisten":     {IsiPresensi},
+}
+
+// roleTambahan adalah role yang dapat diberikan lewat tabel user_roles di
+// samping role utama user. Hanya koordinator yang cakupannya dapat dibatasi
+// per program studi; admin hanya diberikan sebagai role utama karena izinnya
+// (termasuk mengelola role) tidak mengenal program studi.
+var roleTambahan = map[string]bool{"koordinator": true}
+
+// RoleTambahanValid mengecek apakah role boleh diberikan sebagai role tambahan.
+func RoleTambahanValid(role string) bool {
+	return roleTambahan[role]
+}
+
+// Pemberian adalah satu role yang dimiliki user. ProgramStudiID nil berarti
+// role berlaku untuk semua program studi.
+type Pemberian struct {
+	Role           string
+	ProgramStudiID *uint
+}
+
+func (p Pemberian) punya(izin Izin) bool {
+	for _, i := range izinRole[p.Role] {
+		if i == izin {
+			return true
+		}
+	}
+	return false
+}
+
+// Hak adalah semua role yang dimiliki user.
+type Hak []Pemberian
+
+// Punya mengecek apakah user memiliki izin pada setidaknya satu program studi.
+func (h Hak) Punya(izin Izin) bool {
+	for _, p := range h {
+		if p.punya(izin) {
+			return true
+		}
+	}
+	return false
+}
+
+// Boleh mengecek izin terhadap data milik program studi tertentu.
+func (h Hak) Boleh(izin Izin, programStudiID uint) bool {
+	for _, p := range h {
+		if p.punya(izin) && (p.ProgramStudiID == nil || *p.ProgramStudiID == programStudiID) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cakupan mengembalikan program studi tempat izin berlaku; semua bernilai
+// true jika izin tidak dibatasi program studi.
+func (h Hak) Cakupan(izin Izin) (programStudi []uint, semua bool) {
+	for _, p := range h {
+		if !p.punya(izin) {
+			continue
+		}
+		if p.ProgramStudiID == nil {
+			return nil, true
+		}
+		programStudi = append(programStudi, *p.ProgramStudiID)
+	}
+	return programStudi, false
+}
@@ -0,0 +1,53 @@
+package authz
+
+import "testing"
+
+func TestHakBoleh(t *testing.T) {
+	prodiA, prodiB := uint(1), uint(2)
+
+	tests := []struct {
+		nama  string
+		hak   Hak
+		izin  Izin
+		prodi uint
+		want  bool
+	}{
+		{"admin semua prodi", Hak{{Role: "admin"}}, KelolaPresensi, prodiB, true},
+		{"asisten isi presensi", Hak{{Role: "asisten"}}, IsiPresensi, prodiA, true},
+		{"asisten tidak kelola presensi", Hak{{Role: "asisten"}}, KelolaPresensi, prodiA, false},
+		{"koordinator prodi sendiri", Hak{{Role: "koordinator", ProgramStudiID: &prodiA}}, KelolaPlotting, prodiA, true},
+		{"koordinator prodi lain", Hak{{Role: "koordinator", ProgramStudiID: &prodiA}}, KelolaPlotting, prodiB, false},
+		{"koordinator tidak kelola role", Hak{{Role: "koordinator"}}, KelolaRole, prodiA, false},
+		{"asisten sekaligus koordinator", Hak{{Role: "asisten"}, {Role: "koordinator", ProgramStudiID: &prodiB}}, KelolaPresensi, prodiB, true},
+		{"role tidak dikenal", Hak{{Role: "tamu"}}, IsiPresensi, prodiA, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			if got := tt.hak.Boleh(tt.izin, tt.prodi); got != tt.want {
+				t.Errorf("Boleh(%s, %d) = %v, want %v", tt.izin, tt.prodi, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHakCakupan(t *testing.T) {
+	prodiA, prodiB := uint(1), uint(2)
+
+	hak := Hak{
+		{Role: "asisten"},
+		{Role: "koordinator", ProgramStudiID: &prodiA},
+		{Role: "koordinator", ProgramStudiID: &prodiB},
+	}
+	prodi, semua := hak.Cakupan(KelolaPresensi)
+	if semua || len(prodi) != 2 {
+		t.Fatalf("Cakupan = %v, %v; want [1 2], false", prodi, semua)
+	}
+
+	if _, semua := (Hak{{Role: "admin"}}).Cakupan(KelolaPresensi); !semua {
+		t.Fatal("admin harus mencakup semua program studi")
+	}
+	if prodi, semua := (Hak{{Role: "asisten"}}).Cakupan(KelolaPresensi); semua || len(prodi) != 0 {
+		t.Fatalf("asisten tidak boleh punya cakupan, got %v, %v", prodi, semua)
+	}
+}
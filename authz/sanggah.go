@@ -0,0 +1,40 @@
+package authz
+
+type AksiSanggah string
+
+const (
+	LihatSanggah      AksiSanggah = "lihat"
+	BuatSanggah       AksiSanggah = "buat"
+	BalasSanggah      AksiSanggah = "balas"
+	UbahStatusSanggah AksiSanggah = "ubah_status"
+)
+
+// BolehSanggah memutuskan apakah aktor boleh melakukan aksi pada sanggahan
+// atas rekapitulasi milik asisten pemilikID.
+//
+//	aksi          admin  asisten pemilik  asisten lain
+//	lihat         ya     ya               tidak
+//	buat          tidak  ya               tidak
+//	balas         ya     ya               tidak
+//	ubah_status   ya     tidak            tidak
+func BolehSanggah(a Aktor, aksi AksiSanggah, pemilikID uint) bool {
+	switch aksi {
+	case LihatSanggah, BalasSanggah:
+		return a.Admin() || a.pemilik(pemilikID)
+	case BuatSanggah:
+		return !a.Admin() && a.pemilik(pemilikID)
+	case UbahStatusSanggah:
+		return a.Admin()
+	default:
+		return false
+	}
+}
+
+// FilterSanggah menentukan sanggahan mana yang boleh tampil pada daftar:
+// semua untuk admin, atau hanya milik asisten tersebut.
+func FilterSanggah(a Aktor) (asistenID uint, semua bool) {
+	if a.Admin() {
+		return 0, true
+	}
+	return a.UserID, false
+}
@@ -0,0 +1,56 @@
+package authz
+
+import "testing"
+
+func TestBolehSanggah(t *testing.T) {
+	const pemilikID = 7
+
+	admin := Aktor{UserID: 1, Role: "admin"}
+	pemilik := Aktor{UserID: pemilikID, Role: "asisten"}
+	asistenLain := Aktor{UserID: 8, Role: "asisten"}
+	tanpaUser := Aktor{Role: "asisten"}
+
+	tests := []struct {
+		nama  string
+		aktor Aktor
+		aksi  AksiSanggah
+		mau   bool
+	}{
+		{"admin lihat", admin, LihatSanggah, true},
+		{"admin buat", admin, BuatSanggah, false},
+		{"admin balas", admin, BalasSanggah, true},
+		{"admin ubah status", admin, UbahStatusSanggah, true},
+
+		{"pemilik lihat", pemilik, LihatSanggah, true},
+		{"pemilik buat", pemilik, BuatSanggah, true},
+		{"pemilik balas", pemilik, BalasSanggah, true},
+		{"pemilik ubah status", pemilik, UbahStatusSanggah, false},
+
+		{"asisten lain lihat", asistenLain, LihatSanggah, false},
+		{"asisten lain buat", asistenLain, BuatSanggah, false},
+		{"asisten lain balas", asistenLain, BalasSanggah, false},
+		{"asisten lain ubah status", asistenLain, UbahStatusSanggah, false},
+
+		{"tanpa user id lihat", tanpaUser, LihatSanggah, false},
+		{"aksi tidak dikenal", admin, AksiSanggah("hapus"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			if got := BolehSanggah(tt.aktor, tt.aksi, pemilikID); got != tt.mau {
+				t.Errorf("BolehSanggah(%+v, %q) = %v, mau %v", tt.aktor, tt.aksi, got, tt.mau)
+			}
+		})
+	}
+}
+
+func TestFilterSanggah(t *testing.T) {
+	if _, semua := FilterSanggah(Aktor{UserID: 1, Role: "admin"}); !semua {
+		t.Error("admin harus melihat semua sanggahan")
+	}
+
+	asistenID, semua := FilterSanggah(Aktor{UserID: 7, Role: "asisten"})
+	if semua || asistenID != 7 {
+		t.Errorf("asisten hanya boleh melihat miliknya, dapat asistenID=%d semua=%v", asistenID, semua)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import "time"
+
+// MasaBerlakuToken mengatur umur access token, refresh token, dan token
+// reset password.
+type MasaBerlakuToken struct {
+	Akses         time.Duration
+	Refresh       time.Duration
+	ResetPassword time.Duration
+}
+
+// masaBerlakuToken membaca umur token.
+func (p *pembaca) masaBerlakuToken() MasaBerlakuToken {
+	return MasaBerlakuToken{
+		Akses:         p.menit("JWT_AKSES_MENIT", 15),
+		Refresh:       p.menit("JWT_REFRESH_MENIT", 7*24*60),
+		ResetPassword: p.menit("PASSWORD_RESET_MENIT", 60),
+	}
+}
+
+// BatasLogin mengatur perlindungan login dari brute force.
+type BatasLogin struct {
+	MaksGagal   int           // kegagalan per identifier sebelum dikunci
+	MaksGagalIP int           // kegagalan per IP sebelum dikunci
+	JedaAwal    time.Duration // jeda setelah gagal pertama, berlipat dua tiap gagal
+	KunciSelama time.Duration // lama penguncian sekaligus jendela reset hitungan
+}
+
+// batasLogin membaca batas percobaan login.
+func (p *pembaca) batasLogin() BatasLogin {
+	return BatasLogin{
+		MaksGagal:   p.bulat("LOGIN_MAKS_GAGAL", 5),
+		MaksGagalIP: p.bulat("LOGIN_MAKS_GAGAL_IP", 20),
+		JedaAwal:    p.detik("LOGIN_JEDA_DETIK", 1),
+		KunciSelama: p.menit("LOGIN_KUNCI_MENIT", 15),
+	}
+}
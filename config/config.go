@@ -1,42 +1,193 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"forum_asisten/models"
-	"log"
+	"forum_asisten/honor"
+	"forum_asisten/migrasi"
+	"forum_asisten/storage"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
-	"gorm.io/driver/mysql"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
+// minPanjangSecret adalah panjang minimum JWT_SECRET: 32 byte sesuai ukuran
+// kunci HS256.
+const minPanjangSecret = 32
 
-func InitDB() {
-	// Load DB config from environment variables
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASS")
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	dbname := os.Getenv("DB_NAME")
+// originBawaan selalu diizinkan agar frontend pengembangan dapat terhubung.
+var originBawaan = []string{"http://localhost:5173", "http://localhost:5174"}
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", user, pass, host, port, dbname)
+// Config adalah pengaturan aplikasi yang dibaca sekali saat start, lalu
+// diteruskan ke handler lewat app.App.
+type Config struct {
+	Port           string
+	AllowedOrigins []string
+	FrontendURL    string // alamat aplikasi web untuk tautan email dan redirect SSO
+	JWTSecret      string
+	BcryptCost     int
+	HTTP           HTTP
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	DB      Database
+	Storage Storage
+	Mail    Mail
+	SSO     SSO
+
+	Token       MasaBerlakuToken
+	BatasLogin  BatasLogin
+	Presensi    JendelaPresensi
+	Honor       honor.Konfigurasi
+	BatasUpload storage.Batas
+}
+
+// Load membaca konfigurasi dari environment proses, berkas .env, lalu berkas
+// YAML (CONFIG_FILE, bawaan config.yaml jika ada); sumber pertama yang
+// mengisi sebuah kunci yang dipakai. Load gagal jika ada nilai yang tidak
+// dapat diurai; kelengkapan nilai diperiksa terpisah oleh Validasi.
+func Load() (Config, error) {
+	dotenv, err := bacaDotenv(".env")
+	if err != nil {
+		return Config{}, err
+	}
+	lapisan := []sumber{os.LookupEnv, dariMap(dotenv)}
+
+	berkas := (&pembaca{sumber: lapisan}).teks("CONFIG_FILE", "")
+	wajib := berkas != ""
+	if !wajib {
+		berkas = "config.yaml"
+	}
+	nilaiYAML, err := bacaYAML(berkas, wajib)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return muat(append(lapisan, dariMap(nilaiYAML))...)
+}
+
+// Bawaan mengembalikan konfigurasi dengan semua nilai bawaan tanpa membaca
+// environment maupun berkas apa pun.
+func Bawaan() Config {
+	cfg, _ := muat()
+	return cfg
+}
+
+func muat(s ...sumber) (Config, error) {
+	p := &pembaca{sumber: s}
+	cfg := Config{
+		Port:           p.teks("PORT", "8080"),
+		AllowedOrigins: append(append([]string{}, originBawaan...), p.daftar("ALLOWED_ORIGINS")...),
+		FrontendURL:    strings.TrimRight(p.teks("FRONTEND_URL", "http://localhost:5173"), "/"),
+		JWTSecret:      p.teks("JWT_SECRET", ""),
+		BcryptCost:     p.bulat("BCRYPT_COST", 14),
+		HTTP:           p.http(),
+
+		DB:      p.database(),
+		Storage: p.storage(),
+		Mail:    p.mail(),
+		SSO:     p.sso(),
+
+		Token:       p.masaBerlakuToken(),
+		BatasLogin:  p.batasLogin(),
+		Presensi:    p.jendelaPresensi(),
+		Honor:       p.aturanHonor(),
+		BatasUpload: p.batasUpload(),
+	}
+	return cfg, errors.Join(p.galat...)
+}
+
+// Validasi memeriksa nilai wajib dan nilai yang tidak masuk akal. Semua
+// masalah dilaporkan sekaligus.
+func (c Config) Validasi() error {
+	var galat []error
+	tambah := func(err error) {
+		if err != nil {
+			galat = append(galat, err)
+		}
+	}
+
+	switch {
+	case c.JWTSecret == "":
+		tambah(errors.New("JWT_SECRET wajib diisi"))
+	case len(c.JWTSecret) < minPanjangSecret:
+		tambah(fmt.Errorf("JWT_SECRET minimal %d karakter", minPanjangSecret))
+	}
+	if c.BcryptCost < 10 || c.BcryptCost > bcrypt.MaxCost {
+		tambah(fmt.Errorf("BCRYPT_COST harus antara 10 dan %d", bcrypt.MaxCost))
+	}
+	if c.Token.Akses == 0 || c.Token.Refresh == 0 || c.Token.ResetPassword == 0 {
+		tambah(errors.New("JWT_AKSES_MENIT, JWT_REFRESH_MENIT dan PASSWORD_RESET_MENIT harus lebih dari 0"))
+	}
+
+	tambah(validasiPort("PORT", c.Port))
+	tambah(c.HTTP.Validasi())
+	for _, o := range c.AllowedOrigins {
+		tambah(validasiOrigin(o))
+	}
+	tambah(validasiURL("FRONTEND_URL", c.FrontendURL))
+
+	tambah(c.DB.Validasi())
+	tambah(c.Storage.Validasi())
+	tambah(c.Mail.Validasi())
+	tambah(c.SSO.Validasi())
+	return errors.Join(galat...)
+}
+
+func validasiPort(key, port string) error {
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%s bukan nomor port yang sah: %q", key, port)
+	}
+	return nil
+}
+
+func validasiURL(key, nilai string) error {
+	u, err := url.Parse(nilai)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%s bukan URL http(s) yang sah: %q", key, nilai)
+	}
+	return nil
+}
+
+// validasiOrigin menerima origin berbentuk skema://host[:port] tanpa path,
+// termasuk garis miring penutup, karena CORS mencocokkan origin apa adanya.
+// Wildcard ditolak karena CORS mengizinkan kredensial.
+func validasiOrigin(origin string) error {
+	if origin == "*" {
+		return errors.New("ALLOWED_ORIGINS tidak boleh berisi *; sebutkan origin satu per satu")
+	}
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+		return fmt.Errorf("ALLOWED_ORIGINS berisi origin yang tidak sah: %q (contoh: https://forum-asisten.vercel.app)", origin)
+	}
+	return nil
+}
+
+// ConnectDB membuka koneksi database tanpa memeriksa skema. Dipakai langsung
+// oleh subcommand migrate.
+func ConnectDB(d Database) (*gorm.DB, error) {
+	db, err := BukaDB(d.Driver, d.dsn())
 	if err != nil {
-		log.Fatal("Gagal koneksi DB: ", err)
+		return nil, fmt.Errorf("gagal koneksi DB: %w", err)
 	}
 
-	DB = db
-	fmt.Println("Database terkoneksi.")
+	fmt.Printf("Database terkoneksi (%s).\n", d.Driver)
+	return db, nil
+}
 
-	// Auto migrate semua model
-	db.AutoMigrate(
-		&models.ProgramStudi{},
-		&models.MataKuliah{},
-		&models.Dosen{},
-		&models.Jadwal{},
-		&models.User{},
-		&models.AsistenKelas{},
-	)
+// InitDB membuka koneksi database dan memastikan skemanya mutakhir. Aplikasi
+// menolak berjalan jika masih ada migrasi tertunda; jalankan
+// `go run main.go migrate up` terlebih dahulu.
+func InitDB(d Database) (*gorm.DB, error) {
+	db, err := ConnectDB(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := migrasi.Periksa(db); err != nil {
+		return nil, fmt.Errorf("skema database tidak sesuai: %w", err)
+	}
+	return db, nil
 }
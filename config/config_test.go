@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secretUji = "0123456789abcdef0123456789abcdef"
+
+// valid mengembalikan konfigurasi minimum yang lolos Validasi.
+func valid(t *testing.T) Config {
+	t.Helper()
+	cfg, err := muat(dariMap(map[string]string{
+		"JWT_SECRET": secretUji,
+		"DB_USER":    "forum",
+		"DB_PASS":    "sandi-db",
+		"DB_NAME":    "forum_asisten",
+	}))
+	if err != nil {
+		t.Fatalf("muat: %v", err)
+	}
+	if err := cfg.Validasi(); err != nil {
+		t.Fatalf("konfigurasi dasar tidak valid: %v", err)
+	}
+	return cfg
+}
+
+func TestMuatUrutanSumber(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{"PORT": "9000", "DB_DRIVER": ""}
+	dotenv := map[string]string{"PORT": "9001", "DB_DRIVER": "postgres", "LOGIN_MAKS_GAGAL": "7"}
+	berkas := map[string]string{"PORT": "9002", "DB_DRIVER": "sqlite", "LOGIN_MAKS_GAGAL": "8", "JWT_AKSES_MENIT": "30"}
+
+	cfg, err := muat(dariMap(env), dariMap(dotenv), dariMap(berkas))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "9000" {
+		t.Errorf("PORT = %q, want 9000 dari environment", cfg.Port)
+	}
+	// Nilai kosong di environment tidak menutupi sumber berikutnya
+	if cfg.DB.Driver != "postgres" || cfg.DB.Port != "5432" {
+		t.Errorf("DB = %s:%s, want postgres:5432 dari .env", cfg.DB.Driver, cfg.DB.Port)
+	}
+	if cfg.BatasLogin.MaksGagal != 7 {
+		t.Errorf("LOGIN_MAKS_GAGAL = %d, want 7 dari .env", cfg.BatasLogin.MaksGagal)
+	}
+	if cfg.Token.Akses != 30*time.Minute {
+		t.Errorf("JWT_AKSES_MENIT = %s, want 30m dari YAML", cfg.Token.Akses)
+	}
+}
+
+func TestMuatNilaiRusak(t *testing.T) {
+	t.Parallel()
+	_, err := muat(dariMap(map[string]string{
+		"BCRYPT_COST":           "empat belas",
+		"LOGIN_JEDA_DETIK":      "-1",
+		"APP_TIMEZONE":          "Mars/Olympus",
+		"S3_USE_SSL":            "ya",
+		"UPLOAD_MAX_MEGAPIXELS": "0",
+		"UPLOAD_MAX_MB":         "0",
+	}))
+	if err == nil {
+		t.Fatal("nilai rusak diterima")
+	}
+	for _, key := range []string{"BCRYPT_COST", "LOGIN_JEDA_DETIK", "APP_TIMEZONE", "S3_USE_SSL", "UPLOAD_MAX_MB", "UPLOAD_MAX_MEGAPIXELS"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("galat tidak menyebut %s:\n%v", key, err)
+		}
+	}
+}
+
+func TestValidasi(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		nama  string
+		ubah  func(*Config)
+		galat string
+	}{
+		{"secret kosong", func(c *Config) { c.JWTSecret = "" }, "JWT_SECRET wajib diisi"},
+		{"secret pendek", func(c *Config) { c.JWTSecret = "rahasia" }, "JWT_SECRET minimal"},
+		{"bcrypt terlalu murah", func(c *Config) { c.BcryptCost = 4 }, "BCRYPT_COST"},
+		{"port bukan angka", func(c *Config) { c.Port = "http" }, "PORT"},
+		{"origin wildcard", func(c *Config) { c.AllowedOrigins = append(c.AllowedOrigins, "*") }, "tidak boleh berisi *"},
+		{"origin dengan path", func(c *Config) {
+			c.AllowedOrigins = append(c.AllowedOrigins, "https://forum-asisten.vercel.app/")
+		}, "ALLOWED_ORIGINS"},
+		{"mysql tanpa host", func(c *Config) { c.DB.Host = "" }, "DB_HOST"},
+		{"driver database tidak dikenal", func(c *Config) { c.DB.Driver = "oracle" }, "DB_DRIVER"},
+		{"s3 tanpa kunci", func(c *Config) { c.Storage.Driver = "s3" }, "S3_ACCESS_KEY"},
+		{"smtp tanpa host", func(c *Config) { c.Mail.Driver = "smtp" }, "SMTP_HOST"},
+		{"jeda drain negatif", func(c *Config) { c.HTTP.DrainDelay = -time.Second }, "HTTP_DRAIN_DELAY_DETIK"},
+		{"sso tanpa client", func(c *Config) { c.SSO.Klien.Issuer = "https://sso.contoh.ac.id" }, "OIDC_CLIENT_ID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			cfg := valid(t)
+			tt.ubah(&cfg)
+			err := cfg.Validasi()
+			if err == nil || !strings.Contains(err.Error(), tt.galat) {
+				t.Fatalf("Validasi() = %v, want galat memuat %q", err, tt.galat)
+			}
+		})
+	}
+
+	t.Run("dsn menggantikan pengaturan koneksi", func(t *testing.T) {
+		cfg := valid(t)
+		cfg.DB = Database{Driver: "postgres", DSN: "postgres://forum@db/forum"}
+		if err := cfg.Validasi(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestBacaYAML(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	isi := "DB_DRIVER: sqlite\nBCRYPT_COST: 12\nALLOWED_ORIGINS:\n  - https://a.contoh.ac.id\n  - https://b.contoh.ac.id\n"
+	if err := os.WriteFile(path, []byte(isi), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	nilai, err := bacaYAML(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := muat(dariMap(nilai))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Driver != "sqlite" || cfg.BcryptCost != 12 {
+		t.Errorf("DB_DRIVER = %q, BCRYPT_COST = %d; want sqlite, 12", cfg.DB.Driver, cfg.BcryptCost)
+	}
+	if got := cfg.AllowedOrigins[len(cfg.AllowedOrigins)-2:]; got[0] != "https://a.contoh.ac.id" || got[1] != "https://b.contoh.ac.id" {
+		t.Errorf("ALLOWED_ORIGINS = %v", cfg.AllowedOrigins)
+	}
+
+	if _, err := bacaYAML(filepath.Join(dir, "tidak-ada.yaml"), false); err != nil {
+		t.Errorf("berkas opsional yang tidak ada: %v", err)
+	}
+	if _, err := bacaYAML(filepath.Join(dir, "tidak-ada.yaml"), true); err == nil {
+		t.Error("CONFIG_FILE yang tidak ada diterima")
+	}
+}
+
+func TestRingkasanMenyamarkanRahasia(t *testing.T) {
+	t.Parallel()
+	cfg := valid(t)
+	cfg.Mail.Driver = "smtp"
+	cfg.Mail.SMTP.Password = "sandi-smtp"
+	cfg.SSO.Klien.Issuer = "https://sso.contoh.ac.id"
+	cfg.SSO.Klien.ClientSecret = "sandi-oidc"
+
+	ringkasan := cfg.Ringkasan()
+	for _, rahasia := range []string{secretUji, "sandi-db", "sandi-smtp", "sandi-oidc"} {
+		if strings.Contains(ringkasan, rahasia) {
+			t.Errorf("ringkasan memuat rahasia %q:\n%s", rahasia, ringkasan)
+		}
+	}
+	if !strings.Contains(ringkasan, "forum@localhost:3306/forum_asisten") {
+		t.Errorf("ringkasan tidak memuat tujuan database:\n%s", ringkasan)
+	}
+}
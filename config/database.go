@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// BukaDB membuka koneksi database dengan driver "mysql", "postgres" atau
+// "sqlite". Untuk sqlite, dsn adalah path berkas database (atau ":memory:").
+func BukaDB(driver, dsn string) (*gorm.DB, error) {
+	var dialektor gorm.Dialector
+	switch driver {
+	case "mysql":
+		dialektor = mysql.Open(dsn)
+	case "postgres":
+		dialektor = postgres.Open(dsn)
+	case "sqlite":
+		dialektor = sqlite.Open(dsnSQLite(dsn))
+	default:
+		return nil, fmt.Errorf("DB_DRIVER tidak dikenal: %q (pilih mysql, postgres atau sqlite)", driver)
+	}
+	return gorm.Open(dialektor, &gorm.Config{})
+}
+
+// dsnSQLite menambahkan pragma yang dibutuhkan aplikasi: foreign key aktif,
+// menunggu kunci tulis alih-alih langsung gagal, dan WAL agar pembaca tidak
+// terblokir selama transaksi tulis berjalan.
+func dsnSQLite(dsn string) string {
+	pragma := []string{"foreign_keys(1)", "busy_timeout(5000)", "journal_mode(WAL)"}
+	var tambahan []string
+	for _, p := range pragma {
+		nama := p[:strings.IndexByte(p, '(')]
+		if !strings.Contains(dsn, nama) {
+			tambahan = append(tambahan, "_pragma="+p)
+		}
+	}
+	if len(tambahan) == 0 {
+		return dsn
+	}
+	pemisah := "?"
+	if strings.Contains(dsn, "?") {
+		pemisah = "&"
+	}
+	return dsn + pemisah + strings.Join(tambahan, "&")
+}
+
+// Database mengatur koneksi database.
+type Database struct {
+	Driver   string // "mysql", "postgres" atau "sqlite"
+	DSN      string // jika diisi, dipakai apa adanya
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Nama     string
+	SSLMode  string // khusus postgres
+	Path     string // khusus sqlite
+}
+
+func (p *pembaca) database() Database {
+	driver := p.teks("DB_DRIVER", "mysql")
+	port := "3306"
+	if driver == "postgres" {
+		port = "5432"
+	}
+	return Database{
+		Driver:   driver,
+		DSN:      p.teks("DB_DSN", ""),
+		Host:     p.teks("DB_HOST", "localhost"),
+		Port:     p.teks("DB_PORT", port),
+		User:     p.teks("DB_USER", ""),
+		Password: p.teks("DB_PASS", ""),
+		Nama:     p.teks("DB_NAME", ""),
+		SSLMode:  p.teks("DB_SSLMODE", "disable"),
+		Path:     p.teks("DB_PATH", "forum_asisten.db"),
+	}
+}
+
+// Validasi memeriksa pengaturan yang wajib untuk driver terpilih. DB_DSN
+// menggantikan semua pengaturan koneksi lain.
+func (d Database) Validasi() error {
+	switch d.Driver {
+	case "mysql", "postgres":
+	case "sqlite":
+		if d.DSN == "" && d.Path == "" {
+			return errors.New("DB_PATH wajib diisi untuk DB_DRIVER=sqlite")
+		}
+		return nil
+	default:
+		return fmt.Errorf("DB_DRIVER tidak dikenal: %q (pilih mysql, postgres atau sqlite)", d.Driver)
+	}
+	if d.DSN != "" {
+		return nil
+	}
+
+	var galat []error
+	for _, w := range [][2]string{{"DB_HOST", d.Host}, {"DB_USER", d.User}, {"DB_NAME", d.Nama}} {
+		if w[1] == "" {
+			galat = append(galat, fmt.Errorf("%s wajib diisi untuk DB_DRIVER=%s", w[0], d.Driver))
+		}
+	}
+	if err := validasiPort("DB_PORT", d.Port); err != nil {
+		galat = append(galat, err)
+	}
+	return errors.Join(galat...)
+}
+
+// dsn menyusun DSN dari pengaturan koneksi, atau path berkas untuk sqlite.
+func (d Database) dsn() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	switch d.Driver {
+	case "postgres":
+		return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s TimeZone=UTC",
+			d.Host, d.Port, d.User, d.Password, d.Nama, d.SSLMode)
+	case "sqlite":
+		return d.Path
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", d.User, d.Password, d.Host, d.Port, d.Nama)
+	}
+}
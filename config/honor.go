@@ -0,0 +1,22 @@
+package config
+
+import "forum_asisten/honor"
+
+// aturanHonor membaca parameter perhitungan honor. Nilai bawaan menghasilkan
+// perhitungan lama: hadir dan pengganti dibayar penuh, izin dan alpha tidak
+// dibayar, tanpa batas.
+func (p *pembaca) aturanHonor() honor.Konfigurasi {
+	return honor.Konfigurasi{
+		FaktorJenis: map[string]float64{
+			"utama":     p.angka("HONOR_FAKTOR_UTAMA", 1),
+			"pengganti": p.angka("HONOR_FAKTOR_PENGGANTI", 1),
+			"izin":      p.angka("HONOR_FAKTOR_IZIN", 0),
+			"alpha":     0,
+		},
+		DurasiStandar: p.menit("HONOR_DURASI_STANDAR_MENIT", 0),
+		MaksPertemuan: p.bulat("HONOR_MAKS_PERTEMUAN", 0),
+		FaktorLebih:   p.angka("HONOR_FAKTOR_PERTEMUAN_LEBIH", 1),
+		MaksPerSesi:   p.bulat("HONOR_MAKS_PER_SESI", 0),
+		MaksTotal:     p.bulat("HONOR_MAKS_TOTAL", 0),
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// HTTP mengatur batas waktu server HTTP.
+type HTTP struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration // mencakup body, termasuk unggahan berkas
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration // koneksi keep-alive yang menganggur
+	ShutdownTimeout   time.Duration // batas menunggu request yang berjalan saat berhenti
+	DrainDelay        time.Duration // jeda antara readiness gagal dan berhenti menerima koneksi
+}
+
+func (p *pembaca) http() HTTP {
+	return HTTP{
+		ReadHeaderTimeout: p.detik("HTTP_READ_HEADER_TIMEOUT_DETIK", 5),
+		ReadTimeout:       p.detik("HTTP_READ_TIMEOUT_DETIK", 30),
+		WriteTimeout:      p.detik("HTTP_WRITE_TIMEOUT_DETIK", 60),
+		IdleTimeout:       p.detik("HTTP_IDLE_TIMEOUT_DETIK", 120),
+		ShutdownTimeout:   p.detik("HTTP_SHUTDOWN_TIMEOUT_DETIK", 20),
+		DrainDelay:        p.detik("HTTP_DRAIN_DELAY_DETIK", 5),
+	}
+}
+
+// Validasi menolak batas waktu nol, yang berarti tanpa batas. Jeda drain
+// boleh nol bila tidak ada load balancer yang memeriksa readiness.
+func (h HTTP) Validasi() error {
+	if h.ReadHeaderTimeout <= 0 || h.ReadTimeout <= 0 || h.WriteTimeout <= 0 || h.IdleTimeout <= 0 || h.ShutdownTimeout <= 0 {
+		return errors.New("HTTP_*_TIMEOUT_DETIK harus lebih dari 0")
+	}
+	if h.DrainDelay < 0 {
+		return errors.New("HTTP_DRAIN_DELAY_DETIK tidak boleh negatif")
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"forum_asisten/mailer"
+	"net/mail"
+)
+
+// Mail mengatur pengiriman email.
+type Mail struct {
+	Driver    string // "smtp", "file" atau "memori"
+	Dari      string
+	OutboxDir string
+	SMTP      mailer.SMTPConfig
+}
+
+func (p *pembaca) mail() Mail {
+	dari := p.teks("MAIL_FROM", "no-reply@forum-asisten.local")
+	return Mail{
+		Driver:    p.teks("MAIL_DRIVER", "file"),
+		Dari:      dari,
+		OutboxDir: p.teks("MAIL_OUTBOX_DIR", "outbox"),
+		SMTP: mailer.SMTPConfig{
+			Host:     p.teks("SMTP_HOST", ""),
+			Port:     p.teks("SMTP_PORT", "587"),
+			Username: p.teks("SMTP_USER", ""),
+			Password: p.teks("SMTP_PASS", ""),
+			Dari:     dari,
+		},
+	}
+}
+
+// Validasi memeriksa alamat pengirim dan pengaturan driver terpilih.
+func (m Mail) Validasi() error {
+	var galat []error
+	if _, err := mail.ParseAddress(m.Dari); err != nil {
+		galat = append(galat, fmt.Errorf("MAIL_FROM bukan alamat email yang sah: %q", m.Dari))
+	}
+	switch m.Driver {
+	case "smtp":
+		if m.SMTP.Host == "" {
+			galat = append(galat, errors.New("SMTP_HOST wajib diisi untuk MAIL_DRIVER=smtp"))
+		}
+		if err := validasiPort("SMTP_PORT", m.SMTP.Port); err != nil {
+			galat = append(galat, err)
+		}
+	case "file":
+		if m.OutboxDir == "" {
+			galat = append(galat, errors.New("MAIL_OUTBOX_DIR wajib diisi untuk MAIL_DRIVER=file"))
+		}
+	case "memori":
+	default:
+		galat = append(galat, fmt.Errorf("MAIL_DRIVER tidak dikenal: %q (pilih smtp, file atau memori)", m.Driver))
+	}
+	return errors.Join(galat...)
+}
+
+// InitMailer menyiapkan pengirim email sesuai driver.
+func InitMailer(m Mail) (mailer.Mailer, error) {
+	switch m.Driver {
+	case "smtp":
+		return mailer.NewSMTP(m.SMTP)
+	case "file":
+		return mailer.NewFile(m.OutboxDir, m.Dari)
+	case "memori":
+		return mailer.NewMemori(), nil
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER tidak dikenal: %s", m.Driver)
+	}
+}
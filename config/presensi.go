@@ -0,0 +1,34 @@
+package config
+
+import (
+	"time"
+	// Basis data zona waktu ikut dikompilasi agar APP_TIMEZONE dapat
+	// divalidasi di host tanpa tzdata
+	_ "time/tzdata"
+)
+
+// JendelaPresensi mengatur kapan presensi boleh diisi relatif terhadap jam
+// mulai dan jam selesai pertemuan.
+type JendelaPresensi struct {
+	BukaSebelumMulai time.Duration  // presensi hadir dibuka sekian menit sebelum jam mulai
+	Toleransi        time.Duration  // setelah jam mulai, masih dianggap tepat waktu
+	BatasTerlambat   time.Duration  // setelah jam selesai, masih diterima namun ditandai terlambat
+	Lokasi           *time.Location // zona waktu jadwal kuliah
+}
+
+// jendelaPresensi membaca pengaturan jendela presensi.
+func (p *pembaca) jendelaPresensi() JendelaPresensi {
+	zona := p.teks("APP_TIMEZONE", "Asia/Jakarta")
+	lokasi, err := time.LoadLocation(zona)
+	if err != nil {
+		p.gagal("APP_TIMEZONE tidak dikenal: %q", zona)
+		lokasi = time.Local
+	}
+
+	return JendelaPresensi{
+		BukaSebelumMulai: p.menit("PRESENSI_BUKA_SEBELUM_MENIT", 15),
+		Toleransi:        p.menit("PRESENSI_TOLERANSI_MENIT", 30),
+		BatasTerlambat:   p.menit("PRESENSI_BATAS_TERLAMBAT_MENIT", 24*60),
+		Lokasi:           lokasi,
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Ringkasan menulis konfigurasi efektif untuk log startup. Password, secret
+// dan DSN lengkap disamarkan.
+func (c Config) Ringkasan() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	baris := func(key, format string, a ...interface{}) {
+		fmt.Fprintf(w, "  %s\t%s\n", key, fmt.Sprintf(format, a...))
+	}
+
+	fmt.Fprintln(w, "Konfigurasi:")
+	baris("PORT", "%s", c.Port)
+	baris("ALLOWED_ORIGINS", "%s", strings.Join(c.AllowedOrigins, ", "))
+	baris("FRONTEND_URL", "%s", c.FrontendURL)
+	baris("JWT_SECRET", "%s", samarkan(c.JWTSecret))
+	baris("BCRYPT_COST", "%d", c.BcryptCost)
+	baris("HTTP", "read header %s, read %s, write %s, idle %s, drain %s, shutdown %s",
+		c.HTTP.ReadHeaderTimeout, c.HTTP.ReadTimeout, c.HTTP.WriteTimeout, c.HTTP.IdleTimeout, c.HTTP.DrainDelay, c.HTTP.ShutdownTimeout)
+	baris("DB", "%s", c.DB.ringkasan())
+	baris("STORAGE", "%s", c.Storage.ringkasan())
+	baris("MAIL", "%s", c.Mail.ringkasan())
+	baris("SSO", "%s", c.SSO.ringkasan())
+	baris("TOKEN", "akses %s, refresh %s, reset password %s",
+		c.Token.Akses, c.Token.Refresh, c.Token.ResetPassword)
+	baris("LOGIN", "maks gagal %d (per IP %d), jeda awal %s, kunci %s",
+		c.BatasLogin.MaksGagal, c.BatasLogin.MaksGagalIP, c.BatasLogin.JedaAwal, c.BatasLogin.KunciSelama)
+	baris("PRESENSI", "buka %s sebelum mulai, toleransi %s, batas terlambat %s, zona %s",
+		c.Presensi.BukaSebelumMulai, c.Presensi.Toleransi, c.Presensi.BatasTerlambat, c.Presensi.Lokasi)
+	baris("HONOR", "faktor utama %g, pengganti %g, izin %g; maks pertemuan %d, per sesi %d, total %d",
+		c.Honor.FaktorJenis["utama"], c.Honor.FaktorJenis["pengganti"], c.Honor.FaktorJenis["izin"],
+		c.Honor.MaksPertemuan, c.Honor.MaksPerSesi, c.Honor.MaksTotal)
+	baris("UPLOAD", "maks %d MB, %d megapiksel", c.BatasUpload.MaksUkuran>>20, c.BatasUpload.MaksPiksel/1_000_000)
+	w.Flush()
+	return b.String()
+}
+
+func samarkan(rahasia string) string {
+	if rahasia == "" {
+		return "(kosong)"
+	}
+	return "****"
+}
+
+func (d Database) ringkasan() string {
+	switch {
+	case d.DSN != "":
+		return d.Driver + " (DB_DSN " + samarkan(d.DSN) + ")"
+	case d.Driver == "sqlite":
+		return "sqlite " + d.Path
+	default:
+		return fmt.Sprintf("%s %s@%s:%s/%s (password %s)", d.Driver, d.User, d.Host, d.Port, d.Nama, samarkan(d.Password))
+	}
+}
+
+func (s Storage) ringkasan() string {
+	if s.Driver == "s3" {
+		return fmt.Sprintf("s3 %s/%s (ssl %t, secret key %s)", s.S3.Endpoint, s.S3.Bucket, s.S3.UseSSL, samarkan(s.S3.SecretKey))
+	}
+	return s.Driver + " " + s.LocalDir
+}
+
+func (m Mail) ringkasan() string {
+	switch m.Driver {
+	case "smtp":
+		return fmt.Sprintf("smtp %s@%s:%s (password %s), dari %s", m.SMTP.Username, m.SMTP.Host, m.SMTP.Port, samarkan(m.SMTP.Password), m.Dari)
+	case "file":
+		return fmt.Sprintf("file %s, dari %s", m.OutboxDir, m.Dari)
+	default:
+		return m.Driver
+	}
+}
+
+func (s SSO) ringkasan() string {
+	if s.Klien.Issuer == "" {
+		return "tidak aktif"
+	}
+	return fmt.Sprintf("%s (client %s, secret %s)", s.Klien.Issuer, s.Klien.ClientID, samarkan(s.Klien.ClientSecret))
+}
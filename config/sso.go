@@ -0,0 +1,69 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"forum_asisten/sso"
+	"log"
+	"strings"
+	"time"
+)
+
+// SSO mengatur login OIDC. Login SSO tidak aktif jika Klien.Issuer kosong.
+type SSO struct {
+	Klien sso.Config
+	// MasaSesi adalah batas waktu user menyelesaikan login di penyedia
+	// identitas.
+	MasaSesi time.Duration
+}
+
+func (p *pembaca) sso() SSO {
+	return SSO{
+		Klien: sso.Config{
+			Issuer:       p.teks("OIDC_ISSUER", ""),
+			ClientID:     p.teks("OIDC_CLIENT_ID", ""),
+			ClientSecret: p.teks("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  p.teks("OIDC_REDIRECT_URL", "http://localhost:8080/api/sso/callback"),
+			Scopes:       strings.Fields(p.teks("OIDC_SCOPES", "profile email")),
+			KlaimNIM:     p.teks("OIDC_KLAIM_NIM", "nim"),
+		},
+		MasaSesi: p.menit("OIDC_SESI_MENIT", 10),
+	}
+}
+
+// Validasi memeriksa pengaturan klien bila login SSO diaktifkan.
+func (s SSO) Validasi() error {
+	if s.Klien.Issuer == "" {
+		return nil
+	}
+	var galat []error
+	if err := validasiURL("OIDC_ISSUER", s.Klien.Issuer); err != nil {
+		galat = append(galat, err)
+	}
+	if s.Klien.ClientID == "" {
+		galat = append(galat, errors.New("OIDC_CLIENT_ID wajib diisi jika OIDC_ISSUER diisi"))
+	}
+	if err := validasiURL("OIDC_REDIRECT_URL", s.Klien.RedirectURL); err != nil {
+		galat = append(galat, err)
+	}
+	return errors.Join(galat...)
+}
+
+// InitSSO menyiapkan klien OIDC dan mengembalikan nil jika login SSO tidak
+// dikonfigurasi. Penyedia identitas yang tidak dapat dihubungi tidak
+// menghentikan server; login SSO saja yang tidak tersedia.
+func InitSSO(s SSO) *sso.Klien {
+	if s.Klien.Issuer == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	klien, err := sso.New(ctx, s.Klien)
+	if err != nil {
+		log.Println("Login SSO tidak aktif:", err)
+		return nil
+	}
+	return klien
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"forum_asisten/storage"
+)
+
+// Storage mengatur tempat penyimpanan berkas.
+type Storage struct {
+	Driver   string // "local" atau "s3"
+	LocalDir string
+	S3       storage.S3Config
+}
+
+func (p *pembaca) storage() Storage {
+	return Storage{
+		Driver:   p.teks("STORAGE_DRIVER", "local"),
+		LocalDir: p.teks("STORAGE_LOCAL_DIR", "uploads"),
+		S3: storage.S3Config{
+			Endpoint:  p.teks("S3_ENDPOINT", ""),
+			AccessKey: p.teks("S3_ACCESS_KEY", ""),
+			SecretKey: p.teks("S3_SECRET_KEY", ""),
+			Bucket:    p.teks("S3_BUCKET", "forum-asisten"),
+			Region:    p.teks("S3_REGION", ""),
+			UseSSL:    p.benar("S3_USE_SSL", false),
+		},
+	}
+}
+
+// Validasi memeriksa pengaturan yang wajib untuk driver terpilih.
+func (s Storage) Validasi() error {
+	switch s.Driver {
+	case "local":
+		if s.LocalDir == "" {
+			return errors.New("STORAGE_LOCAL_DIR wajib diisi")
+		}
+	case "s3":
+		var galat []error
+		for _, w := range [][2]string{
+			{"S3_ENDPOINT", s.S3.Endpoint},
+			{"S3_ACCESS_KEY", s.S3.AccessKey},
+			{"S3_SECRET_KEY", s.S3.SecretKey},
+			{"S3_BUCKET", s.S3.Bucket},
+		} {
+			if w[1] == "" {
+				galat = append(galat, fmt.Errorf("%s wajib diisi untuk STORAGE_DRIVER=s3", w[0]))
+			}
+		}
+		return errors.Join(galat...)
+	default:
+		return fmt.Errorf("STORAGE_DRIVER tidak dikenal: %q (pilih local atau s3)", s.Driver)
+	}
+	return nil
+}
+
+// InitStorage menyiapkan tempat penyimpanan berkas sesuai driver.
+func InitStorage(s Storage) (storage.Storage, error) {
+	switch s.Driver {
+	case "local":
+		return storage.NewLocal(s.LocalDir)
+	case "s3":
+		return storage.NewS3(context.Background(), s.S3)
+	default:
+		return nil, fmt.Errorf("STORAGE_DRIVER tidak dikenal: %s", s.Driver)
+	}
+}
+
+// batasUpload membaca batas ukuran berkas dan jumlah piksel gambar unggahan.
+func (p *pembaca) batasUpload() storage.Batas {
+	maksMB := p.bulat("UPLOAD_MAX_MB", 5)
+	if maksMB == 0 {
+		p.gagal("UPLOAD_MAX_MB harus lebih dari 0")
+	}
+	maksMP := p.bulat("UPLOAD_MAX_MEGAPIXELS", 40)
+	if maksMP == 0 {
+		p.gagal("UPLOAD_MAX_MEGAPIXELS harus lebih dari 0")
+	}
+	return storage.Batas{
+		MaksUkuran:       int64(maksMB) << 20,
+		MaksPiksel:       maksMP * 1_000_000,
+		MaksDimensi:      1920,
+		DimensiThumbnail: 320,
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// sumber mengambil nilai mentah satu kunci konfigurasi, mis. environment
+// proses, berkas .env atau berkas YAML.
+type sumber func(key string) (string, bool)
+
+func dariMap(m map[string]string) sumber {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+// bacaDotenv membaca berkas .env tanpa mengubah environment proses. Berkas
+// yang tidak ada dianggap kosong.
+func bacaDotenv(path string) (map[string]string, error) {
+	m, err := godotenv.Read(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("membaca %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// bacaYAML membaca berkas YAML berisi kunci yang sama dengan nama variabel
+// environment, mis. `DB_DRIVER: postgres`. Daftar digabung dengan koma
+// sehingga ALLOWED_ORIGINS dapat ditulis sebagai list. wajib menentukan
+// apakah berkas yang tidak ada merupakan galat.
+func bacaYAML(path string, wajib bool) (map[string]string, error) {
+	isi, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !wajib {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("membaca %s: %w", path, err)
+	}
+
+	var mentah map[string]interface{}
+	if err := yaml.Unmarshal(isi, &mentah); err != nil {
+		return nil, fmt.Errorf("mengurai %s: %w", path, err)
+	}
+
+	hasil := make(map[string]string, len(mentah))
+	for key, v := range mentah {
+		switch v := v.(type) {
+		case nil:
+		case []interface{}:
+			bagian := make([]string, len(v))
+			for i, x := range v {
+				bagian[i] = fmt.Sprint(x)
+			}
+			hasil[key] = strings.Join(bagian, ",")
+		case map[string]interface{}:
+			return nil, fmt.Errorf("%s: nilai %s harus berupa teks, angka atau list", path, key)
+		default:
+			hasil[key] = fmt.Sprint(v)
+		}
+	}
+	return hasil, nil
+}
+
+// pembaca mengurai nilai konfigurasi dari beberapa sumber; sumber pertama
+// yang mengisi sebuah kunci yang dipakai. Nilai yang tidak dapat diurai
+// dicatat di galat sehingga Load gagal alih-alih diam-diam memakai nilai
+// bawaan.
+type pembaca struct {
+	sumber []sumber
+	galat  []error
+}
+
+func (p *pembaca) gagal(format string, a ...interface{}) {
+	p.galat = append(p.galat, fmt.Errorf(format, a...))
+}
+
+func (p *pembaca) teks(key, bawaan string) string {
+	for _, s := range p.sumber {
+		if v, ok := s(key); ok && v != "" {
+			return v
+		}
+	}
+	return bawaan
+}
+
+func (p *pembaca) bulat(key string, bawaan int) int {
+	v := p.teks(key, "")
+	if v == "" {
+		return bawaan
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		p.gagal("%s harus berupa bilangan bulat tidak negatif, bukan %q", key, v)
+		return bawaan
+	}
+	return n
+}
+
+func (p *pembaca) angka(key string, bawaan float64) float64 {
+	v := p.teks(key, "")
+	if v == "" {
+		return bawaan
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil || f < 0 {
+		p.gagal("%s harus berupa angka tidak negatif, bukan %q", key, v)
+		return bawaan
+	}
+	return f
+}
+
+func (p *pembaca) menit(key string, bawaan int) time.Duration {
+	return time.Duration(p.bulat(key, bawaan)) * time.Minute
+}
+
+func (p *pembaca) detik(key string, bawaan float64) time.Duration {
+	return time.Duration(p.angka(key, bawaan) * float64(time.Second))
+}
+
+func (p *pembaca) benar(key string, bawaan bool) bool {
+	v := p.teks(key, "")
+	if v == "" {
+		return bawaan
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.gagal("%s harus berupa true atau false, bukan %q", key, v)
+		return bawaan
+	}
+	return b
+}
+
+// daftar memecah nilai yang dipisahkan koma dan membuang bagian kosong.
+func (p *pembaca) daftar(key string) []string {
+	var hasil []string
+	for _, v := range strings.Split(p.teks(key, ""), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			hasil = append(hasil, v)
+		}
+	}
+	return hasil
+}
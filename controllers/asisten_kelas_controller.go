@@ -1,7 +1,7 @@
 package controllers
 
 import (
-	"forum_asisten/config"
+	"forum_asisten/authz"
 	"forum_asisten/models"
 	"net/http"
 	"strconv"
@@ -10,7 +10,16 @@ import (
 	"gorm.io/gorm"
 )
 
-func PilihJadwalAsisten(c *gin.Context) {
+// buangPlottingTerhapus menghapus permanen plotting di tempat sampah untuk
+// jadwal dan asisten yang sama sebelum plotting baru dibuat, karena indeks
+// unik jadwal-asisten juga berlaku untuk baris yang sudah dihapus.
+func buangPlottingTerhapus(tx *gorm.DB, jadwalID, asistenID uint) error {
+	return tx.Unscoped().
+		Where("jadwal_id = ? AND asisten_id = ? AND deleted_at IS NOT NULL", jadwalID, asistenID).
+		Delete(&models.AsistenKelas{}).Error
+}
+
+func (h *Handler) PilihJadwalAsisten(c *gin.Context) {
 	// Ambil ID user dan role dari token
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
@@ -43,21 +52,25 @@ func PilihJadwalAsisten(c *gin.Context) {
 
 	// Cek apakah jadwal dengan ID tersebut ada di DB
 	var jadwal models.Jadwal
-	if err := config.DB.First(&jadwal, input.JadwalID).Error; err != nil {
+	if err := h.DB.First(&jadwal, input.JadwalID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Jadwal tidak ditemukan"})
 		return
 	}
+	if periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+		return
+	}
 
 	// Ambil data user
 	var user models.User
-	if err := config.DB.First(&user, userID).Error; err != nil {
+	if err := h.DB.First(&user, userID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pengguna"})
 		return
 	}
 
 	// Cek apakah user sudah pernah memilih jadwal ini
 	var existing models.AsistenKelas
-	if err := config.DB.
+	if err := h.DB.
 		Where("jadwal_id = ? AND asisten_id = ?", input.JadwalID, userID).
 		First(&existing).Error; err == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Jadwal sudah pernah dipilih"})
@@ -68,6 +81,7 @@ func PilihJadwalAsisten(c *gin.Context) {
 	asistenKelas := models.AsistenKelas{
 		JadwalID:  input.JadwalID,
 		AsistenID: userID,
+		PeriodeID: jadwal.PeriodeID,
 	}
 
 	// Pastikan user.NIM tidak nil pointer
@@ -75,7 +89,16 @@ func PilihJadwalAsisten(c *gin.Context) {
 	// 	asistenKelas.Nama = *user.Nama
 	// }
 
-	if err := config.DB.Create(&asistenKelas).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := buangPlottingTerhapus(tx, asistenKelas.JadwalID, asistenKelas.AsistenID); err != nil {
+			return err
+		}
+		if err := tx.Create(&asistenKelas).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "asisten_kelas", asistenKelas.ID, "buat", nil, asistenKelas)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memilih jadwal"})
 		return
 	}
@@ -83,7 +106,7 @@ func PilihJadwalAsisten(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Berhasil memilih jadwal", "data": asistenKelas})
 }
 
-func AdminPilihJadwalAsisten(c *gin.Context) {
+func (h *Handler) AdminPilihJadwalAsisten(c *gin.Context) {
 	var input struct {
 		JadwalID  uint `json:"jadwal_id" binding:"required"`
 		AsistenID uint `json:"asisten_id" binding:"required"`
@@ -95,21 +118,28 @@ func AdminPilihJadwalAsisten(c *gin.Context) {
 
 	// Verify schedule exists
 	var jadwal models.Jadwal
-	if err := config.DB.First(&jadwal, input.JadwalID).Error; err != nil {
+	if err := h.DB.First(&jadwal, input.JadwalID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Schedule not found"})
 		return
 	}
+	if !cekProdi(c, authz.KelolaPlotting, prodiJadwal(h.DB, jadwal.ID)) {
+		return
+	}
+	if periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Academic period is closed"})
+		return
+	}
 
 	// Verify assistant exists
 	var asisten models.User
-	if err := config.DB.First(&asisten, input.AsistenID).Error; err != nil {
+	if err := h.DB.First(&asisten, input.AsistenID).Error; err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Assistant not found"})
 		return
 	}
 
 	// Check if assignment already exists
 	var existing models.AsistenKelas
-	if err := config.DB.
+	if err := h.DB.
 		Where("jadwal_id = ? AND asisten_id = ?", input.JadwalID, input.AsistenID).
 		First(&existing).Error; err == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Assistant already assigned to this schedule"})
@@ -120,9 +150,19 @@ func AdminPilihJadwalAsisten(c *gin.Context) {
 	asistenKelas := models.AsistenKelas{
 		JadwalID:  input.JadwalID,
 		AsistenID: input.AsistenID,
+		PeriodeID: jadwal.PeriodeID,
 	}
 
-	if err := config.DB.Create(&asistenKelas).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := buangPlottingTerhapus(tx, asistenKelas.JadwalID, asistenKelas.AsistenID); err != nil {
+			return err
+		}
+		if err := tx.Create(&asistenKelas).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "asisten_kelas", asistenKelas.ID, "buat", nil, asistenKelas)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign assistant"})
 		return
 	}
@@ -133,11 +173,17 @@ func AdminPilihJadwalAsisten(c *gin.Context) {
 	})
 }
 
-func GetJadwalAsisten(c *gin.Context) {
+func (h *Handler) GetJadwalAsisten(c *gin.Context) {
 	// userID := c.GetUint("user_id") // dari JWT
+	periode, ok := h.periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	var data []models.AsistenKelas
 
-	if err := config.DB.Preload("Jadwal").Preload("User").Preload("Jadwal.MataKuliah.ProgramStudi").Preload("Jadwal.Dosen").
+	query := h.batasiProdi(c, h.DB.Where("periode_id = ?", periode.ID), authz.KelolaPlotting, "jadwal_id")
+	if err := query.Preload("Jadwal").Preload("User", termasukTerhapus).Preload("Jadwal.MataKuliah.ProgramStudi").Preload("Jadwal.Dosen").
 		Find(&data).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data"})
 		return
@@ -146,7 +192,7 @@ func GetJadwalAsisten(c *gin.Context) {
 	c.JSON(http.StatusOK, data)
 }
 
-func GetJadwalAsistenById(c *gin.Context) {
+func (h *Handler) GetJadwalAsistenById(c *gin.Context) {
     // Get user ID from path parameter
     userId := c.Param("user_id")
     if userId == "" {
@@ -161,15 +207,20 @@ func GetJadwalAsistenById(c *gin.Context) {
         return
     }
 
+    periode, ok := h.periodeDariQuery(c)
+    if !ok {
+        return
+    }
+
     var data []models.AsistenKelas
 
     // Query with proper joins and preloading
-    if err := config.DB.
+    if err := h.DB.
         Preload("Jadwal", func(db *gorm.DB) *gorm.DB {
             return db.Preload("MataKuliah.ProgramStudi").Preload("Dosen")
         }).
-        Preload("User").
-        Where("asisten_id = ?", uint(userID)).
+        Preload("User", termasukTerhapus).
+        Where("asisten_id = ? AND periode_id = ?", uint(userID), periode.ID).
         Find(&data).Error; err != nil {
             
         c.JSON(http.StatusInternalServerError, gin.H{
@@ -188,11 +239,11 @@ func GetJadwalAsistenById(c *gin.Context) {
     c.JSON(http.StatusOK, data)
 }
 
-func UpdateAsistenKelas(c *gin.Context) {
+func (h *Handler) UpdateAsistenKelas(c *gin.Context) {
 	id := c.Param("id")
 	var data models.AsistenKelas
 
-	if err := config.DB.First(&data, id).Error; err != nil {
+	if err := h.DB.First(&data, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Data tidak ditemukan"})
 		return
 	}
@@ -203,10 +254,44 @@ func UpdateAsistenKelas(c *gin.Context) {
 		return
 	}
 
+	var jadwal models.Jadwal
+	if err := h.DB.First(&jadwal, input.JadwalID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+	if !cekProdi(c, authz.KelolaPlotting, prodiJadwal(h.DB, data.JadwalID)) ||
+		!cekProdi(c, authz.KelolaPlotting, prodiJadwal(h.DB, jadwal.ID)) {
+		return
+	}
+	if periodeTerkunci(h.DB, data.PeriodeID) || periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
+
+	var bentrok int64
+	h.DB.Model(&models.AsistenKelas{}).
+		Where("jadwal_id = ? AND asisten_id = ? AND id <> ?", input.JadwalID, input.AsistenID, data.ID).
+		Count(&bentrok)
+	if bentrok > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Asisten sudah diplot pada jadwal tersebut"})
+		return
+	}
+
+	sebelum := data
 	data.JadwalID = input.JadwalID
 	data.AsistenID = input.AsistenID
-
-	if err := config.DB.Save(&data).Error; err != nil {
+	data.PeriodeID = jadwal.PeriodeID
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := buangPlottingTerhapus(tx, data.JadwalID, data.AsistenID); err != nil {
+			return err
+		}
+		if err := tx.Save(&data).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "asisten_kelas", data.ID, "ubah", sebelum, data)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate data"})
 		return
 	}
@@ -214,7 +299,7 @@ func UpdateAsistenKelas(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Berhasil diupdate", "data": data})
 }
 
-func DeleteAsistenFromJadwal(c *gin.Context) {
+func (h *Handler) DeleteAsistenFromJadwal(c *gin.Context) {
     jadwalID := c.Param("jadwal_id")
     asistenID := c.Param("asisten_id")
 
@@ -231,10 +316,41 @@ func DeleteAsistenFromJadwal(c *gin.Context) {
         return
     }
 
+    // Tanpa izin plotting (route non-admin), asisten hanya boleh melepas
+    // plotting miliknya sendiri
+    if _, ok := hakDari(c); !ok {
+        userID, ok := ambilUserID(c)
+        if !ok {
+            return
+        }
+        if uint(asistenIDUint) != userID {
+            c.JSON(http.StatusForbidden, gin.H{"error": "Anda hanya dapat melepas plotting milik sendiri"})
+            return
+        }
+    } else if !cekProdi(c, authz.KelolaPlotting, prodiJadwal(h.DB, uint(jadwalIDUint))) {
+        return
+    }
+
+    var jadwal models.Jadwal
+    if err := h.DB.First(&jadwal, jadwalIDUint).Error; err == nil && periodeTerkunci(h.DB, jadwal.PeriodeID) {
+        c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+        return
+    }
+
+    var plotting models.AsistenKelas
+    if err := h.DB.Where("jadwal_id = ? AND asisten_id = ?", jadwalIDUint, asistenIDUint).First(&plotting).Error; err != nil {
+        c.JSON(http.StatusNotFound, gin.H{"error": "Plotting tidak ditemukan"})
+        return
+    }
+
     // Delete the record
-    if err := config.DB.
-        Where("jadwal_id = ? AND asisten_id = ?", jadwalIDUint, asistenIDUint).
-        Delete(&models.AsistenKelas{}).Error; err != nil {
+    err = h.DB.Transaction(func(tx *gorm.DB) error {
+        if err := tx.Delete(&plotting).Error; err != nil {
+            return err
+        }
+        return catatAudit(c, tx, "asisten_kelas", plotting.ID, "hapus", plotting, nil)
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete"})
         return
     }
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"forum_asisten/models"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// jsonAudit mengubah data menjadi JSON; nil tetap nil agar tercatat NULL.
+func jsonAudit(data interface{}) json.RawMessage {
+	if data == nil {
+		return nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// potongTeks memotong s menjadi paling banyak n karakter. Header dari klien
+// bisa sepanjang apa pun, sedangkan kolom audit berukuran tetap; insert yang
+// gagal akan membatalkan transaksi perubahan yang diaudit.
+func potongTeks(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// catatAudit menulis jejak perubahan entitas oleh user yang sedang login.
+// Di dalam transaksi, kembalikan errornya agar perubahan tanpa jejak ikut
+// dibatalkan.
+func catatAudit(c *gin.Context, db *gorm.DB, entitas string, id interface{}, aksi string, sebelum, sesudah interface{}) error {
+	entri := models.AuditLog{
+		Entitas:   entitas,
+		EntitasID: fmt.Sprint(id),
+		Aksi:      aksi,
+		Sebelum:   jsonAudit(sebelum),
+		Sesudah:   jsonAudit(sesudah),
+		IP:        potongTeks(c.ClientIP(), 45),
+		UserAgent: potongTeks(c.Request.UserAgent(), 255),
+		Method:    potongTeks(c.Request.Method, 10),
+		Path:      potongTeks(c.Request.URL.Path, 255),
+	}
+	if v, ok := c.Get("user_id"); ok {
+		if f, ok := v.(float64); ok {
+			userID := uint(f)
+			entri.UserID = &userID
+		}
+	}
+
+	if err := db.Create(&entri).Error; err != nil {
+		log.Println("Gagal mencatat audit:", err)
+		return err
+	}
+	return nil
+}
+
+// GET /admin/audit
+// Filter: user_id, entitas, entitas_id, aksi, dari & sampai (YYYY-MM-DD),
+// halaman, limit.
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	query := h.DB.Model(&models.AuditLog{})
+
+	if v := c.Query("user_id"); v != "" {
+		query = query.Where("user_id = ?", v)
+	}
+	if v := c.Query("entitas"); v != "" {
+		query = query.Where("entitas = ?", v)
+	}
+	if v := c.Query("entitas_id"); v != "" {
+		query = query.Where("entitas_id = ?", v)
+	}
+	if v := c.Query("aksi"); v != "" {
+		query = query.Where("aksi = ?", v)
+	}
+	if v := c.Query("dari"); v != "" {
+		dari, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal dari harus YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("waktu >= ?", dari)
+	}
+	if v := c.Query("sampai"); v != "" {
+		sampai, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal sampai harus YYYY-MM-DD"})
+			return
+		}
+		query = query.Where("waktu < ?", sampai.AddDate(0, 0, 1))
+	}
+
+	halaman, _ := strconv.Atoi(c.DefaultQuery("halaman", "1"))
+	if halaman < 1 {
+		halaman = 1
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil audit log"})
+		return
+	}
+
+	var logs []models.AuditLog
+	if err := query.Preload("User", termasukTerhapus).
+		Order("waktu DESC, id DESC").
+		Offset((halaman - 1) * limit).
+		Limit(limit).
+		Find(&logs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    logs,
+		"total":   total,
+		"halaman": halaman,
+		"limit":   limit,
+	})
+}
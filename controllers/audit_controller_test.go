@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPotongTeks(t *testing.T) {
+	tests := []struct {
+		nama string
+		s    string
+		n    int
+		want string
+	}{
+		{"lebih pendek", "curl/8.0", 255, "curl/8.0"},
+		{"tepat batas", "abc", 3, "abc"},
+		{"dipotong", "abcdef", 3, "abc"},
+		{"multibyte tidak terbelah", "é€日本", 3, "é€日"},
+		{"user agent panjang", strings.Repeat("x", 4096), 255, strings.Repeat("x", 255)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			if got := potongTeks(tt.s, tt.n); got != tt.want {
+				t.Errorf("potongTeks() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
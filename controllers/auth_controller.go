@@ -1,17 +1,15 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
 	"forum_asisten/utils"
-	"io"
 	"net/http"
-	"os"
-	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type RegisterInput struct {
@@ -22,7 +20,7 @@ type RegisterInput struct {
 	Telepon  *string `json:"telepon,omitempty"`
 }
 
-func Register(c *gin.Context) {
+func (h *Handler) Register(c *gin.Context) {
 	var input RegisterInput
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
@@ -34,7 +32,31 @@ func Register(c *gin.Context) {
 	// 	return
 	// }
 
-	hashedPassword, err := utils.HashPassword(input.Password)
+	input.Email = strings.ToLower(input.Email)
+	// Email milik akun di tempat sampah juga ditolak. Admin (POST
+	// /admin/users) diarahkan ke pemulihan; pendaftar publik tidak.
+	if _, admin := c.Get("user_id"); admin {
+		if !h.cekUnik(c, &models.User{}, "email", input.Email, 0, "Email", "users") {
+			return
+		}
+	} else if b, err := cariBentrokUnik(h.DB, &models.User{}, "email", input.Email, 0); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat user"})
+		return
+	} else if b != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Email sudah terdaftar"})
+		return
+	}
+
+	nim := ""
+	if input.NIM != nil {
+		nim = *input.NIM
+	}
+	if err := utils.ValidasiPassword(input.Password, input.Email, nim); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(input.Password, h.Config.BcryptCost)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal hash password"})
 		return
@@ -50,7 +72,13 @@ func Register(c *gin.Context) {
 		Status:   "non-aktif",
 	}
 
-	if err := config.DB.Create(&user).Error; err != nil {
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "buat", nil, user)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat user"})
 		return
 	}
@@ -58,7 +86,7 @@ func Register(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User berhasil dibuat"})
 }
 
-func Login(c *gin.Context) {
+func (h *Handler) Login(c *gin.Context) {
 	var input struct {
 		Identifier string `json:"identifier" binding:"required"`
 		Password   string `json:"password" binding:"required"`
@@ -69,67 +97,82 @@ func Login(c *gin.Context) {
 		return
 	}
 
+	if tunggu := h.cekBatasLogin(kunciIdentifier(input.Identifier), kunciIP(c.ClientIP())); tunggu > 0 {
+		h.catatLoginDiblokir(c, input.Identifier)
+		responLoginDiblokir(c, tunggu)
+		return
+	}
+
 	var user models.User
 	isEmail := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`).MatchString(input.Identifier)
 
 	var err error
 	if isEmail {
-		err = config.DB.Where("email = ?", strings.ToLower(input.Identifier)).First(&user).Error
+		err = h.DB.Where("email = ?", strings.ToLower(input.Identifier)).First(&user).Error
 	} else {
-		err = config.DB.Where("nim = ?", input.Identifier).First(&user).Error
+		err = h.DB.Where("nim = ?", input.Identifier).First(&user).Error
 	}
 
+	// Pesan disamakan agar tidak bisa dipakai menebak akun yang terdaftar
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email atau NIM tidak ditemukan"})
+		h.bandingkanPasswordPengecoh(input.Password)
+		h.catatLoginGagal(c, input.Identifier, nil, "tidak_ditemukan")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email/NIM atau password salah"})
 		return
 	}
 
 	if !utils.CheckPasswordHash(input.Password, user.Password) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password salah"})
+		h.catatLoginGagal(c, input.Identifier, &user.ID, "password_salah")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Email/NIM atau password salah"})
 		return
 	}
 
-	nim := ""
-	if user.NIM != nil {
-		nim = *user.NIM
+	h.DB.Where("kunci = ?", kunciIdentifier(input.Identifier)).Delete(&models.PercobaanLogin{})
+
+	if user.Status != "aktif" {
+		responAkunTidakAktif(c, user)
+		return
 	}
-	token, err := utils.GenerateJWT(user.ID, user.Email, user.Nama, nim, user.Role)
+
+	token, err := h.terbitkanToken(c, h.DB, user, "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal generate token"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"token": token,
+		"token":         token.Token,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
 		"user": gin.H{
-			"id":     user.ID,
-			"nama":   user.Nama,
-			"email":  user.Email,
-			"nim":    user.NIM,
-			"role":   user.Role,
-			"status": user.Status,
-			"photo":  user.Photo,
-			"telepon":user.Telepon,
+			"id":      user.ID,
+			"nama":    user.Nama,
+			"email":   user.Email,
+			"nim":     user.NIM,
+			"role":    user.Role,
+			"status":  user.Status,
+			"photo":   user.Photo,
+			"telepon": user.Telepon,
 		},
 		"message": "User berhasil dibuat",
-        "success": true,
+		"success": true,
 	})
 }
 
-func GetUsers(c *gin.Context) {
+func (h *Handler) GetUsers(c *gin.Context) {
 	var users []models.User
-	if err := config.DB.Find(&users).Error; err != nil {
+	if err := h.DB.Find(&users).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data user"})
 		return
 	}
 	c.JSON(http.StatusOK, users)
 }
 
-func GetUserByID(c *gin.Context) {
+func (h *Handler) GetUserByID(c *gin.Context) {
     id := c.Param("id")
     
     var user models.User
-    if err := config.DB.First(&user, id).Error; err != nil {
+    if err := h.DB.First(&user, id).Error; err != nil {
         c.JSON(http.StatusNotFound, gin.H{
             "error":   "User tidak ditemukan",
             "message": "Tidak ada user dengan ID tersebut",
@@ -137,41 +180,90 @@ func GetUserByID(c *gin.Context) {
         return
     }
     
-    // You might want to omit sensitive fields like password
-    responseUser := gin.H{
-        "id":      user.ID,
-        "nama":    user.Nama,
-        "email":   user.Email,
-        "nim":     user.NIM,
-        "telepon": user.Telepon,
-        "role":    user.Role,
-        "status":  user.Status,
-        "photo":   user.Photo,
-    }
-    
     c.JSON(http.StatusOK, gin.H{
-        "data":    responseUser,
+        "data":    profilUser(user),
         "message": "User ditemukan",
         "success": true,
     })
 }
 
-func UpdateUser(c *gin.Context) {
+// UpdateUserInput hanya memuat field yang boleh diubah admin. Status diubah
+// lewat UpdateUserStatus; password baru (opsional) mencabut semua sesi user.
+type UpdateUserInput struct {
+	Nama     *string `json:"nama"`
+	Email    *string `json:"email" binding:"omitempty,email"`
+	NIM      *string `json:"nim"`
+	Telepon  *string `json:"telepon"`
+	Role     *string `json:"role" binding:"omitempty,oneof=admin asisten"`
+	Password *string `json:"password"`
+}
+
+func (h *Handler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
-	var input models.User
+	var input UpdateUserInput
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
 		return
 	}
 
 	var user models.User
-	if err := config.DB.First(&user, id).Error; err != nil {
+	if err := h.DB.First(&user, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
 		return
 	}
 
-	if err := config.DB.Model(&user).Updates(input).Error; err != nil {
+	updates := map[string]interface{}{}
+	if input.Nama != nil {
+		updates["nama"] = *input.Nama
+	}
+	if input.Email != nil {
+		updates["email"] = strings.ToLower(*input.Email)
+		if !h.cekUnik(c, &models.User{}, "email", updates["email"], user.ID, "Email", "users") {
+			return
+		}
+	}
+	if input.NIM != nil {
+		updates["nim"] = input.NIM
+	}
+	if input.Telepon != nil {
+		updates["telepon"] = input.Telepon
+	}
+	if input.Role != nil {
+		updates["role"] = *input.Role
+	}
+	if input.Password != nil {
+		if err := validasiPasswordUser(*input.Password, user); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	sebelum := user
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if input.Password != nil {
+			if err := h.gantiPassword(tx, user.ID, *input.Password); err != nil {
+				return err
+			}
+		}
+		// Token lama membawa role lama; sesi dicabut seperti saat status berubah
+		if input.Role != nil && *input.Role != sebelum.Role {
+			if err := cabutSemuaToken(tx, user.ID); err != nil {
+				return err
+			}
+		}
+		var sesudah models.User
+		if err := tx.First(&sesudah, user.ID).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "ubah", sebelum, sesudah)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui user"})
 		return
 	}
@@ -179,81 +271,8 @@ func UpdateUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User berhasil diperbarui"})
 }
 
-func UpdateUserAsisten(c *gin.Context) {
-    id := c.Param("id")
-    
-    // Get the existing user first
-    var user models.User
-    if err := config.DB.First(&user, id).Error; err != nil {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
-        return
-    }
-
-    // Parse form data (support both multipart and urlencoded)
-    if err := c.Request.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal parsing form data"})
-        return
-    }
-
-    // Handle text fields
-    updatedData := map[string]interface{}{
-        "nama":    c.PostForm("nama"),
-        "email":   c.PostForm("email"),
-        "nim":     c.PostForm("nim"),
-        "telepon": c.PostForm("telepon"),
-    }
-
-    // Handle photo - bisa berupa file upload atau URL string
-    photoUrl := c.PostForm("photo") // Untuk URL dari Cloudinary
-    
-    // Jika ada file upload, proses upload lokal
-    file, header, err := c.Request.FormFile("photo")
-    if err == nil {
-        defer file.Close()
-
-        // Create uploads directory if not exists
-        if err := os.MkdirAll("uploads", os.ModePerm); err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat direktori"})
-            return
-        }
-
-        // Create a new file in the uploads directory
-        filename := "user_" + id + filepath.Ext(header.Filename)
-        dst, err := os.Create(filepath.Join("uploads", filename))
-        if err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan file"})
-            return
-        }
-        defer dst.Close()
-
-        // Copy the uploaded file to the filesystem
-        if _, err := io.Copy(dst, file); err != nil {
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyalin file"})
-            return
-        }
-
-        // Update photo path in database
-        photoPath := "/uploads/" + filename
-        updatedData["photo"] = photoPath
-    } else if photoUrl != "" {
-        // Jika ada URL dari Cloudinary, gunakan itu
-        updatedData["photo"] = photoUrl
-    }
-
-    // Update the user in database
-    if err := config.DB.Model(&user).Updates(updatedData).Error; err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui user"})
-        return
-    }
-
-    c.JSON(http.StatusOK, gin.H{
-        "message": "User berhasil diperbarui",
-        "data":    user,
-    })
-}
-
 // Tambahkan endpoint khusus untuk update status
-func UpdateUserStatus(c *gin.Context) {
+func (h *Handler) UpdateUserStatus(c *gin.Context) {
     id := c.Param("id")
     
     // Struct khusus untuk menerima input status
@@ -265,9 +284,14 @@ func UpdateUserStatus(c *gin.Context) {
         c.JSON(http.StatusBadRequest, gin.H{"error": "Status harus diisi dengan 'aktif' atau 'non-aktif'"})
         return
     }
+
+    adminID, ok := ambilUserID(c)
+    if !ok {
+        return
+    }
     
     var user models.User
-    if err := config.DB.First(&user, id).Error; err != nil {
+    if err := h.DB.First(&user, id).Error; err != nil {
         c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
         return
     }
@@ -275,8 +299,30 @@ func UpdateUserStatus(c *gin.Context) {
     // Normalisasi status ke lowercase
     normalizedStatus := strings.ToLower(input.Status)
     
-    // Update hanya field status
-    if err := config.DB.Model(&user).Update("status", normalizedStatus).Error; err != nil {
+    // Update hanya field status; perubahan status mencabut semua sesi user
+    err := h.DB.Transaction(func(tx *gorm.DB) error {
+        updates := map[string]interface{}{"status": normalizedStatus}
+        // Mengaktifkan akun yang belum diperiksa sama dengan menyetujui pendaftarannya
+        if normalizedStatus == "aktif" && user.DiverifikasiPada == nil {
+            now := time.Now()
+            updates["diverifikasi_pada"] = &now
+            updates["diverifikasi_oleh"] = adminID
+        }
+        if err := tx.Model(&models.User{ID: user.ID}).Updates(updates).Error; err != nil {
+            return err
+        }
+        if user.Status != normalizedStatus {
+            if err := cabutSemuaToken(tx, user.ID); err != nil {
+                return err
+            }
+        }
+        var sesudah models.User
+        if err := tx.First(&sesudah, user.ID).Error; err != nil {
+            return err
+        }
+        return catatAudit(c, tx, "user", user.ID, "ubah_status", user, sesudah)
+    })
+    if err != nil {
         c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui status user"})
         return
     }
@@ -287,9 +333,41 @@ func UpdateUserStatus(c *gin.Context) {
     })
 }
 
-func DeleteUser(c *gin.Context) {
+// DeleteUser menghapus user secara soft delete. Presensi dan rekapitulasi
+// tetap tersimpan; plotting pada periode yang belum ditutup ikut dihapus dan
+// dipulihkan bersama user.
+func (h *Handler) DeleteUser(c *gin.Context) {
+	adminID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.User{}, id).Error; err != nil {
+	var user models.User
+	if err := h.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if user.ID == adminID {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tidak dapat menghapus akun sendiri"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := cabutSemuaToken(tx, user.ID); err != nil {
+			return err
+		}
+		periodeTerbuka := tx.Model(&models.Periode{}).Select("id").Where("ditutup = ?", false)
+		if err := tx.Where("asisten_id = ? AND periode_id IN (?)", user.ID, periodeTerbuka).
+			Delete(&models.AsistenKelas{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "hapus", user, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus user"})
 		return
 	}
@@ -0,0 +1,216 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/authz"
+	"forum_asisten/middlewares"
+	"forum_asisten/models"
+	"forum_asisten/storage"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	tipeGambar       = []string{"image/jpeg", "image/png", "image/gif"}
+	tipeGambarDanPDF = []string{"image/jpeg", "image/png", "image/gif", "application/pdf"}
+)
+
+// tipeBerkasKategori menentukan prefix storage dan tipe yang diizinkan per kategori.
+var tipeBerkasKategori = map[string]struct {
+	prefix  string
+	izinkan []string
+}{
+	"bukti_kehadiran": {"presensi", tipeGambar},
+	"bukti_izin":      {"presensi", tipeGambarDanPDF},
+	"foto":            {"foto", tipeGambar},
+}
+
+// simpanBerkas memproses berkas unggahan dan mencatat metadatanya.
+func (h *Handler) simpanBerkas(c *gin.Context, fileHeader *multipart.FileHeader, kategori string, pemilikID uint) (models.Berkas, error) {
+	aturan, ok := tipeBerkasKategori[kategori]
+	if !ok {
+		return models.Berkas{}, errors.New("kategori berkas tidak valid")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return models.Berkas{}, err
+	}
+	defer file.Close()
+
+	hasil, err := storage.Unggah(c.Request.Context(), h.Storage, aturan.prefix, file, h.Config.BatasUpload, aturan.izinkan...)
+	if err != nil {
+		return models.Berkas{}, err
+	}
+
+	berkas := models.Berkas{
+		PemilikID:    pemilikID,
+		Kategori:     kategori,
+		NamaAsli:     fileHeader.Filename,
+		Key:          hasil.Key,
+		ThumbnailKey: hasil.ThumbnailKey,
+		ContentType:  hasil.ContentType,
+		Ukuran:       hasil.Ukuran,
+		SHA256:       hasil.SHA256,
+	}
+	if err := h.DB.Create(&berkas).Error; err != nil {
+		return models.Berkas{}, err
+	}
+	return berkas, nil
+}
+
+// responErrorBerkas memetakan error unggahan ke status HTTP yang sesuai.
+func responErrorBerkas(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrTerlaluBesar):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Ukuran berkas melebihi batas"})
+	case errors.Is(err, storage.ErrTipeTidakDidukung):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Tipe berkas tidak didukung", "detail": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan berkas"})
+	}
+}
+
+// ambilBerkasMilik memastikan berkas ada, milik user, dan berkategori sesuai.
+func (h *Handler) ambilBerkasMilik(id *uint, pemilikID uint, kategori string) (*models.Berkas, error) {
+	if id == nil {
+		return nil, nil
+	}
+	var berkas models.Berkas
+	if err := h.DB.First(&berkas, *id).Error; err != nil {
+		return nil, errors.New("Berkas tidak ditemukan")
+	}
+	if berkas.PemilikID != pemilikID || berkas.Kategori != kategori {
+		return nil, errors.New("Berkas tidak dapat dipakai untuk " + kategori)
+	}
+	return &berkas, nil
+}
+
+// POST /berkas (multipart: file, kategori)
+func (h *Handler) UnggahBerkas(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	kategori := c.PostForm("kategori")
+	if _, ok := tipeBerkasKategori[kategori]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kategori harus bukti_kehadiran, bukti_izin, atau foto"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Berkas wajib diunggah pada field file"})
+		return
+	}
+
+	berkas, err := h.simpanBerkas(c, fileHeader, kategori, userID)
+	if err != nil {
+		responErrorBerkas(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Berkas berhasil diunggah", "data": berkas, "url": berkas.URL()})
+}
+
+// GET /berkas/:id
+func (h *Handler) UnduhBerkas(c *gin.Context) {
+	h.kirimBerkas(c, false)
+}
+
+// GET /berkas/:id/thumbnail
+func (h *Handler) UnduhThumbnailBerkas(c *gin.Context) {
+	h.kirimBerkas(c, true)
+}
+
+// kirimBerkas mengirim isi berkas. Foto profil boleh dilihat semua user yang
+// login; bukti presensi hanya oleh pemiliknya dan pengelola presensi program
+// studi jadwalnya.
+func (h *Handler) kirimBerkas(c *gin.Context, thumbnail bool) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var berkas models.Berkas
+	if err := h.DB.First(&berkas, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Berkas tidak ditemukan"})
+		return
+	}
+	if berkas.Kategori != "foto" && berkas.PemilikID != userID {
+		boleh, err := h.bolehLihatBukti(userID, berkas)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa hak akses berkas"})
+			return
+		}
+		if !boleh {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak berhak mengakses berkas ini"})
+			return
+		}
+	}
+
+	key, etag := berkas.Key, berkas.SHA256
+	if thumbnail {
+		if berkas.ThumbnailKey == "" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Berkas tidak memiliki thumbnail"})
+			return
+		}
+		key, etag = berkas.ThumbnailKey, berkas.SHA256+"-thumb"
+	}
+
+	reader, info, err := h.Storage.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Isi berkas tidak ditemukan"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membaca berkas"})
+		return
+	}
+	defer reader.Close()
+
+	contentType := berkas.ContentType
+	if info.ContentType != "" {
+		contentType = info.ContentType
+	}
+
+	// Nama berkas berbasis hash sehingga isinya tidak pernah berubah
+	c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	c.Header("ETag", strconv.Quote(etag))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, info.Size, contentType, reader, nil)
+}
+
+// bolehLihatBukti mengecek izin KelolaPresensi user atas bukti milik asisten
+// lain: tanpa batas program studi, atau pada program studi jadwal presensi
+// yang memakai bukti tersebut. Presensi di tempat sampah ikut dihitung.
+func (h *Handler) bolehLihatBukti(userID uint, berkas models.Berkas) (bool, error) {
+	hak, err := middlewares.MuatHak(h.DB, userID)
+	if err != nil {
+		return false, err
+	}
+	if _, semua := hak.Cakupan(authz.KelolaPresensi); semua {
+		return true, nil
+	}
+	if !hak.Punya(authz.KelolaPresensi) {
+		return false, nil
+	}
+
+	var jadwalIDs []uint
+	err = h.DB.Unscoped().Model(&models.Presensi{}).
+		Where("bukti_kehadiran_id = ? OR bukti_izin_id = ?", berkas.ID, berkas.ID).
+		Distinct().Pluck("jadwal_id", &jadwalIDs).Error
+	if err != nil {
+		return false, err
+	}
+	for _, jadwalID := range jadwalIDs {
+		if hak.Boleh(authz.KelolaPresensi, prodiJadwal(h.DB, jadwalID)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
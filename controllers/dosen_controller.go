@@ -1,39 +1,45 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func CreateDosen(c *gin.Context) {
+func (h *Handler) CreateDosen(c *gin.Context) {
 	var dosen models.Dosen
 	if err := c.ShouldBindJSON(&dosen); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
 		return
 	}
-	if err := config.DB.Create(&dosen).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&dosen).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "dosen", dosen.ID, "buat", nil, dosen)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan dosen"})
 		return
 	}
 	c.JSON(http.StatusCreated, dosen)
 }
 
-func GetAllDosen(c *gin.Context) {
+func (h *Handler) GetAllDosen(c *gin.Context) {
 	var dosen []models.Dosen
-	if err := config.DB.Find(&dosen).Error; err != nil {
+	if err := h.DB.Find(&dosen).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data dosen"})
 		return
 	}
 	c.JSON(http.StatusOK, dosen)
 }
 
-func UpdateDosen(c *gin.Context) {
+func (h *Handler) UpdateDosen(c *gin.Context) {
 	id := c.Param("id")
 	var dosen models.Dosen
-	if err := config.DB.First(&dosen, id).Error; err != nil {
+	if err := h.DB.First(&dosen, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
 		return
 	}
@@ -44,14 +50,39 @@ func UpdateDosen(c *gin.Context) {
 		return
 	}
 
+	sebelum := dosen
 	dosen.Nama = input.Nama
-	config.DB.Save(&dosen)
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&dosen).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "dosen", dosen.ID, "ubah", sebelum, dosen)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui dosen"})
+		return
+	}
 	c.JSON(http.StatusOK, dosen)
 }
 
-func DeleteDosen(c *gin.Context) {
+func (h *Handler) DeleteDosen(c *gin.Context) {
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.Dosen{}, id).Error; err != nil {
+	var dosen models.Dosen
+	if err := h.DB.First(&dosen, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dosen tidak ditemukan"})
+		return
+	}
+	if h.masihDipakai(&models.Jadwal{}, "dosen_id", dosen.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Dosen masih dipakai jadwal"})
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&dosen).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "dosen", dosen.ID, "hapus", dosen, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus dosen"})
 		return
 	}
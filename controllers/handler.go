@@ -0,0 +1,14 @@
+package controllers
+
+import "forum_asisten/app"
+
+// Handler memegang dependensi yang dipakai semua handler HTTP. Setiap handler
+// adalah method Handler sehingga tidak ada yang membaca state global.
+type Handler struct {
+	*app.App
+}
+
+// New membuat Handler di atas App.
+func New(a *app.App) *Handler {
+	return &Handler{App: a}
+}
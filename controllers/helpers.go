@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"fmt"
+	"forum_asisten/authz"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// termasukTerhapus dipakai pada preload data riwayat agar relasi yang sudah
+// dihapus (soft delete) tetap tampil, bukan struct kosong.
+func termasukTerhapus(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// masihDipakai menghitung baris aktif yang masih merujuk sebuah data.
+func (h *Handler) masihDipakai(model interface{}, kolom string, id interface{}) bool {
+	var jumlah int64
+	h.DB.Model(model).Where(kolom+" = ?", id).Count(&jumlah)
+	return jumlah > 0
+}
+
+// ambilUserID membaca user_id dari claims token yang disimpan AuthMiddleware.
+// Jika tidak ada, response 401 sudah dikirim dan ok bernilai false.
+func ambilUserID(c *gin.Context) (uint, bool) {
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID tidak ditemukan"})
+		return 0, false
+	}
+
+	// Token user_id bertipe float64 saat di-unmarshal
+	userIDFloat, ok := userIDVal.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID token tidak valid"})
+		return 0, false
+	}
+	return uint(userIDFloat), true
+}
+
+// aktorDari membentuk authz.Aktor dari claims token.
+func aktorDari(c *gin.Context) (authz.Aktor, bool) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return authz.Aktor{}, false
+	}
+	return authz.Aktor{UserID: userID, Role: c.GetString("role")}, true
+}
+
+// hakDari membaca hak user yang disimpan middleware RequirePermission.
+// ok bernilai false jika route tidak memakai RequirePermission.
+func hakDari(c *gin.Context) (authz.Hak, bool) {
+	v, exists := c.Get("hak")
+	if !exists {
+		return nil, false
+	}
+	hak, ok := v.(authz.Hak)
+	return hak, ok
+}
+
+// cekProdi memastikan izin user berlaku pada program studi pemilik data.
+// Jika tidak, response 403 sudah dikirim dan hasilnya false.
+func cekProdi(c *gin.Context, izin authz.Izin, programStudiID uint) bool {
+	hak, ok := hakDari(c)
+	if !ok || hak.Boleh(izin, programStudiID) {
+		return true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "Data ini di luar program studi Anda"})
+	return false
+}
+
+// prodiJadwal mengembalikan program studi dari mata kuliah sebuah jadwal.
+func prodiJadwal(db *gorm.DB, jadwalID uint) uint {
+	var programStudiID uint
+	db.Table("jadwals").
+		Select("mata_kuliahs.program_studi_id").
+		Joins("JOIN mata_kuliahs ON mata_kuliahs.id = jadwals.mata_kuliah_id").
+		Where("jadwals.id = ?", jadwalID).
+		Scan(&programStudiID)
+	return programStudiID
+}
+
+// batasiProdi membatasi query pada data yang jadwalnya berada dalam cakupan
+// program studi izin user. kolomJadwal adalah kolom jadwal_id pada query.
+func (h *Handler) batasiProdi(c *gin.Context, query *gorm.DB, izin authz.Izin, kolomJadwal string) *gorm.DB {
+	hak, ok := hakDari(c)
+	if !ok {
+		return query
+	}
+	prodi, semua := hak.Cakupan(izin)
+	if semua {
+		return query
+	}
+	jadwal := h.DB.Table("jadwals").
+		Select("jadwals.id").
+		Joins("JOIN mata_kuliahs ON mata_kuliahs.id = jadwals.mata_kuliah_id").
+		Where("mata_kuliahs.program_studi_id IN ?", prodi)
+	return query.Where(kolomJadwal+" IN (?)", jadwal)
+}
+
+// bentrokUnik adalah baris lain yang sudah memakai nilai kolom unik.
+type bentrokUnik struct {
+	ID        uint
+	DeletedAt gorm.DeletedAt
+}
+
+// cariBentrokUnik mencari baris selain kecuali yang memakai nilai kolom unik,
+// termasuk yang ada di tempat sampah: indeks unik tetap berlaku untuk baris
+// soft delete, sehingga insert akan gagal di database.
+func cariBentrokUnik(db *gorm.DB, model interface{}, kolom string, nilai interface{}, kecuali uint) (*bentrokUnik, error) {
+	var list []bentrokUnik
+	err := db.Unscoped().Model(model).Select("id", "deleted_at").
+		Where(kolom+" = ? AND id <> ?", nilai, kecuali).Limit(1).Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// cekUnik mengirim 409 jika nilai kolom unik sudah dipakai. Bila pemakainya
+// ada di tempat sampah, respons menunjuk endpoint pemulihannya. Hasilnya
+// false jika response sudah dikirim.
+func (h *Handler) cekUnik(c *gin.Context, model interface{}, kolom string, nilai interface{}, kecuali uint, label, entitasSampah string) bool {
+	b, err := cariBentrokUnik(h.DB, model, kolom, nilai, kecuali)
+	switch {
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa data"})
+		return false
+	case b == nil:
+		return true
+	case b.DeletedAt.Valid:
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       label + " sudah dipakai data di tempat sampah; pulihkan data tersebut atau gunakan nilai lain",
+			"terhapus_id": b.ID,
+			"pulihkan":    fmt.Sprintf("/api/admin/sampah/%s/%d/pulihkan", entitasSampah, b.ID),
+		})
+		return false
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": label + " sudah digunakan"})
+		return false
+	}
+}
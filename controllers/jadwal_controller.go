@@ -1,21 +1,27 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
+	"forum_asisten/utils"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func CreateJadwal(c *gin.Context) {
+func (h *Handler) CreateJadwal(c *gin.Context) {
 	var jadwal models.Jadwal
 	if err := c.ShouldBindJSON(&jadwal); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
 		return
 	}
 
+	if _, err := utils.ParseHari(jadwal.Hari); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hari tidak valid, gunakan Senin s.d. Minggu"})
+		return
+	}
+
 	// Validasi format jam
 	if _, err := time.Parse("15:04", jadwal.JamMulai); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format jam_mulai harus HH:MM"})
@@ -26,7 +32,31 @@ func CreateJadwal(c *gin.Context) {
 		return
 	}
 
-	if err := config.DB.Create(&jadwal).Error; err != nil {
+	// Jadwal baru masuk ke periode aktif jika periode tidak disebutkan
+	if jadwal.PeriodeID == 0 {
+		periode, err := periodeAktif(h.DB)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Belum ada periode akademik yang aktif"})
+			return
+		}
+		jadwal.PeriodeID = periode.ID
+	}
+	if periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
+
+	// Simpan jadwal sekaligus susun pertemuannya sepanjang periode
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&jadwal).Error; err != nil {
+			return err
+		}
+		if _, err := susunPertemuan(tx, jadwal); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "jadwal", jadwal.ID, "buat", nil, jadwal)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan jadwal"})
 		return
 	}
@@ -34,22 +64,33 @@ func CreateJadwal(c *gin.Context) {
 }
 
 
-func GetAllJadwal(c *gin.Context) {
+func (h *Handler) GetAllJadwal(c *gin.Context) {
+	periode, ok := h.periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	var jadwal []models.Jadwal
-	if err := config.DB.Preload("Dosen").Preload("MataKuliah").Preload("MataKuliah.ProgramStudi").Find(&jadwal).Error; err != nil {
+	if err := h.DB.Preload("Dosen").Preload("MataKuliah").Preload("MataKuliah.ProgramStudi").Preload("Periode").
+		Where("periode_id = ?", periode.ID).
+		Find(&jadwal).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data jadwal"})
 		return
 	}
 	c.JSON(http.StatusOK, jadwal)
 }
 
-func UpdateJadwal(c *gin.Context) {
+func (h *Handler) UpdateJadwal(c *gin.Context) {
 	id := c.Param("id")
 	var jadwal models.Jadwal
-	if err := config.DB.First(&jadwal, id).Error; err != nil {
+	if err := h.DB.First(&jadwal, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
 		return
 	}
+	if periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
 
 	var input models.Jadwal
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -57,6 +98,7 @@ func UpdateJadwal(c *gin.Context) {
 		return
 	}
 
+	sebelum := jadwal
 	jadwal.MataKuliahID = input.MataKuliahID
 	jadwal.DosenID = input.DosenID
 	jadwal.Hari = input.Hari
@@ -66,13 +108,69 @@ func UpdateJadwal(c *gin.Context) {
 	jadwal.Kelas = input.Kelas
 	jadwal.Semester = input.Semester
 
-	config.DB.Save(&jadwal)
+	if _, err := utils.ParseHari(jadwal.Hari); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Hari tidak valid, gunakan Senin s.d. Minggu"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&jadwal).Error; err != nil {
+			return err
+		}
+		if _, err := susunPertemuan(tx, jadwal); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "jadwal", jadwal.ID, "ubah", sebelum, jadwal)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui jadwal"})
+		return
+	}
 	c.JSON(http.StatusOK, jadwal)
 }
 
-func DeleteJadwal(c *gin.Context) {
+func (h *Handler) DeleteJadwal(c *gin.Context) {
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.Jadwal{}, id).Error; err != nil {
+	var jadwal models.Jadwal
+	if err := h.DB.First(&jadwal, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+	if periodeTerkunci(h.DB, jadwal.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
+	// Presensi di tempat sampah ikut dihitung karena merujuk pertemuan jadwal ini
+	var jumlahPresensi int64
+	h.DB.Unscoped().Model(&models.Presensi{}).Where("jadwal_id = ?", jadwal.ID).Count(&jumlahPresensi)
+	if jumlahPresensi > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Jadwal sudah memiliki presensi dan tidak dapat dihapus"})
+		return
+	}
+	var jumlahPenggantian int64
+	h.DB.Model(&models.Penggantian{}).
+		Where("pertemuan_id IN (?)", h.DB.Model(&models.Pertemuan{}).Select("id").Where("jadwal_id = ?", jadwal.ID)).
+		Count(&jumlahPenggantian)
+	if jumlahPenggantian > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Jadwal memiliki pengajuan penggantian dan tidak dapat dihapus"})
+		return
+	}
+
+	// Plotting asisten ikut dihapus dan dipulihkan bersama jadwal; pertemuan
+	// disusun ulang saat jadwal dipulihkan
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("jadwal_id = ?", jadwal.ID).Delete(&models.Pertemuan{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("jadwal_id = ?", jadwal.ID).Delete(&models.AsistenKelas{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&jadwal).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "jadwal", jadwal.ID, "hapus", jadwal, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus jadwal"})
 		return
 	}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"forum_asisten/migrasi"
+	"forum_asisten/utils"
+	"forum_asisten/versi"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GET /healthz
+// Liveness: proses hidup dan dapat melayani request. Tidak menyentuh
+// dependensi agar gangguan database tidak membuat proses di-restart.
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// GET /readyz
+// Readiness: database dapat dihubungi, storage dapat ditulisi, dan tidak ada
+// migrasi tertunda. Selama server berhenti selalu 503 agar load balancer
+// berhenti mengirim trafik. Rincian kegagalan hanya dicatat di log.
+func (h *Handler) Readyz(c *gin.Context) {
+	if h.Berhenti() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "berhenti"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	siap := true
+	hasil := gin.H{}
+	for _, cek := range []struct {
+		nama  string
+		jalan func(context.Context) error
+	}{
+		{"database", h.cekDatabase},
+		{"storage", h.cekStorage},
+		{"migrasi", h.cekMigrasi},
+	} {
+		if err := cek.jalan(ctx); err != nil {
+			log.Printf("Readiness %s gagal: %v", cek.nama, err)
+			hasil[cek.nama] = "gagal"
+			siap = false
+			continue
+		}
+		hasil[cek.nama] = "ok"
+	}
+
+	if !siap {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "tidak siap", "periksa": hasil})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "siap", "periksa": hasil})
+}
+
+func (h *Handler) cekDatabase(ctx context.Context) error {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// cekStorage menulis lalu menghapus berkas kecil dengan key acak. Key tanpa
+// direktori agar storage lokal tidak meninggalkan direktori kosong.
+func (h *Handler) cekStorage(ctx context.Context) error {
+	acak, err := utils.TokenAcak()
+	if err != nil {
+		return err
+	}
+	key := "readyz-" + acak
+	if err := h.Storage.Put(ctx, key, strings.NewReader("ok"), 2, "text/plain"); err != nil {
+		return err
+	}
+	return h.Storage.Delete(ctx, key)
+}
+
+// cekMigrasi hanya membaca schema_migrations; readiness tidak mengubah skema.
+func (h *Handler) cekMigrasi(ctx context.Context) error {
+	return migrasi.Periksa(h.DB.WithContext(ctx))
+}
+
+// GET /api/versi
+// Informasi build: versi, commit, dan waktunya.
+func (h *Handler) GetVersi(c *gin.Context) {
+	c.JSON(http.StatusOK, versi.Baca())
+}
@@ -1,39 +1,48 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func CreateMataKuliah(c *gin.Context) {
+func (h *Handler) CreateMataKuliah(c *gin.Context) {
 	var mk models.MataKuliah
 	if err := c.ShouldBindJSON(&mk); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data salah"})
 		return
 	}
-	if err := config.DB.Create(&mk).Error; err != nil {
+	if !h.cekUnik(c, &models.MataKuliah{}, "kode", mk.Kode, 0, "Kode mata kuliah", "mata-kuliah") {
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&mk).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "mata_kuliah", mk.ID, "buat", nil, mk)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan"})
 		return
 	}
 	c.JSON(http.StatusCreated, mk)
 }
 
-func GetAllMataKuliah(c *gin.Context) {
+func (h *Handler) GetAllMataKuliah(c *gin.Context) {
 	var list []models.MataKuliah
-	if err := config.DB.Preload("ProgramStudi").Find(&list).Error; err != nil {
+	if err := h.DB.Preload("ProgramStudi").Find(&list).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data"})
 		return
 	}
 	c.JSON(http.StatusOK, list)
 }
 
-func UpdateMataKuliah(c *gin.Context) {
+func (h *Handler) UpdateMataKuliah(c *gin.Context) {
 	id := c.Param("id")
 	var mk models.MataKuliah
-	if err := config.DB.First(&mk, id).Error; err != nil {
+	if err := h.DB.First(&mk, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Mata kuliah tidak ditemukan"})
 		return
 	}
@@ -44,18 +53,50 @@ func UpdateMataKuliah(c *gin.Context) {
 		return
 	}
 
+	if !h.cekUnik(c, &models.MataKuliah{}, "kode", input.Kode, mk.ID, "Kode mata kuliah", "mata-kuliah") {
+		return
+	}
+
+	sebelum := mk
 	mk.Nama = input.Nama
 	mk.Semester = input.Semester
 	mk.Kode = input.Kode
 	mk.ProgramStudiID = input.ProgramStudiID
+	if input.FaktorHonor > 0 {
+		mk.FaktorHonor = input.FaktorHonor
+	}
 
-	config.DB.Save(&mk)
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&mk).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "mata_kuliah", mk.ID, "ubah", sebelum, mk)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui mata kuliah"})
+		return
+	}
 	c.JSON(http.StatusOK, mk)
 }
 
-func DeleteMataKuliah(c *gin.Context) {
+func (h *Handler) DeleteMataKuliah(c *gin.Context) {
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.MataKuliah{}, id).Error; err != nil {
+	var mk models.MataKuliah
+	if err := h.DB.First(&mk, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Mata kuliah tidak ditemukan"})
+		return
+	}
+	if h.masihDipakai(&models.Jadwal{}, "mata_kuliah_id", mk.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Mata kuliah masih dipakai jadwal"})
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&mk).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "mata_kuliah", mk.ID, "hapus", mk, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus"})
 		return
 	}
@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"forum_asisten/mailer"
+	"forum_asisten/models"
+	"forum_asisten/utils"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errTokenResetTidakValid = errors.New("token reset tidak valid")
+
+// validasiPasswordUser menerapkan aturan kekuatan password terhadap user.
+func validasiPasswordUser(password string, user models.User) error {
+	nim := ""
+	if user.NIM != nil {
+		nim = *user.NIM
+	}
+	return utils.ValidasiPassword(password, user.Email, nim)
+}
+
+// gantiPassword menyimpan hash password baru dan mencabut semua sesi user.
+func (h *Handler) gantiPassword(tx *gorm.DB, userID uint, password string) error {
+	hash, err := utils.HashPassword(password, h.Config.BcryptCost)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("password", hash).Error; err != nil {
+		return err
+	}
+	return cabutSemuaToken(tx, userID)
+}
+
+// POST /password/change
+// Setelah password diganti semua sesi lama dicabut dan sesi baru diterbitkan
+// untuk perangkat yang sedang dipakai.
+func (h *Handler) ChangePassword(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		PasswordLama string `json:"password_lama" binding:"required"`
+		PasswordBaru string `json:"password_baru" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password lama dan password baru wajib diisi"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if !utils.CheckPasswordHash(input.PasswordLama, user.Password) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Password lama salah"})
+		return
+	}
+	if input.PasswordLama == input.PasswordBaru {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password baru harus berbeda dari password lama"})
+		return
+	}
+	if err := validasiPasswordUser(input.PasswordBaru, user); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var token pasanganToken
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := h.gantiPassword(tx, user.ID, input.PasswordBaru); err != nil {
+			return err
+		}
+		if err := tx.First(&user, user.ID).Error; err != nil {
+			return err
+		}
+		var err error
+		token, err = h.terbitkanToken(c, tx, user, "")
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengganti password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Password berhasil diganti",
+		"token":         token.Token,
+		"refresh_token": token.RefreshToken,
+		"expires_in":    token.ExpiresIn,
+	})
+}
+
+// POST /password/forgot
+// Respons selalu sama agar endpoint tidak bisa dipakai untuk menebak email
+// yang terdaftar.
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email tidak valid"})
+		return
+	}
+
+	respon := gin.H{"message": "Jika email terdaftar, tautan reset password telah dikirim"}
+
+	var user models.User
+	if err := h.DB.Where("email = ?", strings.ToLower(input.Email)).First(&user).Error; err != nil || user.Status != "aktif" {
+		c.JSON(http.StatusOK, respon)
+		return
+	}
+
+	token, err := utils.TokenAcak()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token reset"})
+		return
+	}
+	masa := h.Config.Token.ResetPassword
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		// Hanya token terbaru yang berlaku
+		if err := tx.Where("user_id = ? AND dipakai_pada IS NULL", user.ID).Delete(&models.ResetPassword{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ResetPassword{
+			UserID:          user.ID,
+			Hash:            utils.HashToken(token),
+			KedaluwarsaPada: time.Now().Add(masa),
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat token reset"})
+		return
+	}
+
+	tautan := h.Config.FrontendURL + "/reset-password?token=" + url.QueryEscape(token)
+	pesan := mailer.Pesan{
+		Kepada: user.Email,
+		Subjek: "Reset password Forum Asisten",
+		Isi: fmt.Sprintf("Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda. "+
+			"Buka tautan berikut untuk membuat password baru:\n\n%s\n\n"+
+			"Tautan berlaku %d menit dan hanya dapat dipakai sekali. "+
+			"Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.Nama, tautan, int(masa.Minutes())),
+	}
+	if err := h.Mailer.Kirim(c.Request.Context(), pesan); err != nil {
+		log.Println("Gagal mengirim email reset password:", err)
+	}
+
+	c.JSON(http.StatusOK, respon)
+}
+
+// POST /password/reset
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var input struct {
+		Token        string `json:"token" binding:"required"`
+		PasswordBaru string `json:"password_baru" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token dan password baru wajib diisi"})
+		return
+	}
+
+	var reset models.ResetPassword
+	if err := h.DB.Preload("User").Where("hash = ?", utils.HashToken(input.Token)).First(&reset).Error; err != nil ||
+		reset.DipakaiPada != nil || time.Now().After(reset.KedaluwarsaPada) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if err := validasiPasswordUser(input.PasswordBaru, reset.User); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Tandai terpakai secara atomik agar token tidak bisa dipakai dua kali
+		res := tx.Model(&models.ResetPassword{}).
+			Where("id = ? AND dipakai_pada IS NULL", reset.ID).
+			Update("dipakai_pada", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errTokenResetTidakValid
+		}
+		return h.gantiPassword(tx, reset.UserID, input.PasswordBaru)
+	})
+	if errors.Is(err, errTokenResetTidakValid) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token reset tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengatur ulang password"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil diatur ulang, silakan login"})
+}
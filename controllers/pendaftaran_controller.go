@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"forum_asisten/models"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// kodeAkunTidakAktif membedakan akun yang masih menunggu aktivasi, yang
+// pendaftarannya ditolak, dan yang dinonaktifkan admin.
+func kodeAkunTidakAktif(user models.User) string {
+	switch {
+	case user.Status == "ditolak":
+		return "pendaftaran_ditolak"
+	case user.DiverifikasiPada == nil:
+		return "menunggu_aktivasi"
+	default:
+		return "akun_nonaktif"
+	}
+}
+
+func responAkunTidakAktif(c *gin.Context, user models.User) {
+	switch kode := kodeAkunTidakAktif(user); kode {
+	case "pendaftaran_ditolak":
+		alasan := ""
+		if user.AlasanPenolakan != nil {
+			alasan = *user.AlasanPenolakan
+		}
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":  "Pendaftaran akun ditolak",
+			"kode":   kode,
+			"alasan": alasan,
+		})
+	case "menunggu_aktivasi":
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Akun menunggu aktivasi admin",
+			"kode":  kode,
+		})
+	default:
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Akun dinonaktifkan, hubungi admin",
+			"kode":  kode,
+		})
+	}
+}
+
+// GET /admin/pendaftaran
+// Antrian pendaftaran yang belum diperiksa admin, terlama lebih dulu.
+func (h *Handler) GetPendaftaranMenunggu(c *gin.Context) {
+	var users []models.User
+	if err := h.DB.Where("status = ? AND diverifikasi_pada IS NULL", "non-aktif").
+		Order("created_at, id").
+		Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pendaftaran"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// PUT /admin/pendaftaran/:id/setujui
+func (h *Handler) SetujuiPendaftaran(c *gin.Context) {
+	h.putuskanPendaftaran(c, "aktif", nil)
+}
+
+// PUT /admin/pendaftaran/:id/tolak
+func (h *Handler) TolakPendaftaran(c *gin.Context) {
+	var input struct {
+		Alasan string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alasan penolakan wajib diisi"})
+		return
+	}
+	h.putuskanPendaftaran(c, "ditolak", &input.Alasan)
+}
+
+func (h *Handler) putuskanPendaftaran(c *gin.Context, status string, alasan *string) {
+	adminID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if user.Status != "non-aktif" || user.DiverifikasiPada != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Pendaftaran ini sudah diperiksa"})
+		return
+	}
+
+	sebelum := user
+	now := time.Now()
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"status":            status,
+			"diverifikasi_pada": &now,
+			"diverifikasi_oleh": adminID,
+			"alasan_penolakan":  alasan,
+		}).Error; err != nil {
+			return err
+		}
+		if err := cabutSemuaToken(tx, user.ID); err != nil {
+			return err
+		}
+		if err := tx.First(&user, user.ID).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "pendaftaran_"+status, sebelum, user)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui pendaftaran"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pendaftaran " + status, "data": user})
+}
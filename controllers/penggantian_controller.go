@@ -0,0 +1,415 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errAsalSudahHadir           = errors.New("asisten asal sudah mengisi presensi hadir")
+	errStatusPenggantianBerubah = errors.New("status penggantian sudah berubah")
+)
+
+// simpanStatusPenggantian menyimpan keputusan hanya jika status di database
+// masih salah satu statusAsal, agar dua keputusan bersamaan tidak saling
+// menimpa.
+func simpanStatusPenggantian(tx *gorm.DB, p *models.Penggantian, statusAsal ...string) error {
+	res := tx.Model(&models.Penggantian{}).
+		Where("id = ? AND status IN ?", p.ID, statusAsal).
+		Updates(map[string]interface{}{
+			"status":          p.Status,
+			"catatan_admin":   p.CatatanAdmin,
+			"diterima_pada":   p.DiterimaPada,
+			"diputuskan_pada": p.DiputuskanPada,
+			"diputuskan_oleh": p.DiputuskanOleh,
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return errStatusPenggantianBerubah
+	}
+	return nil
+}
+
+// penggantianDisetujui mengecek apakah asisten punya penggantian yang sudah
+// disetujui admin untuk pertemuan tertentu.
+func penggantianDisetujui(db *gorm.DB, pertemuanID, asistenID uint) bool {
+	var jumlah int64
+	db.Model(&models.Penggantian{}).
+		Where("pertemuan_id = ? AND asisten_pengganti_id = ? AND status = ?", pertemuanID, asistenID, "disetujui").
+		Count(&jumlah)
+	return jumlah > 0
+}
+
+func preloadPenggantian(db *gorm.DB) *gorm.DB {
+	return db.Preload("Pertemuan.Jadwal.MataKuliah").Preload("AsistenAsal", termasukTerhapus).Preload("AsistenPengganti", termasukTerhapus)
+}
+
+// POST /penggantian
+func (h *Handler) AjukanPenggantian(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		PertemuanID        uint   `json:"pertemuan_id" binding:"required"`
+		AsistenPenggantiID uint   `json:"asisten_pengganti_id" binding:"required"`
+		Alasan             string `json:"alasan" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
+		return
+	}
+
+	if input.AsistenPenggantiID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tidak dapat menunjuk diri sendiri sebagai pengganti"})
+		return
+	}
+
+	var pertemuan models.Pertemuan
+	if err := h.DB.First(&pertemuan, input.PertemuanID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+	if periodeTerkunci(h.DB, pertemuan.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+		return
+	}
+
+	// Hanya asisten yang ter-plot pada jadwal yang dapat meminta pengganti
+	if err := cekPenugasanPresensi(h.DB, pertemuan, userID, "utama"); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	var pengganti models.User
+	if err := h.DB.First(&pengganti, input.AsistenPenggantiID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Asisten pengganti tidak ditemukan"})
+		return
+	}
+	if pengganti.Role != "asisten" || pengganti.Status != "aktif" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pengganti harus asisten yang aktif"})
+		return
+	}
+
+	var jumlah int64
+	h.DB.Model(&models.AsistenKelas{}).
+		Where("jadwal_id = ? AND asisten_id = ?", pertemuan.JadwalID, pengganti.ID).
+		Count(&jumlah)
+	if jumlah > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pengganti sudah terdaftar sebagai asisten pada jadwal ini"})
+		return
+	}
+
+	h.DB.Model(&models.Penggantian{}).
+		Where("pertemuan_id = ? AND asisten_asal_id = ? AND status IN ?", pertemuan.ID, userID, []string{"diajukan", "diterima", "disetujui"}).
+		Count(&jumlah)
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sudah ada permintaan penggantian untuk pertemuan ini"})
+		return
+	}
+
+	penggantian := models.Penggantian{
+		PertemuanID:        pertemuan.ID,
+		AsistenAsalID:      userID,
+		AsistenPenggantiID: pengganti.ID,
+		Alasan:             input.Alasan,
+		Status:             "diajukan",
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&penggantian).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "penggantian", penggantian.ID, "buat", nil, penggantian)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan permintaan penggantian"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Permintaan penggantian berhasil diajukan", "data": penggantian})
+}
+
+// GET /penggantian
+// Menampilkan penggantian milik user, baik sebagai asisten asal maupun pengganti.
+func (h *Handler) GetPenggantianSaya(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	query := preloadPenggantian(h.DB).
+		Where("asisten_asal_id = ? OR asisten_pengganti_id = ?", userID, userID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var list []models.Penggantian
+	if err := query.Order("created_at DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data penggantian"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// GET /admin/penggantian
+func (h *Handler) GetAllPenggantian(c *gin.Context) {
+	query := preloadPenggantian(h.DB)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var list []models.Penggantian
+	if err := query.Order("created_at DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data penggantian"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// PUT /penggantian/:id/terima
+func (h *Handler) TerimaPenggantian(c *gin.Context) {
+	h.responPengganti(c, "diterima")
+}
+
+// PUT /penggantian/:id/tolak
+func (h *Handler) TolakPenggantian(c *gin.Context) {
+	h.responPengganti(c, "ditolak")
+}
+
+// responPengganti dipakai asisten pengganti untuk menerima atau menolak penunjukan.
+func (h *Handler) responPengganti(c *gin.Context, status string) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var penggantian models.Penggantian
+	if err := h.DB.First(&penggantian, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penggantian tidak ditemukan"})
+		return
+	}
+	if penggantian.AsistenPenggantiID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya asisten pengganti yang dapat merespons permintaan ini"})
+		return
+	}
+	if penggantian.Status != "diajukan" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Permintaan penggantian sudah direspons"})
+		return
+	}
+
+	sebelum := penggantian
+	now := time.Now()
+	penggantian.Status = status
+	if status == "diterima" {
+		penggantian.DiterimaPada = &now
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := simpanStatusPenggantian(tx, &penggantian, "diajukan"); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "penggantian", penggantian.ID, status, sebelum, penggantian)
+	})
+	if errors.Is(err, errStatusPenggantianBerubah) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Permintaan penggantian sudah direspons"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui penggantian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Penggantian berhasil " + status, "data": penggantian})
+}
+
+// PUT /penggantian/:id/batal
+func (h *Handler) BatalkanPenggantian(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var penggantian models.Penggantian
+	if err := h.DB.First(&penggantian, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penggantian tidak ditemukan"})
+		return
+	}
+	if penggantian.AsistenAsalID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya asisten asal yang dapat membatalkan permintaan ini"})
+		return
+	}
+	if penggantian.Status != "diajukan" && penggantian.Status != "diterima" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian tidak dapat dibatalkan lagi"})
+		return
+	}
+
+	sebelum := penggantian
+	penggantian.Status = "dibatalkan"
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := simpanStatusPenggantian(tx, &penggantian, "diajukan", "diterima"); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "penggantian", penggantian.ID, "batal", sebelum, penggantian)
+	})
+	if errors.Is(err, errStatusPenggantianBerubah) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian tidak dapat dibatalkan lagi"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membatalkan penggantian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Penggantian dibatalkan", "data": penggantian})
+}
+
+// PUT /admin/penggantian/:id/setujui
+// Setelah disetujui, sesi asisten asal otomatis tercatat izin dan pengganti
+// dapat mengisi presensi berjenis pengganti.
+func (h *Handler) SetujuiPenggantian(c *gin.Context) {
+	adminID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Catatan string `json:"catatan"`
+	}
+	_ = c.ShouldBindJSON(&input)
+
+	var penggantian models.Penggantian
+	if err := h.DB.Preload("AsistenPengganti").First(&penggantian, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penggantian tidak ditemukan"})
+		return
+	}
+	if penggantian.Status != "diterima" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian harus sudah diterima oleh asisten pengganti"})
+		return
+	}
+
+	var pertemuan models.Pertemuan
+	if err := h.DB.First(&pertemuan, penggantian.PertemuanID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+	if periodeTerkunci(h.DB, pertemuan.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+		return
+	}
+
+	sebelum := penggantian
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		penggantian.Status = "disetujui"
+		penggantian.CatatanAdmin = input.Catatan
+		penggantian.DiputuskanPada = &now
+		penggantian.DiputuskanOleh = &adminID
+		if err := simpanStatusPenggantian(tx, &penggantian, "diterima"); err != nil {
+			return err
+		}
+
+		// Sesi asisten asal menjadi izin
+		keterangan := "Digantikan oleh " + penggantian.AsistenPengganti.Nama
+		var presensi models.Presensi
+		err := tx.Where("pertemuan_id = ? AND asisten_id = ?", pertemuan.ID, penggantian.AsistenAsalID).First(&presensi).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			presensi = models.Presensi{
+				JadwalID:    pertemuan.JadwalID,
+				AsistenID:   penggantian.AsistenAsalID,
+				PeriodeID:   pertemuan.PeriodeID,
+				PertemuanID: &pertemuan.ID,
+				Jenis:       "utama",
+				Status:      "izin",
+				Keterangan:  keterangan,
+				WaktuInput:  now,
+			}
+			if err := buangPresensiTerhapus(tx, pertemuan.ID, penggantian.AsistenAsalID); err != nil {
+				return err
+			}
+			if err := tx.Create(&presensi).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case presensi.Status == "hadir":
+			return errAsalSudahHadir
+		default:
+			presensi.Status = "izin"
+			presensi.Keterangan = keterangan
+			if err := tx.Save(&presensi).Error; err != nil {
+				return err
+			}
+		}
+
+		if _, err := rekapitulasi.Perbarui(tx, h.Config.Honor, penggantian.AsistenAsalID, pertemuan.PeriodeID); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "penggantian", penggantian.ID, "setujui", sebelum, penggantian)
+	})
+	if errors.Is(err, errAsalSudahHadir) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Asisten asal sudah mengisi presensi hadir pada pertemuan ini"})
+		return
+	}
+	if errors.Is(err, errStatusPenggantianBerubah) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian sudah tidak berstatus diterima"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyetujui penggantian"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Penggantian disetujui", "data": penggantian})
+}
+
+// PUT /admin/penggantian/:id/tolak
+func (h *Handler) AdminTolakPenggantian(c *gin.Context) {
+	adminID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Catatan string `json:"catatan"`
+	}
+	_ = c.ShouldBindJSON(&input)
+
+	var penggantian models.Penggantian
+	if err := h.DB.First(&penggantian, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penggantian tidak ditemukan"})
+		return
+	}
+	if penggantian.Status != "diajukan" && penggantian.Status != "diterima" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian sudah diputuskan"})
+		return
+	}
+
+	sebelum := penggantian
+	now := time.Now()
+	penggantian.Status = "ditolak"
+	penggantian.CatatanAdmin = input.Catatan
+	penggantian.DiputuskanPada = &now
+	penggantian.DiputuskanOleh = &adminID
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := simpanStatusPenggantian(tx, &penggantian, "diajukan", "diterima"); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "penggantian", penggantian.ID, "tolak", sebelum, penggantian)
+	})
+	if errors.Is(err, errStatusPenggantianBerubah) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penggantian sudah diputuskan"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menolak penggantian"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Penggantian ditolak", "data": penggantian})
+}
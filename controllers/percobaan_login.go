@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"forum_asisten/config"
+	"forum_asisten/models"
+	"forum_asisten/utils"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func kunciIdentifier(identifier string) string {
+	return "id:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func kunciIP(ip string) string {
+	return "ip:" + ip
+}
+
+// jedaLogin menghitung sisa waktu tunggu sebuah kunci: sampai penguncian
+// berakhir, atau jeda eksponensial sejak kegagalan terakhir.
+func jedaLogin(p models.PercobaanLogin, batas config.BatasLogin, now time.Time) time.Duration {
+	if p.TerkunciSampai != nil && now.Before(*p.TerkunciSampai) {
+		return p.TerkunciSampai.Sub(now)
+	}
+	if p.Gagal == 0 || batas.JedaAwal <= 0 {
+		return 0
+	}
+
+	jeda := batas.JedaAwal
+	for i := 1; i < p.Gagal && jeda < batas.KunciSelama; i++ {
+		jeda *= 2
+	}
+	if jeda > batas.KunciSelama {
+		jeda = batas.KunciSelama
+	}
+	if sisa := p.TerakhirGagal.Add(jeda).Sub(now); sisa > 0 {
+		return sisa
+	}
+	return 0
+}
+
+// cekBatasLogin mengembalikan waktu tunggu terlama dari semua kunci.
+func (h *Handler) cekBatasLogin(kunci ...string) time.Duration {
+	batas := h.Config.BatasLogin
+	now := time.Now()
+
+	var list []models.PercobaanLogin
+	h.DB.Where("kunci IN ?", kunci).Find(&list)
+
+	var tunggu time.Duration
+	for _, p := range list {
+		if j := jedaLogin(p, batas, now); j > tunggu {
+			tunggu = j
+		}
+	}
+	return tunggu
+}
+
+// catatLoginGagal menambah hitungan gagal tiap kunci, mengunci kunci yang
+// melewati batas, dan menulis jejak audit. Hitungan dinaikkan langsung di
+// database agar percobaan yang bersamaan tidak saling menimpa.
+func (h *Handler) catatLoginGagal(c *gin.Context, identifier string, userID *uint, alasan string) {
+	batas := h.Config.BatasLogin
+	now := time.Now()
+	// Hitungan dimulai ulang jika kegagalan terakhir sudah lama
+	kedaluwarsa := now.Add(-batas.KunciSelama)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		for kunci, maks := range map[string]int{
+			kunciIdentifier(identifier): batas.MaksGagal,
+			kunciIP(c.ClientIP()):       batas.MaksGagalIP,
+		} {
+			err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+				Create(&models.PercobaanLogin{Kunci: kunci}).Error
+			if err != nil {
+				return err
+			}
+			// terakhir_gagal diisi paling akhir: MySQL memakai nilai baru
+			// kolom yang sudah diisi sebelumnya dalam SET yang sama.
+			err = tx.Exec(`UPDATE percobaan_login SET
+				gagal = CASE WHEN terakhir_gagal < ? THEN 1 ELSE gagal + 1 END,
+				terkunci_sampai = CASE WHEN terakhir_gagal < ? THEN NULL ELSE terkunci_sampai END,
+				terakhir_gagal = ?
+				WHERE kunci = ?`, kedaluwarsa, kedaluwarsa, now, kunci).Error
+			if err != nil {
+				return err
+			}
+			if maks > 0 {
+				err = tx.Model(&models.PercobaanLogin{}).
+					Where("kunci = ? AND gagal >= ?", kunci, maks).
+					Update("terkunci_sampai", now.Add(batas.KunciSelama)).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return catatJejakLogin(c, tx, identifier, userID, alasan)
+	})
+	if err != nil {
+		log.Println("Gagal mencatat percobaan login:", err)
+	}
+}
+
+// catatLoginDiblokir hanya menulis jejak audit untuk percobaan yang ditolak
+// karena penguncian; hitungan gagal tidak dinaikkan agar penguncian tidak
+// terus diperpanjang selama percobaan berlanjut.
+func (h *Handler) catatLoginDiblokir(c *gin.Context, identifier string) {
+	if err := catatJejakLogin(c, h.DB, identifier, nil, "diblokir"); err != nil {
+		log.Println("Gagal mencatat percobaan login:", err)
+	}
+}
+
+func catatJejakLogin(c *gin.Context, tx *gorm.DB, identifier string, userID *uint, alasan string) error {
+	return tx.Create(&models.LoginGagal{
+		Identifier: identifier,
+		UserID:     userID,
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		Alasan:     alasan,
+	}).Error
+}
+
+var (
+	hashPengecohMu sync.Mutex
+	hashPengecoh   = map[int]string{} // biaya bcrypt -> hash
+)
+
+// bandingkanPasswordPengecoh menjalankan bcrypt terhadap hash acak untuk
+// identifier yang tidak terdaftar, agar waktu respons tidak membedakannya
+// dari akun yang ada.
+func (h *Handler) bandingkanPasswordPengecoh(password string) {
+	biaya := h.Config.BcryptCost
+	hashPengecohMu.Lock()
+	hash, ada := hashPengecoh[biaya]
+	if !ada {
+		acak, err := utils.TokenAcak()
+		if err == nil {
+			hash, err = utils.HashPassword(acak, biaya)
+		}
+		if err != nil {
+			hashPengecohMu.Unlock()
+			log.Println("Gagal membuat hash pengecoh:", err)
+			return
+		}
+		hashPengecoh[biaya] = hash
+	}
+	hashPengecohMu.Unlock()
+	utils.CheckPasswordHash(password, hash)
+}
+
+// responLoginDiblokir mengirim 429 dengan Retry-After.
+func responLoginDiblokir(c *gin.Context, tunggu time.Duration) {
+	detik := int(tunggu.Seconds() + 0.999)
+	c.Header("Retry-After", strconv.Itoa(detik))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "Terlalu banyak percobaan login, coba lagi nanti",
+		"retry_after": detik,
+	})
+}
+
+// PUT /admin/users/:id/unlock
+// Menghapus penguncian login berdasarkan email dan NIM user, beserta
+// penguncian IP asal kegagalan login user tersebut dalam masa kunci. Tanpa
+// itu user tetap tertahan kunci per IP setelah dibuka.
+func (h *Handler) UnlockUser(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	identifier := []string{strings.ToLower(user.Email)}
+	if user.NIM != nil {
+		identifier = append(identifier, strings.ToLower(*user.NIM))
+	}
+	kunci := make([]string, 0, len(identifier))
+	for _, nilai := range identifier {
+		kunci = append(kunci, kunciIdentifier(nilai))
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var ip []string
+		err := tx.Model(&models.LoginGagal{}).
+			Where("(user_id = ? OR LOWER(identifier) IN ?) AND waktu >= ?",
+				user.ID, identifier, time.Now().Add(-h.Config.BatasLogin.KunciSelama)).
+			Distinct().Pluck("ip", &ip).Error
+		if err != nil {
+			return err
+		}
+		for _, alamat := range ip {
+			kunci = append(kunci, kunciIP(alamat))
+		}
+
+		if err := tx.Where("kunci IN ?", kunci).Delete(&models.PercobaanLogin{}).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "buka_kunci_login", nil, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuka kunci akun"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Kunci login akun berhasil dibuka"})
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var tahunAjaranRegex = regexp.MustCompile(`^\d{4}/\d{4}$`)
+
+var errPeriodeDitutup = errors.New("periode sudah ditutup")
+
+type PeriodeInput struct {
+	TahunAjaran    string `json:"tahun_ajaran" binding:"required"`
+	Semester       string `json:"semester" binding:"required,oneof=ganjil genap"`
+	TanggalMulai   string `json:"tanggal_mulai" binding:"required"`   // format: "2006-01-02"
+	TanggalSelesai string `json:"tanggal_selesai" binding:"required"` // format: "2006-01-02"
+}
+
+// periodeAktif mengambil periode akademik yang sedang aktif.
+func periodeAktif(db *gorm.DB) (models.Periode, error) {
+	var periode models.Periode
+	err := db.Where("aktif = ?", true).First(&periode).Error
+	return periode, err
+}
+
+// periodeDariQuery menentukan periode yang dipakai untuk filter data:
+// query param periode_id jika ada, selain itu periode aktif.
+// Jika gagal, response error sudah dikirim dan ok bernilai false.
+func (h *Handler) periodeDariQuery(c *gin.Context) (models.Periode, bool) {
+	var periode models.Periode
+
+	if periodeID := c.Query("periode_id"); periodeID != "" {
+		if err := h.DB.First(&periode, periodeID).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+			return periode, false
+		}
+		return periode, true
+	}
+
+	periode, err := periodeAktif(h.DB)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Belum ada periode akademik yang aktif"})
+		return periode, false
+	}
+	return periode, true
+}
+
+// periodeTerkunci mengembalikan true jika periode sudah ditutup sehingga
+// jadwal, presensi, dan rekapitulasinya tidak boleh diubah lagi.
+func periodeTerkunci(db *gorm.DB, periodeID uint) bool {
+	var periode models.Periode
+	if err := db.Select("ditutup").First(&periode, periodeID).Error; err != nil {
+		return false
+	}
+	return periode.Ditutup
+}
+
+func (input PeriodeInput) toModel() (models.Periode, error) {
+	if !tahunAjaranRegex.MatchString(input.TahunAjaran) {
+		return models.Periode{}, errors.New("Format tahun_ajaran harus YYYY/YYYY")
+	}
+	mulai, err := time.Parse("2006-01-02", input.TanggalMulai)
+	if err != nil {
+		return models.Periode{}, errors.New("Format tanggal_mulai harus YYYY-MM-DD")
+	}
+	selesai, err := time.Parse("2006-01-02", input.TanggalSelesai)
+	if err != nil {
+		return models.Periode{}, errors.New("Format tanggal_selesai harus YYYY-MM-DD")
+	}
+	if !selesai.After(mulai) {
+		return models.Periode{}, errors.New("tanggal_selesai harus setelah tanggal_mulai")
+	}
+
+	semester := strings.ToLower(input.Semester)
+	return models.Periode{
+		Nama:           input.TahunAjaran + " " + strings.ToUpper(semester[:1]) + semester[1:],
+		TahunAjaran:    input.TahunAjaran,
+		Semester:       semester,
+		TanggalMulai:   mulai,
+		TanggalSelesai: selesai,
+	}, nil
+}
+
+func (h *Handler) CreatePeriode(c *gin.Context) {
+	var input PeriodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
+		return
+	}
+
+	periode, err := input.toModel()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&periode).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "periode", periode.ID, "buat", nil, periode)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan periode, pastikan periode belum ada"})
+		return
+	}
+	c.JSON(http.StatusCreated, periode)
+}
+
+func (h *Handler) GetAllPeriode(c *gin.Context) {
+	var list []models.Periode
+	if err := h.DB.Order("tanggal_mulai DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data periode"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) GetPeriodeAktif(c *gin.Context) {
+	periode, err := periodeAktif(h.DB)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Belum ada periode akademik yang aktif"})
+		return
+	}
+	c.JSON(http.StatusOK, periode)
+}
+
+func (h *Handler) UpdatePeriode(c *gin.Context) {
+	id := c.Param("id")
+	var periode models.Periode
+	if err := h.DB.First(&periode, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	if periode.Ditutup {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup dan tidak dapat diubah"})
+		return
+	}
+
+	var input PeriodeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
+		return
+	}
+
+	updated, err := input.toModel()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sebelum := periode
+	periode.Nama = updated.Nama
+	periode.TahunAjaran = updated.TahunAjaran
+	periode.Semester = updated.Semester
+	periode.TanggalMulai = updated.TanggalMulai
+	periode.TanggalSelesai = updated.TanggalSelesai
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&periode).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "periode", periode.ID, "ubah", sebelum, periode)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui periode"})
+		return
+	}
+	c.JSON(http.StatusOK, periode)
+}
+
+// PUT /admin/periode/:id/aktifkan
+func (h *Handler) AktifkanPeriode(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID periode tidak valid"})
+		return
+	}
+
+	var periode models.Periode
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&periode, id).Error; err != nil {
+			return err
+		}
+		if periode.Ditutup {
+			return errPeriodeDitutup
+		}
+		// Hanya boleh ada satu periode aktif
+		if err := tx.Model(&models.Periode{}).Where("aktif = ?", true).Update("aktif", false).Error; err != nil {
+			return err
+		}
+		sebelum := periode
+		periode.Aktif = true
+		if err := tx.Model(&periode).Update("aktif", true).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "periode", periode.ID, "aktifkan", sebelum, periode)
+	})
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+	case errors.Is(err, errPeriodeDitutup):
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode yang sudah ditutup tidak dapat diaktifkan"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengaktifkan periode"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Periode berhasil diaktifkan", "data": periode})
+	}
+}
+
+// PUT /admin/periode/:id/tutup
+// Menutup periode membekukan rekapitulasinya: presensi, jadwal, dan
+// rekapitulasi pada periode ini tidak bisa diubah lagi.
+func (h *Handler) TutupPeriode(c *gin.Context) {
+	id := c.Param("id")
+	var periode models.Periode
+	if err := h.DB.First(&periode, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	if periode.Ditutup {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
+
+	sebelum := periode
+	now := time.Now()
+	periode.Ditutup = true
+	periode.Aktif = false
+	periode.DitutupPada = &now
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&periode).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "periode", periode.ID, "tutup", sebelum, periode)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menutup periode"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Periode berhasil ditutup", "data": periode})
+}
+
+func (h *Handler) DeletePeriode(c *gin.Context) {
+	id := c.Param("id")
+
+	// Jadwal di tempat sampah ikut dihitung karena masih merujuk periode
+	var jumlahJadwal int64
+	h.DB.Unscoped().Model(&models.Jadwal{}).Where("periode_id = ?", id).Count(&jumlahJadwal)
+	if jumlahJadwal > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode masih memiliki jadwal"})
+		return
+	}
+	if h.masihDipakai(&models.Rekapitulasi{}, "periode_id", id) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode masih memiliki rekapitulasi"})
+		return
+	}
+
+	var periode models.Periode
+	if err := h.DB.First(&periode, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&periode).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "periode", periode.ID, "hapus", periode, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus periode"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Periode berhasil dihapus"})
+}
@@ -0,0 +1,295 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const formatTanggal = "2006-01-02"
+
+// susunPertemuan menyelaraskan daftar pertemuan sebuah jadwal dengan
+// kalender periodenya: setiap minggu pada hari jadwal, kecuali hari libur.
+// Pertemuan yang sudah memiliki presensi atau penggantian tidak pernah
+// dihapus, dinomori ulang, maupun diubah jamnya: keterlambatan dan rekap
+// yang sudah tercatat mengacu ke data lamanya.
+func susunPertemuan(tx *gorm.DB, jadwal models.Jadwal) ([]models.Pertemuan, error) {
+	var periode models.Periode
+	if err := tx.First(&periode, jadwal.PeriodeID).Error; err != nil {
+		return nil, errors.New("periode jadwal tidak ditemukan")
+	}
+	if periode.Ditutup {
+		return nil, errPeriodeDitutup
+	}
+
+	hari, err := utils.ParseHari(jadwal.Hari)
+	if err != nil {
+		return nil, err
+	}
+
+	var libur []models.HariLibur
+	if err := tx.Where("tanggal BETWEEN ? AND ?", periode.TanggalMulai, periode.TanggalSelesai).Find(&libur).Error; err != nil {
+		return nil, err
+	}
+	tanggalLibur := make(map[string]bool, len(libur))
+	for _, l := range libur {
+		tanggalLibur[l.Tanggal.Format(formatTanggal)] = true
+	}
+
+	tanggalSesi := make(map[string]time.Time)
+	for _, t := range utils.TanggalMingguan(periode.TanggalMulai, periode.TanggalSelesai, hari) {
+		if !tanggalLibur[t.Format(formatTanggal)] {
+			tanggalSesi[t.Format(formatTanggal)] = t
+		}
+	}
+
+	var existing []models.Pertemuan
+	if err := tx.Where("jadwal_id = ?", jadwal.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	terpakai := make(map[uint]bool)
+	for _, p := range existing {
+		dipakai, err := pertemuanTerpakai(tx, p.ID)
+		if err != nil {
+			return nil, err
+		}
+		terpakai[p.ID] = dipakai
+
+		key := p.Tanggal.Format(formatTanggal)
+		if _, ok := tanggalSesi[key]; ok {
+			delete(tanggalSesi, key)
+			continue
+		}
+		if dipakai {
+			continue
+		}
+		if err := tx.Delete(&p).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, t := range tanggalSesi {
+		p := models.Pertemuan{
+			JadwalID:  jadwal.ID,
+			PeriodeID: jadwal.PeriodeID,
+			Tanggal:   t,
+		}
+		if err := tx.Create(&p).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Nomori ulang pertemuan berdasarkan urutan tanggal
+	var hasil []models.Pertemuan
+	if err := tx.Where("jadwal_id = ?", jadwal.ID).Order("tanggal").Find(&hasil).Error; err != nil {
+		return nil, err
+	}
+	for i := range hasil {
+		if terpakai[hasil[i].ID] {
+			continue
+		}
+		hasil[i].Ke = i + 1
+		hasil[i].PeriodeID = jadwal.PeriodeID
+		hasil[i].JamMulai = jadwal.JamMulai
+		hasil[i].JamSelesai = jadwal.JamSelesai
+		if err := tx.Save(&hasil[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+	return hasil, nil
+}
+
+// pertemuanTerpakai mengecek apakah pertemuan sudah memiliki presensi atau
+// penggantian. Presensi di tempat sampah ikut dihitung agar masih bisa
+// dipulihkan.
+func pertemuanTerpakai(tx *gorm.DB, pertemuanID uint) (bool, error) {
+	var jumlahPresensi int64
+	if err := tx.Unscoped().Model(&models.Presensi{}).Where("pertemuan_id = ?", pertemuanID).Count(&jumlahPresensi).Error; err != nil {
+		return false, err
+	}
+	var jumlahPenggantian int64
+	if err := tx.Model(&models.Penggantian{}).Where("pertemuan_id = ?", pertemuanID).Count(&jumlahPenggantian).Error; err != nil {
+		return false, err
+	}
+	return jumlahPresensi > 0 || jumlahPenggantian > 0, nil
+}
+
+// susunPertemuanPeriode menyusun ulang pertemuan seluruh jadwal dalam satu periode.
+func susunPertemuanPeriode(tx *gorm.DB, periodeID uint) (int, error) {
+	var jadwalList []models.Jadwal
+	if err := tx.Where("periode_id = ?", periodeID).Find(&jadwalList).Error; err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, jadwal := range jadwalList {
+		hasil, err := susunPertemuan(tx, jadwal)
+		if err != nil {
+			return 0, err
+		}
+		total += len(hasil)
+	}
+	return total, nil
+}
+
+// POST /admin/jadwal/:id/pertemuan
+func (h *Handler) GeneratePertemuanJadwal(c *gin.Context) {
+	var jadwal models.Jadwal
+	if err := h.DB.First(&jadwal, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jadwal tidak ditemukan"})
+		return
+	}
+
+	var hasil []models.Pertemuan
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		hasil, err = susunPertemuan(tx, jadwal)
+		return err
+	})
+	if errors.Is(err, errPeriodeDitutup) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal menyusun pertemuan", "detail": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pertemuan berhasil disusun", "data": hasil})
+}
+
+// POST /admin/periode/:id/pertemuan
+func (h *Handler) GeneratePertemuanPeriode(c *gin.Context) {
+	var periode models.Periode
+	if err := h.DB.First(&periode, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Periode tidak ditemukan"})
+		return
+	}
+
+	var total int
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		total, err = susunPertemuanPeriode(tx, periode.ID)
+		return err
+	})
+	if errors.Is(err, errPeriodeDitutup) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode sudah ditutup"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal menyusun pertemuan", "detail": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pertemuan berhasil disusun", "jumlah_pertemuan": total})
+}
+
+// GET /pertemuan?jadwal_id=&periode_id=
+func (h *Handler) GetPertemuan(c *gin.Context) {
+	periode, ok := h.periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
+	query := h.DB.Preload("Jadwal.MataKuliah").Where("periode_id = ?", periode.ID)
+	if jadwalID := c.Query("jadwal_id"); jadwalID != "" {
+		query = query.Where("jadwal_id = ?", jadwalID)
+	}
+
+	var list []models.Pertemuan
+	if err := query.Order("tanggal, jadwal_id").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data pertemuan"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) GetAllHariLibur(c *gin.Context) {
+	var list []models.HariLibur
+	if err := h.DB.Order("tanggal").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data hari libur"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// POST /admin/hari-libur
+// Pertemuan pada periode yang belum ditutup langsung disusun ulang agar
+// tanggal libur tidak lagi dijadwalkan.
+func (h *Handler) CreateHariLibur(c *gin.Context) {
+	var input struct {
+		Tanggal    string `json:"tanggal" binding:"required"` // format: "2006-01-02"
+		Keterangan string `json:"keterangan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	tanggal, err := time.Parse(formatTanggal, input.Tanggal)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format tanggal harus YYYY-MM-DD"})
+		return
+	}
+
+	libur := models.HariLibur{Tanggal: tanggal, Keterangan: input.Keterangan}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&libur).Error; err != nil {
+			return err
+		}
+		if err := susunUlangPeriodeTerdampak(tx, tanggal); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "hari_libur", libur.ID, "buat", nil, libur)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan hari libur", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, libur)
+}
+
+func (h *Handler) DeleteHariLibur(c *gin.Context) {
+	var libur models.HariLibur
+	if err := h.DB.First(&libur, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hari libur tidak ditemukan"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&libur).Error; err != nil {
+			return err
+		}
+		if err := susunUlangPeriodeTerdampak(tx, libur.Tanggal); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "hari_libur", libur.ID, "hapus", libur, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus hari libur", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Hari libur berhasil dihapus"})
+}
+
+// susunUlangPeriodeTerdampak menyusun ulang pertemuan pada periode terbuka
+// yang rentang tanggalnya mencakup tanggal tertentu.
+func susunUlangPeriodeTerdampak(tx *gorm.DB, tanggal time.Time) error {
+	var periodeList []models.Periode
+	if err := tx.Where("ditutup = ? AND tanggal_mulai <= ? AND tanggal_selesai >= ?", false, tanggal, tanggal).
+		Find(&periodeList).Error; err != nil {
+		return err
+	}
+	for _, periode := range periodeList {
+		if _, err := susunPertemuanPeriode(tx, periode.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
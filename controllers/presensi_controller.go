@@ -1,14 +1,77 @@
 package controllers
 
 import (
+	"errors"
+	"forum_asisten/authz"
 	"forum_asisten/config"
 	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
+	"forum_asisten/utils"
+	"math"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-func CreatePresensi(c *gin.Context) {
+var (
+	errPresensiBelumDibuka = errors.New("Presensi untuk pertemuan ini belum dibuka")
+	errPresensiLewatBatas  = errors.New("Batas waktu pengisian presensi sudah lewat")
+)
+
+// cekPenugasanPresensi memastikan asisten ter-plot pada jadwal pertemuan
+// (presensi utama) atau ditunjuk sebagai pengganti yang sah (presensi pengganti).
+func cekPenugasanPresensi(db *gorm.DB, pertemuan models.Pertemuan, asistenID uint, jenis string) error {
+	switch jenis {
+	case "utama":
+		var jumlah int64
+		db.Model(&models.AsistenKelas{}).
+			Where("jadwal_id = ? AND asisten_id = ?", pertemuan.JadwalID, asistenID).
+			Count(&jumlah)
+		if jumlah == 0 {
+			return errors.New("Anda tidak terdaftar sebagai asisten pada jadwal ini")
+		}
+		return nil
+	case "pengganti":
+		if !penggantianDisetujui(db, pertemuan.ID, asistenID) {
+			return errors.New("Presensi pengganti memerlukan penggantian yang sudah disetujui")
+		}
+		return nil
+	default:
+		return errors.New("Jenis presensi tidak valid")
+	}
+}
+
+// cekJendelaPresensi memastikan waktu pengisian berada dalam jendela pertemuan
+// dan mengembalikan keterlambatan dalam menit sejak batas toleransi setelah
+// jam mulai (0 jika tepat waktu). Izin dan alpha boleh diisi sebelum
+// pertemuan dimulai.
+func cekJendelaPresensi(jendela config.JendelaPresensi, pertemuan models.Pertemuan, status string, waktu time.Time) (int, error) {
+	mulai, err := utils.GabungTanggalJam(pertemuan.Tanggal, pertemuan.JamMulai, jendela.Lokasi)
+	if err != nil {
+		return 0, err
+	}
+	selesai, err := utils.GabungTanggalJam(pertemuan.Tanggal, pertemuan.JamSelesai, jendela.Lokasi)
+	if err != nil {
+		return 0, err
+	}
+
+	if status == "hadir" && waktu.Before(mulai.Add(-jendela.BukaSebelumMulai)) {
+		return 0, errPresensiBelumDibuka
+	}
+	if waktu.After(selesai.Add(jendela.BatasTerlambat)) {
+		return 0, errPresensiLewatBatas
+	}
+
+	batasTepatWaktu := mulai.Add(jendela.Toleransi)
+	if waktu.After(batasTepatWaktu) {
+		return int(math.Ceil(waktu.Sub(batasTepatWaktu).Minutes())), nil
+	}
+	return 0, nil
+}
+
+func (h *Handler) CreatePresensi(c *gin.Context) {
 	// Ambil user ID dari token (context)
 	userIDVal, exists := c.Get("user_id")
 	if !exists {
@@ -23,13 +86,6 @@ func CreatePresensi(c *gin.Context) {
 	}
 	userID := uint(userIDFloat)
 
-	// Ambil role
-	role := c.GetString("role")
-	if role != "asisten" {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Hanya asisten yang dapat mengisi presensi"})
-		return
-	}
-
 	// Binding input
 	var input models.Presensi
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -37,14 +93,20 @@ func CreatePresensi(c *gin.Context) {
 		return
 	}
 
+	// Bukti hanya boleh berupa berkas yang sudah diunggah lewat /berkas,
+	// URL-nya diisi server dari berkas tersebut; keterangan hanya diisi sistem
+	input.BuktiKehadiran = ""
+	input.BuktiIzin = ""
+	input.Keterangan = ""
+
 	// Validasi kehadiran vs izin
 	if input.Status == "hadir" {
-		if input.BuktiIzin != "" {
+		if input.BuktiIzinID != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Bukti izin harus kosong jika status hadir"})
 			return
 		}
 	} else if input.Status == "izin" || input.Status == "alpha" {
-		if input.BuktiKehadiran != "" || input.IsiMateri != "" {
+		if input.BuktiKehadiranID != nil || input.IsiMateri != "" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Bukti kehadiran dan isi materi harus kosong jika tidak hadir"})
 			return
 		}
@@ -53,11 +115,90 @@ func CreatePresensi(c *gin.Context) {
 		return
 	}
 
+	buktiKehadiran, err := h.ambilBerkasMilik(input.BuktiKehadiranID, userID, "bukti_kehadiran")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if buktiKehadiran != nil {
+		input.BuktiKehadiran = buktiKehadiran.URL()
+	}
+	buktiIzin, err := h.ambilBerkasMilik(input.BuktiIzinID, userID, "bukti_izin")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if buktiIzin != nil {
+		input.BuktiIzin = buktiIzin.URL()
+	}
+
+	// Presensi selalu melekat pada satu pertemuan; jadwal dan periode
+	// mengikuti pertemuan tersebut
+	if input.PertemuanID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pertemuan_id wajib diisi"})
+		return
+	}
+	var pertemuan models.Pertemuan
+	if err := h.DB.First(&pertemuan, *input.PertemuanID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pertemuan tidak ditemukan"})
+		return
+	}
+	if input.JadwalID != 0 && input.JadwalID != pertemuan.JadwalID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pertemuan bukan milik jadwal tersebut"})
+		return
+	}
+	if periodeTerkunci(h.DB, pertemuan.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode jadwal sudah ditutup"})
+		return
+	}
+
+	if input.Jenis == "" {
+		input.Jenis = "utama"
+	}
+	if err := cekPenugasanPresensi(h.DB, pertemuan, userID, input.Jenis); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Waktu input ditentukan server, bukan dari client
+	input.WaktuInput = time.Now()
+	menitTerlambat, err := cekJendelaPresensi(h.Config.Presensi, pertemuan, input.Status, input.WaktuInput)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	input.Terlambat = menitTerlambat > 0
+	input.MenitTerlambat = menitTerlambat
+
+	// Satu asisten hanya boleh mengisi satu presensi per pertemuan
+	var jumlah int64
+	h.DB.Model(&models.Presensi{}).
+		Where("pertemuan_id = ? AND asisten_id = ?", pertemuan.ID, userID).
+		Count(&jumlah)
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Presensi untuk pertemuan ini sudah diisi"})
+		return
+	}
+
 	// Tambahkan asisten_id dari token
 	input.AsistenID = userID
+	input.JadwalID = pertemuan.JadwalID
+	input.PeriodeID = pertemuan.PeriodeID
 
-	// Simpan presensi
-	if err := config.DB.Create(&input).Error; err != nil {
+	// Simpan presensi dan susun ulang rekapitulasi dalam satu transaksi
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := buangPresensiTerhapus(tx, *input.PertemuanID, input.AsistenID); err != nil {
+			return err
+		}
+		if err := tx.Create(&input).Error; err != nil {
+			return err
+		}
+		if _, err := rekapitulasi.Perbarui(tx, h.Config.Honor, input.AsistenID, input.PeriodeID); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "presensi", input.ID, "buat", nil, input)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan presensi"})
 		return
 	}
@@ -66,44 +207,35 @@ func CreatePresensi(c *gin.Context) {
 		"message": "Presensi berhasil disimpan",
 		"data":    input,
 	})
+}
 
-	// Update rekapitulasi
-	var rekap models.Rekapitulasi
-	if err := config.DB.Where("asisten_id = ?", input.AsistenID).First(&rekap).Error; err != nil {
-		// Belum ada rekap, buat baru
-		rekap = models.Rekapitulasi{
-			AsistenID: input.AsistenID,
-		}
-	}
-
-	switch input.Status {
-	case "hadir":
-		if input.Jenis == "utama" {
-			rekap.JumlahHadir++
-		} else if input.Jenis == "pengganti" {
-			rekap.JumlahPengganti++
-		}
-	case "izin":
-		rekap.JumlahIzin++
-	case "alpha":
-		rekap.JumlahAlpha++
-	}
-
-	rekap.TotalHonor = rekap.HonorPertemuan * (rekap.JumlahHadir + rekap.JumlahPengganti)
+// buangPresensiTerhapus menghapus permanen presensi di tempat sampah untuk
+// pertemuan dan asisten yang sama. Presensi baru menggantikannya, dan indeks
+// unik pertemuan-asisten tetap berlaku untuk baris yang sudah dihapus.
+func buangPresensiTerhapus(tx *gorm.DB, pertemuanID, asistenID uint) error {
+	return tx.Unscoped().
+		Where("pertemuan_id = ? AND asisten_id = ? AND deleted_at IS NOT NULL", pertemuanID, asistenID).
+		Delete(&models.Presensi{}).Error
+}
 
-	if err := config.DB.Save(&rekap).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui rekapitulasi"})
+func (h *Handler) GetAllPresensi(c *gin.Context) {
+	periode, ok := h.periodeDariQuery(c)
+	if !ok {
 		return
 	}
 
-}
+	query := h.DB.Where("periode_id = ?", periode.ID)
+	query = h.batasiProdi(c, query, authz.KelolaPresensi, "jadwal_id")
+	if c.Query("terlambat") == "true" {
+		query = query.Where("terlambat = ?", true)
+	}
 
-func GetAllPresensi(c *gin.Context) {
 	var data []models.Presensi
-	if err := config.DB.
+	if err := query.
 		Preload("Jadwal").
+		Preload("Pertemuan").
 		Preload("Jadwal.MataKuliah").
-		Preload("Asisten").
+		Preload("Asisten", termasukTerhapus).
 		Preload("Jadwal.MataKuliah.ProgramStudi").
 		Preload("Jadwal.Dosen").
 		Find(&data).Error; err != nil {
@@ -112,212 +244,159 @@ func GetAllPresensi(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, data)
 }
-func UpdatePresensi(c *gin.Context) {
-    // [1] Ambil ID presensi dan validasi
-    presensiID := c.Param("id")
-    if presensiID == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ID presensi harus disertakan"})
-        return
-    }
-
-    // [2] Cek role admin
-    role := c.GetString("role")
-    if role != "admin" {
-        c.JSON(http.StatusForbidden, gin.H{"error": "Hanya admin yang dapat mengupdate presensi"})
-        return
-    }
-
-    // [3] Bind input
-    type UpdateInput struct {
-        Status string `json:"status" binding:"required,oneof=hadir izin alpha"`
-    }
-    
-    var input UpdateInput
-    if err := c.ShouldBindJSON(&input); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid input",
-            "details": err.Error(),
-        })
-        return
-    }
-    
-    // if err := c.ShouldBindJSON(&input); err != nil {
-    //     c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
-    //     return
-    // }
-
-    // [4] Validasi status
-    validStatus := map[string]bool{"hadir": true, "izin": true, "alpha": true}
-    if !validStatus[input.Status] {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Status tidak valid"})
-        return
-    }
-
-    // [5] Mulai transaction
-    tx := config.DB.Begin()
-    defer func() {
-        if r := recover(); r != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // [6] Ambil data presensi lama (untuk mengetahui status sebelumnya)
-    var presensi models.Presensi
-    if err := tx.Where("id = ?", presensiID).First(&presensi).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusNotFound, gin.H{"error": "Presensi tidak ditemukan"})
-        return
-    }
-
-    oldStatus := presensi.Status
-    presensi.Status = input.Status
-
-    // [7] Simpan perubahan presensi
-    if err := tx.Save(&presensi).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan presensi"})
-        return
-    }
-
-    // [8] Update rekapitulasi HANYA jika status berubah
-    if oldStatus != input.Status {
-        var rekap models.Rekapitulasi
-        if err := tx.Where("asisten_id = ?", presensi.AsistenID).First(&rekap).Error; err != nil {
-            tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Data rekapitulasi tidak ditemukan"})
-            return
-        }
-
-        // [9] Kurangi counter status lama
-        switch oldStatus {
-        case "hadir":
-            if presensi.Jenis == "utama" {
-                rekap.JumlahHadir--
-            } else {
-                rekap.JumlahPengganti--
-            }
-        case "izin":
-            rekap.JumlahIzin--
-        case "alpha":
-            rekap.JumlahAlpha--
-        }
-
-        // [10] Tambahkan counter status baru
-        switch input.Status {
-        case "hadir":
-            if presensi.Jenis == "utama" {
-                rekap.JumlahHadir++
-            } else {
-                rekap.JumlahPengganti++
-            }
-        case "izin":
-            rekap.JumlahIzin++
-        case "alpha":
-            rekap.JumlahAlpha++
-        }
-
-        // [11] Hitung ulang total honor
-        rekap.TotalHonor = rekap.HonorPertemuan * (rekap.JumlahHadir + rekap.JumlahPengganti)
-
-        if err := tx.Save(&rekap).Error; err != nil {
-            tx.Rollback()
-            c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update rekapitulasi"})
-            return
-        }
-    }
-
-    // [12] Commit transaksi jika semua berhasil
-    tx.Commit()
-
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Status presensi berhasil diperbarui",
-        "data":    presensi,
-    })
+func (h *Handler) UpdatePresensi(c *gin.Context) {
+	// [1] Ambil ID presensi dan validasi
+	presensiID := c.Param("id")
+	if presensiID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID presensi harus disertakan"})
+		return
+	}
+
+	// [2] Izin dicek middleware RequirePermission(authz.KelolaPresensi)
+
+	// [3] Bind input
+	type UpdateInput struct {
+		Status string `json:"status" binding:"required,oneof=hadir izin alpha"`
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid input",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// if err := c.ShouldBindJSON(&input); err != nil {
+	//     c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+	//     return
+	// }
+
+	// [4] Validasi status
+	validStatus := map[string]bool{"hadir": true, "izin": true, "alpha": true}
+	if !validStatus[input.Status] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status tidak valid"})
+		return
+	}
+
+	// [5] Mulai transaction
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// [6] Ambil data presensi
+	var presensi models.Presensi
+	if err := tx.Where("id = ?", presensiID).First(&presensi).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Presensi tidak ditemukan"})
+		return
+	}
+
+	if !cekProdi(c, authz.KelolaPresensi, prodiJadwal(tx, presensi.JadwalID)) {
+		tx.Rollback()
+		return
+	}
+
+	if periodeTerkunci(tx, presensi.PeriodeID) {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode presensi sudah ditutup"})
+		return
+	}
+
+	sebelum := presensi
+	presensi.Status = input.Status
+
+	// [7] Simpan perubahan presensi
+	if err := tx.Save(&presensi).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan presensi"})
+		return
+	}
+
+	// [8] Susun ulang rekapitulasi dari tabel presensi
+	if _, err := rekapitulasi.Perbarui(tx, h.Config.Honor, presensi.AsistenID, presensi.PeriodeID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal update rekapitulasi"})
+		return
+	}
+
+	if err := catatAudit(c, tx, "presensi", presensi.ID, "ubah", sebelum, presensi); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencatat audit"})
+		return
+	}
+
+	// [9] Commit transaksi jika semua berhasil
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Status presensi berhasil diperbarui",
+		"data":    presensi,
+	})
 }
 
-func DeletePresensi(c *gin.Context) {
-    // Get presensi ID from URL parameter
-    presensiID := c.Param("id")
-    if presensiID == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "ID presensi harus disertakan"})
-        return
-    }
-
-    // Start transaction
-    tx := config.DB.Begin()
-    defer func() {
-        if r := recover(); r != nil {
-            tx.Rollback()
-        }
-    }()
-
-    // Check if presensi exists
-    var presensi models.Presensi
-    if err := tx.Where("id = ?", presensiID).First(&presensi).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusNotFound, gin.H{"error": "Presensi tidak ditemukan"})
-        return
-    }
-
-    // Delete presensi
-    if err := tx.Delete(&presensi).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus presensi"})
-        return
-    }
-
-    // Update rekapitulasi - use presensi.AsistenID instead of userID
-    var rekap models.Rekapitulasi
-    if err := tx.Where("asisten_id = ?", presensi.AsistenID).First(&rekap).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error": "Gagal menemukan rekapitulasi",
-            "details": err.Error(),
-        })
-        return
-    }
-
-    // Recalculate counts based on remaining presensi records
-    var presensis []models.Presensi
-    if err := tx.Where("asisten_id = ?", presensi.AsistenID).Find(&presensis).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung ulang rekapitulasi"})
-        return
-    }
-
-    // Reset counters
-    rekap.JumlahHadir = 0
-    rekap.JumlahPengganti = 0
-    rekap.JumlahIzin = 0
-    rekap.JumlahAlpha = 0
-
-    // Recalculate
-    for _, p := range presensis {
-        switch p.Status {
-        case "hadir":
-            if p.Jenis == "utama" {
-                rekap.JumlahHadir++
-            } else if p.Jenis == "pengganti" {
-                rekap.JumlahPengganti++
-            }
-        case "izin":
-            rekap.JumlahIzin++
-        case "alpha":
-            rekap.JumlahAlpha++
-        }
-    }
-
-    rekap.TotalHonor = rekap.HonorPertemuan * (rekap.JumlahHadir + rekap.JumlahPengganti)
-
-    if err := tx.Save(&rekap).Error; err != nil {
-        tx.Rollback()
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui rekapitulasi"})
-        return
-    }
-
-    tx.Commit()
-
-    c.JSON(http.StatusOK, gin.H{
-        "message": "Presensi berhasil dihapus",
-    })
-}
\ No newline at end of file
+func (h *Handler) DeletePresensi(c *gin.Context) {
+	// Get presensi ID from URL parameter
+	presensiID := c.Param("id")
+	if presensiID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID presensi harus disertakan"})
+		return
+	}
+
+	// Start transaction
+	tx := h.DB.Begin()
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Check if presensi exists
+	var presensi models.Presensi
+	if err := tx.Where("id = ?", presensiID).First(&presensi).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Presensi tidak ditemukan"})
+		return
+	}
+
+	if !cekProdi(c, authz.KelolaPresensi, prodiJadwal(tx, presensi.JadwalID)) {
+		tx.Rollback()
+		return
+	}
+
+	if periodeTerkunci(tx, presensi.PeriodeID) {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode presensi sudah ditutup"})
+		return
+	}
+
+	// Delete presensi
+	if err := tx.Delete(&presensi).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus presensi"})
+		return
+	}
+
+	// Susun ulang rekapitulasi dari presensi yang tersisa
+	if _, err := rekapitulasi.Perbarui(tx, h.Config.Honor, presensi.AsistenID, presensi.PeriodeID); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui rekapitulasi"})
+		return
+	}
+
+	if err := catatAudit(c, tx, "presensi", presensi.ID, "hapus", presensi, nil); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mencatat audit"})
+		return
+	}
+
+	tx.Commit()
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Presensi berhasil dihapus",
+	})
+}
@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"forum_asisten/config"
+	"forum_asisten/models"
+)
+
+func TestCekJendelaPresensi(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*60*60)
+	jendela := config.JendelaPresensi{
+		BukaSebelumMulai: 15 * time.Minute,
+		Toleransi:        30 * time.Minute,
+		BatasTerlambat:   2 * time.Hour,
+		Lokasi:           wib,
+	}
+	// Pertemuan 08:00-10:00 WIB; tanggal disimpan sebagai tanggal UTC
+	pertemuan := models.Pertemuan{
+		Tanggal:    time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		JamMulai:   "08:00",
+		JamSelesai: "10:00",
+	}
+	pada := func(jam, menit, detik int) time.Time {
+		return time.Date(2025, 9, 1, jam, menit, detik, 0, wib)
+	}
+
+	tests := []struct {
+		nama      string
+		status    string
+		waktu     time.Time
+		terlambat int
+		err       error
+	}{
+		{"hadir sebelum dibuka", "hadir", pada(7, 44, 59), 0, errPresensiBelumDibuka},
+		{"hadir tepat saat dibuka", "hadir", pada(7, 45, 0), 0, nil},
+		{"izin jauh sebelum pertemuan", "izin", pada(0, 0, 0), 0, nil},
+		{"hadir saat pertemuan dimulai", "hadir", pada(8, 0, 0), 0, nil},
+		{"tepat batas toleransi", "hadir", pada(8, 30, 0), 0, nil},
+		{"lewat toleransi satu detik dibulatkan ke atas", "hadir", pada(8, 30, 1), 1, nil},
+		{"terlambat sepuluh menit", "hadir", pada(8, 40, 0), 10, nil},
+		{"hadir di tengah pertemuan", "hadir", pada(9, 0, 0), 30, nil},
+		{"tepat batas terlambat", "hadir", pada(12, 0, 0), 210, nil},
+		{"lewat batas terlambat", "hadir", pada(12, 0, 1), 0, errPresensiLewatBatas},
+		{"izin lewat batas terlambat", "izin", pada(12, 0, 1), 0, errPresensiLewatBatas},
+		{"zona waktu lain dikonversi", "hadir", time.Date(2025, 9, 1, 1, 40, 0, 0, time.UTC), 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			terlambat, err := cekJendelaPresensi(jendela, pertemuan, tt.status, tt.waktu)
+			if err != tt.err {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if terlambat != tt.terlambat {
+				t.Errorf("terlambat = %d menit, want %d", terlambat, tt.terlambat)
+			}
+		})
+	}
+
+	t.Run("jam tidak valid", func(t *testing.T) {
+		rusak := pertemuan
+		rusak.JamSelesai = "10.00"
+		if _, err := cekJendelaPresensi(jendela, rusak, "hadir", pada(9, 0, 0)); err == nil {
+			t.Fatal("jam rusak diterima")
+		}
+	})
+}
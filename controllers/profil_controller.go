@@ -0,0 +1,363 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"forum_asisten/mailer"
+	"forum_asisten/models"
+	"forum_asisten/utils"
+	"log"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	nimRegex     = regexp.MustCompile(`^[A-Za-z0-9.\-]{4,20}$`)
+	teleponRegex = regexp.MustCompile(`^(\+62|62|0)8[0-9]{7,12}$`)
+)
+
+var (
+	errTokenEmailTidakValid = errors.New("token konfirmasi email tidak valid")
+	errEmailDipakai         = errors.New("email sudah dipakai akun lain")
+)
+
+// UpdateProfilInput memuat field profil yang boleh diubah pemilik akun.
+// Field yang tidak dikirim tidak diubah; string kosong mengosongkan NIM dan
+// telepon. Email baru menunggu konfirmasi dari alamat tersebut. Foto berkas
+// diunggah lewat PUT /me/photo.
+type UpdateProfilInput struct {
+	Nama    *string `json:"nama" form:"nama"`
+	Email   *string `json:"email" form:"email"`
+	NIM     *string `json:"nim" form:"nim"`
+	Telepon *string `json:"telepon" form:"telepon"`
+	Photo   *string `json:"photo" form:"photo"`
+}
+
+func profilUser(user models.User) gin.H {
+	return gin.H{
+		"id":      user.ID,
+		"nama":    user.Nama,
+		"email":   user.Email,
+		"nim":     user.NIM,
+		"telepon": user.Telepon,
+		"role":    user.Role,
+		"status":  user.Status,
+		"photo":   user.Photo,
+	}
+}
+
+// kosongJadiNil mengubah string kosong menjadi NULL di database.
+func kosongJadiNil(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// dipakaiUserLain memeriksa keunikan email atau NIM terhadap user lain,
+// termasuk user di tempat sampah.
+func (h *Handler) dipakaiUserLain(kolom, nilai string, userID uint) (bool, error) {
+	bentrok, err := cariBentrokUnik(h.DB, &models.User{}, kolom, nilai, userID)
+	return bentrok != nil, err
+}
+
+// validasiProfil memeriksa input per field dan mengembalikan perubahan yang
+// akan disimpan, pesan kesalahan per field, serta field yang nilainya sudah
+// dipakai akun lain.
+func (h *Handler) validasiProfil(input UpdateProfilInput, user models.User) (updates map[string]interface{}, salah, bentrok map[string]string, err error) {
+	updates = map[string]interface{}{}
+	salah = map[string]string{}
+	bentrok = map[string]string{}
+
+	if input.Nama != nil {
+		nama := strings.TrimSpace(*input.Nama)
+		switch {
+		case nama == "":
+			salah["nama"] = "Nama wajib diisi"
+		case len(nama) > 100:
+			salah["nama"] = "Nama maksimal 100 karakter"
+		default:
+			updates["nama"] = nama
+		}
+	}
+
+	if input.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*input.Email))
+		if alamat, errAlamat := mail.ParseAddress(email); errAlamat != nil || alamat.Address != email {
+			salah["email"] = "Format email tidak valid"
+		} else if dipakai, errCek := h.dipakaiUserLain("email", email, user.ID); errCek != nil {
+			return nil, nil, nil, errCek
+		} else if dipakai {
+			bentrok["email"] = "Email sudah dipakai akun lain"
+		} else if email != user.Email {
+			updates["email"] = email
+		}
+	}
+
+	if input.NIM != nil {
+		nim := strings.TrimSpace(*input.NIM)
+		if nim != "" && !nimRegex.MatchString(nim) {
+			salah["nim"] = "NIM hanya boleh berisi huruf, angka, titik, atau strip (4-20 karakter)"
+		} else if nim == "" {
+			updates["nim"] = nil
+		} else if dipakai, errCek := h.dipakaiUserLain("nim", nim, user.ID); errCek != nil {
+			return nil, nil, nil, errCek
+		} else if dipakai {
+			bentrok["nim"] = "NIM sudah dipakai akun lain"
+		} else {
+			updates["nim"] = nim
+		}
+	}
+
+	if input.Telepon != nil {
+		telepon := strings.NewReplacer(" ", "", "-", "").Replace(*input.Telepon)
+		if telepon != "" && !teleponRegex.MatchString(telepon) {
+			salah["telepon"] = "Nomor telepon harus nomor seluler Indonesia, contoh 081234567890"
+		} else {
+			updates["telepon"] = kosongJadiNil(telepon)
+		}
+	}
+
+	if input.Photo != nil && strings.TrimSpace(*input.Photo) != "" {
+		photo := strings.TrimSpace(*input.Photo)
+		u, err := url.Parse(photo)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			salah["photo"] = "URL foto tidak valid"
+		} else {
+			updates["photo"] = photo
+		}
+	}
+
+	return updates, salah, bentrok, nil
+}
+
+// GET /me
+func (h *Handler) GetProfilSaya(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    profilUser(user),
+		"message": "User ditemukan",
+		"success": true,
+	})
+}
+
+// PUT /me
+// Menerima JSON maupun form; role dan status tidak dapat diubah di sini.
+func (h *Handler) UpdateProfilSaya(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input UpdateProfilInput
+	if err := c.ShouldBind(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, userID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	updates, salah, bentrok, err := h.validasiProfil(input, user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa data profil"})
+		return
+	}
+	if len(salah) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Data profil tidak valid", "fields": salah})
+		return
+	}
+	if len(bentrok) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Data profil sudah dipakai akun lain", "fields": bentrok})
+		return
+	}
+	// Email baru tidak langsung dipakai: akun dapat diambil alih lewat reset
+	// password atau SSO jika alamatnya bukan milik pemohon
+	emailBaru, gantiEmail := updates["email"].(string)
+	delete(updates, "email")
+	if len(updates) > 0 {
+		sebelum := user
+		err := h.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&user).Updates(updates).Error; err != nil {
+				return err
+			}
+			return catatAudit(c, tx, "user", user.ID, "ubah_profil", sebelum, user)
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui user"})
+			return
+		}
+	}
+	if gantiEmail {
+		if err := h.mintaGantiEmail(c, user, emailBaru); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat konfirmasi email"})
+			return
+		}
+	}
+	h.DB.First(&user, user.ID)
+
+	respon := gin.H{
+		"message": "User berhasil diperbarui",
+		"data":    profilUser(user),
+	}
+	if gantiEmail {
+		respon["email_menunggu_konfirmasi"] = emailBaru
+	}
+	c.JSON(http.StatusOK, respon)
+}
+
+// mintaGantiEmail menyimpan token konfirmasi dan mengirim tautannya ke email
+// baru. Hanya permintaan terbaru yang berlaku.
+func (h *Handler) mintaGantiEmail(c *gin.Context, user models.User, emailBaru string) error {
+	token, err := utils.TokenAcak()
+	if err != nil {
+		return err
+	}
+	masa := h.Config.Token.ResetPassword
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND dipakai_pada IS NULL", user.ID).Delete(&models.GantiEmail{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.GantiEmail{
+			UserID:          user.ID,
+			EmailBaru:       emailBaru,
+			Hash:            utils.HashToken(token),
+			KedaluwarsaPada: time.Now().Add(masa),
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	tautan := h.Config.FrontendURL + "/konfirmasi-email?token=" + url.QueryEscape(token)
+	pesan := mailer.Pesan{
+		Kepada: emailBaru,
+		Subjek: "Konfirmasi email Forum Asisten",
+		Isi: fmt.Sprintf("Halo %s,\n\nAlamat ini diminta menjadi email akun Forum Asisten Anda. "+
+			"Buka tautan berikut untuk mengonfirmasi:\n\n%s\n\n"+
+			"Tautan berlaku %d menit dan hanya dapat dipakai sekali. "+
+			"Abaikan email ini jika Anda tidak memintanya; email akun tidak akan berubah.\n",
+			user.Nama, tautan, int(masa.Minutes())),
+	}
+	if err := h.Mailer.Kirim(c.Request.Context(), pesan); err != nil {
+		log.Println("Gagal mengirim email konfirmasi:", err)
+	}
+	return nil
+}
+
+// POST /email/konfirmasi
+// Email akun baru diganti setelah token dari email konfirmasi dikirim balik.
+func (h *Handler) KonfirmasiEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token wajib diisi"})
+		return
+	}
+
+	var ganti models.GantiEmail
+	if err := h.DB.Where("hash = ?", utils.HashToken(input.Token)).First(&ganti).Error; err != nil ||
+		ganti.DipakaiPada != nil || time.Now().After(ganti.KedaluwarsaPada) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token konfirmasi tidak valid atau sudah kedaluwarsa"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.GantiEmail{}).
+			Where("id = ? AND dipakai_pada IS NULL", ganti.ID).
+			Update("dipakai_pada", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errTokenEmailTidakValid
+		}
+		// Alamat bisa saja sudah didaftarkan akun lain sejak permintaan dibuat
+		bentrok, err := cariBentrokUnik(tx, &models.User{}, "email", ganti.EmailBaru, ganti.UserID)
+		if err != nil {
+			return err
+		}
+		if bentrok != nil {
+			return errEmailDipakai
+		}
+
+		var sebelum models.User
+		if err := tx.First(&sebelum, ganti.UserID).Error; err != nil {
+			return err
+		}
+		sesudah := sebelum
+		if err := tx.Model(&sesudah).Update("email", ganti.EmailBaru).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", sebelum.ID, "ganti_email", sebelum, sesudah)
+	})
+	switch {
+	case errors.Is(err, errTokenEmailTidakValid), errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token konfirmasi tidak valid atau sudah kedaluwarsa"})
+	case errors.Is(err, errEmailDipakai):
+		c.JSON(http.StatusConflict, gin.H{"error": "Email sudah dipakai akun lain"})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengonfirmasi email"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Email berhasil diperbarui", "email": ganti.EmailBaru})
+	}
+}
+
+// PUT /me/photo
+func (h *Handler) UnggahFotoSaya(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	fileHeader, err := c.FormFile("photo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Berkas foto wajib diunggah"})
+		return
+	}
+
+	berkas, err := h.simpanBerkas(c, fileHeader, "foto", userID)
+	if err != nil {
+		responErrorBerkas(c, err)
+		return
+	}
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		sebelum := user
+		if err := tx.Model(&user).Update("photo", berkas.URL()).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user", user.ID, "ubah_foto", sebelum, user)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui foto"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Foto berhasil diperbarui", "photo": berkas.URL()})
+}
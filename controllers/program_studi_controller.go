@@ -1,38 +1,47 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 )
 
-func CreateProgramStudi(c *gin.Context) {
+func (h *Handler) CreateProgramStudi(c *gin.Context) {
 	var ps models.ProgramStudi
 	if err := c.ShouldBindJSON(&ps); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Format data salah"})
 		return
 	}
-	if err := config.DB.Create(&ps).Error; err != nil {
+	if !h.cekUnik(c, &models.ProgramStudi{}, "nama", ps.Nama, 0, "Nama program studi", "program-studi") {
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&ps).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "program_studi", ps.ID, "buat", nil, ps)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan"})
 		return
 	}
 	c.JSON(http.StatusCreated, ps)
 }
 
-func GetAllProgramStudi(c *gin.Context) {
+func (h *Handler) GetAllProgramStudi(c *gin.Context) {
 	var list []models.ProgramStudi
-	if err := config.DB.Find(&list).Error; err != nil {
+	if err := h.DB.Find(&list).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data"})
 		return
 	}
 	c.JSON(http.StatusOK, list)
 }
 
-func UpdateProgramStudi(c *gin.Context) {
+func (h *Handler) UpdateProgramStudi(c *gin.Context) {
 	id := c.Param("id")
 	var ps models.ProgramStudi
-	if err := config.DB.First(&ps, id).Error; err != nil {
+	if err := h.DB.First(&ps, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Program studi tidak ditemukan"})
 		return
 	}
@@ -43,14 +52,47 @@ func UpdateProgramStudi(c *gin.Context) {
 		return
 	}
 
+	if !h.cekUnik(c, &models.ProgramStudi{}, "nama", input.Nama, ps.ID, "Nama program studi", "program-studi") {
+		return
+	}
+
+	sebelum := ps
 	ps.Nama = input.Nama
-	config.DB.Save(&ps)
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&ps).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "program_studi", ps.ID, "ubah", sebelum, ps)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui program studi"})
+		return
+	}
 	c.JSON(http.StatusOK, ps)
 }
 
-func DeleteProgramStudi(c *gin.Context) {
+func (h *Handler) DeleteProgramStudi(c *gin.Context) {
 	id := c.Param("id")
-	if err := config.DB.Delete(&models.ProgramStudi{}, id).Error; err != nil {
+	var ps models.ProgramStudi
+	if err := h.DB.First(&ps, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Program studi tidak ditemukan"})
+		return
+	}
+	if h.masihDipakai(&models.MataKuliah{}, "program_studi_id", ps.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Program studi masih memiliki mata kuliah"})
+		return
+	}
+	if h.masihDipakai(&models.UserRole{}, "program_studi_id", ps.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Program studi masih dipakai sebagai cakupan role user"})
+		return
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&ps).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "program_studi", ps.ID, "hapus", ps, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus"})
 		return
 	}
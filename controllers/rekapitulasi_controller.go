@@ -1,25 +1,20 @@
 package controllers
 
 import (
-	"forum_asisten/config"
 	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
-var honorMap = map[string]int{
-	"A": 12500,
-	"B": 14500,
-	"C": 16500,
-	"D": 22500,
-	"E": 24500,
-}
-
-func SetTipeHonor(c *gin.Context) {
+func (h *Handler) SetTipeHonor(c *gin.Context) {
 	var input struct {
 		AsistenID uint   `json:"asisten_id" binding:"required"`
-		TipeHonor string `json:"tipe_honor" binding:"required,oneof=A B C D E"`
+		PeriodeID uint   `json:"periode_id"` // optional, default periode aktif
+		TipeHonor string `json:"tipe_honor" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -27,43 +22,44 @@ func SetTipeHonor(c *gin.Context) {
 		return
 	}
 
-	honor, exists := honorMap[input.TipeHonor]
-	if !exists {
+	if !kodeTarifAda(h.DB, input.TipeHonor) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Tipe honor tidak valid"})
 		return
 	}
 
+	periodeID, ok := h.periodeDariInput(c, input.PeriodeID)
+	if !ok {
+		return
+	}
+
 	var rekap models.Rekapitulasi
-	if err := config.DB.Where("asisten_id = ?", input.AsistenID).First(&rekap).Error; err != nil {
+	var sebelum interface{}
+	if err := h.DB.Where("asisten_id = ? AND periode_id = ?", input.AsistenID, periodeID).First(&rekap).Error; err != nil {
 		// Belum ada rekap, buat baru
 		rekap = models.Rekapitulasi{
-			AsistenID:      input.AsistenID,
-			TipeHonor:      input.TipeHonor,
-			HonorPertemuan: honor,
+			AsistenID: input.AsistenID,
+			PeriodeID: periodeID,
+			TipeHonor: input.TipeHonor,
 		}
 	} else {
 		// Update tipe honor
+		sebelum = rekap
 		rekap.TipeHonor = input.TipeHonor
-		rekap.HonorPertemuan = honor
 	}
 
-	// Hitung ulang total honor
-	var hadir, izin, alpha, pengganti int64
-
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "hadir", "utama").Count(&hadir)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "izin", "utama").Count(&izin)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "alpha", "utama").Count(&alpha)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "hadir", "pengganti").Count(&pengganti)
-
-	rekap.JumlahHadir = int(hadir)
-	rekap.JumlahIzin = int(izin)
-	rekap.JumlahAlpha = int(alpha)
-	rekap.JumlahPengganti = int(pengganti)
-
-	// Hitung ulang total honor
-	rekap.TotalHonor = rekap.HonorPertemuan * (rekap.JumlahHadir + rekap.JumlahPengganti)
-
-	if err := config.DB.Save(&rekap).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Hitung ulang counter dan honor dari presensi di dalam transaksi yang
+		// sama dengan penyimpanan agar tidak tertimpa presensi yang masuk
+		// bersamaan
+		if err := rekapitulasi.Susun(tx, h.Config.Honor, &rekap); err != nil {
+			return err
+		}
+		if err := tx.Save(&rekap).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "rekapitulasi", rekap.ID, "ubah_tipe_honor", sebelum, rekap)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan rekapitulasi"})
 		return
 	}
@@ -71,11 +67,37 @@ func SetTipeHonor(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Tipe honor disimpan", "data": rekap})
 }
 
-func GetRekapitulasi(c *gin.Context) {
+// periodeDariInput memakai periode_id dari body jika diisi, selain itu periode
+// aktif. Periode yang sudah ditutup ditolak karena rekapitulasinya dibekukan.
+func (h *Handler) periodeDariInput(c *gin.Context, periodeID uint) (uint, bool) {
+	var periode models.Periode
+	var err error
+	if periodeID == 0 {
+		periode, err = periodeAktif(h.DB)
+	} else {
+		err = h.DB.First(&periode, periodeID).Error
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Periode tidak ditemukan atau belum ada periode aktif"})
+		return 0, false
+	}
+	if periode.Ditutup {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rekapitulasi periode yang sudah ditutup tidak dapat diubah"})
+		return 0, false
+	}
+	return periode.ID, true
+}
+
+func (h *Handler) GetRekapitulasi(c *gin.Context) {
+	periode, ok := h.periodeDariQuery(c)
+	if !ok {
+		return
+	}
+
 	var rekapList []models.Rekapitulasi
 	asistenID := c.Query("asisten_id") // optional query param
 
-	query := config.DB.Preload("Asisten")
+	query := h.DB.Preload("Asisten", termasukTerhapus).Preload("Periode").Where("periode_id = ?", periode.ID)
 
 	if asistenID != "" {
 		query = query.Where("asisten_id = ?", asistenID)
@@ -89,54 +111,60 @@ func GetRekapitulasi(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"data": rekapList})
 }
 
-func UpdateRekapitulasi(c *gin.Context) {
+func (h *Handler) UpdateRekapitulasi(c *gin.Context) {
 	var input struct {
-		AsistenID       uint   `json:"asisten_id" binding:"required"`
-		JumlahHadir     int    `json:"jumlah_hadir"`
-		JumlahIzin      int    `json:"jumlah_izin"`
-		JumlahAlpha     int    `json:"jumlah_alpha"`
-		JumlahPengganti int    `json:"jumlah_pengganti"`
-		TipeHonor       string `json:"tipe_honor" binding:"omitempty,oneof=A B C D E"` // optional field
+		AsistenID uint   `json:"asisten_id" binding:"required"`
+		PeriodeID uint   `json:"periode_id"` // optional, default periode aktif
+		TipeHonor string `json:"tipe_honor"` // optional field
+
+		// Counter dihitung dari presensi; diterima hanya untuk ditolak
+		JumlahHadir     *int `json:"jumlah_hadir"`
+		JumlahIzin      *int `json:"jumlah_izin"`
+		JumlahAlpha     *int `json:"jumlah_alpha"`
+		JumlahPengganti *int `json:"jumlah_pengganti"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
 		return
 	}
+	if input.JumlahHadir != nil || input.JumlahIzin != nil || input.JumlahAlpha != nil || input.JumlahPengganti != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Jumlah hadir, izin, alpha dan pengganti dihitung dari presensi dan tidak dapat diubah; ubah presensinya"})
+		return
+	}
+
+	periodeID, ok := h.periodeDariInput(c, input.PeriodeID)
+	if !ok {
+		return
+	}
 
 	var rekap models.Rekapitulasi
-	if err := config.DB.Where("asisten_id = ?", input.AsistenID).First(&rekap).Error; err != nil {
+	if err := h.DB.Where("asisten_id = ? AND periode_id = ?", input.AsistenID, periodeID).First(&rekap).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rekapitulasi tidak ditemukan"})
 		return
 	}
+	sebelum := rekap
 
 	// Update tipe honor if provided
 	if input.TipeHonor != "" {
-		honor, exists := honorMap[input.TipeHonor]
-		if !exists {
+		if !kodeTarifAda(h.DB, input.TipeHonor) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Tipe honor tidak valid"})
 			return
 		}
 		rekap.TipeHonor = input.TipeHonor
-		rekap.HonorPertemuan = honor
 	}
 
-	// Hitung ulang dari tabel presensi
-	var hadir, izin, alpha, pengganti int64
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "hadir", "utama").Count(&hadir)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "izin", "utama").Count(&izin)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "alpha", "utama").Count(&alpha)
-	config.DB.Model(&models.Presensi{}).Where("asisten_id = ? AND status = ? AND jenis = ?", input.AsistenID, "hadir", "pengganti").Count(&pengganti)
-
-	rekap.JumlahHadir = int(hadir)
-	rekap.JumlahIzin = int(izin)
-	rekap.JumlahAlpha = int(alpha)
-	rekap.JumlahPengganti = int(pengganti)
-
-	// Hitung ulang total honor
-	rekap.TotalHonor = rekap.HonorPertemuan * (rekap.JumlahHadir + rekap.JumlahPengganti)
-
-	if err := config.DB.Save(&rekap).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Counter selalu dihitung ulang dari tabel presensi
+		if err := rekapitulasi.Susun(tx, h.Config.Honor, &rekap); err != nil {
+			return err
+		}
+		if err := tx.Save(&rekap).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "rekapitulasi", rekap.ID, "ubah", sebelum, rekap)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengupdate rekapitulasi"})
 		return
 	}
@@ -144,19 +172,91 @@ func UpdateRekapitulasi(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Rekapitulasi diperbarui", "data": rekap})
 }
 
-func DeleteRekapitulasi(c *gin.Context) {
-	asistenID := c.Param("asisten_id")
+// DELETE /admin/rekapitulasi/:id
+// :id adalah id rekapitulasi (satu per asisten per periode).
+func (h *Handler) DeleteRekapitulasi(c *gin.Context) {
+	id := c.Param("id")
 
 	var rekap models.Rekapitulasi
-	if err := config.DB.Where("asisten_id = ?", asistenID).First(&rekap).Error; err != nil {
+	if err := h.DB.First(&rekap, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rekapitulasi tidak ditemukan"})
 		return
 	}
+	if periodeTerkunci(h.DB, rekap.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rekapitulasi periode yang sudah ditutup tidak dapat dihapus"})
+		return
+	}
+	if h.masihDipakai(&models.Sanggah{}, "rekapitulasi_id", rekap.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rekapitulasi memiliki sanggahan dan tidak dapat dihapus"})
+		return
+	}
 
-	if err := config.DB.Delete(&rekap).Error; err != nil {
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&rekap).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "rekapitulasi", rekap.ID, "hapus", rekap, nil)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus rekapitulasi"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Rekapitulasi berhasil dihapus"})
 }
+
+// periodeIDQuery membaca ?periode_id= opsional; 0 berarti semua periode.
+func periodeIDQuery(c *gin.Context) (uint, bool) {
+	raw := c.Query("periode_id")
+	if raw == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "periode_id tidak valid"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// POST /admin/rekapitulasi/hitung-ulang?periode_id=
+// Menyusun ulang semua rekapitulasi periode terbuka dari tabel presensi.
+func (h *Handler) HitungUlangSemuaRekapitulasi(c *gin.Context) {
+	periodeID, ok := periodeIDQuery(c)
+	if !ok {
+		return
+	}
+
+	var jumlah int
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if jumlah, err = rekapitulasi.HitungUlangSemua(tx, h.Config.Honor, periodeID); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "rekapitulasi", periodeID, "hitung_ulang", nil, gin.H{"periode_id": periodeID, "jumlah": jumlah})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghitung ulang rekapitulasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rekapitulasi dihitung ulang", "jumlah": jumlah})
+}
+
+// GET /admin/rekapitulasi/konsistensi?periode_id=
+// Melaporkan rekapitulasi yang counter atau total honornya berbeda dari hasil
+// hitung ulang presensi, tanpa mengubah data.
+func (h *Handler) PeriksaKonsistensiRekapitulasi(c *gin.Context) {
+	periodeID, ok := periodeIDQuery(c)
+	if !ok {
+		return
+	}
+
+	selisih, err := rekapitulasi.Periksa(h.DB, h.Config.Honor, periodeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memeriksa rekapitulasi"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"konsisten": len(selisih) == 0, "selisih": selisih})
+}
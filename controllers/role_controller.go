@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"forum_asisten/authz"
+	"forum_asisten/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GET /admin/users/:id/roles
+func (h *Handler) GetRoleUser(c *gin.Context) {
+	var user models.User
+	if err := h.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+
+	var roles []models.UserRole
+	if err := h.DB.Preload("ProgramStudi").Where("user_id = ?", user.ID).Find(&roles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil role user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"role_utama": user.Role, "role_tambahan": roles})
+}
+
+// POST /admin/users/:id/roles
+// Menambahkan role koordinator, mis. {"role": "koordinator", "program_studi_id": 2}.
+// Tanpa program_studi_id role berlaku untuk semua program studi.
+func (h *Handler) TambahRoleUser(c *gin.Context) {
+	var input struct {
+		Role           string `json:"role" binding:"required"`
+		ProgramStudiID *uint  `json:"program_studi_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role wajib diisi"})
+		return
+	}
+	if !authz.RoleTambahanValid(input.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role tambahan hanya dapat berupa koordinator; admin diberikan lewat role utama user"})
+		return
+	}
+
+	var user models.User
+	if err := h.DB.First(&user, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+		return
+	}
+	if input.ProgramStudiID != nil {
+		var prodi models.ProgramStudi
+		if err := h.DB.First(&prodi, *input.ProgramStudiID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Program studi tidak ditemukan"})
+			return
+		}
+	}
+
+	query := h.DB.Model(&models.UserRole{}).Where("user_id = ? AND role = ?", user.ID, input.Role)
+	if input.ProgramStudiID == nil {
+		query = query.Where("program_studi_id IS NULL")
+	} else {
+		query = query.Where("program_studi_id = ?", *input.ProgramStudiID)
+	}
+	var jumlah int64
+	query.Count(&jumlah)
+	if jumlah > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "User sudah memiliki role tersebut"})
+		return
+	}
+
+	role := models.UserRole{UserID: user.ID, Role: input.Role, ProgramStudiID: input.ProgramStudiID}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user_role", role.ID, "buat", nil, role)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menambahkan role"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Role berhasil ditambahkan", "data": role})
+}
+
+// DELETE /admin/users/:id/roles/:role_id
+func (h *Handler) HapusRoleUser(c *gin.Context) {
+	var role models.UserRole
+	if err := h.DB.Where("id = ? AND user_id = ?", c.Param("role_id"), c.Param("id")).First(&role).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role user tidak ditemukan"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&role).Error; err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "user_role", role.ID, "hapus", role, nil)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus role"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Role berhasil dihapus"})
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errPulihkan menandai data yang belum dapat dipulihkan, mis. karena data
+// induknya masih di tempat sampah.
+type errPulihkan struct{ pesan string }
+
+func (e errPulihkan) Error() string { return e.pesan }
+
+type entitasSampah struct {
+	nama     string // nama entitas pada audit log
+	daftar   func(db *gorm.DB) (interface{}, error)
+	pulihkan func(h *Handler, c *gin.Context, tx *gorm.DB, id string) (interface{}, error)
+}
+
+// sampah memetakan segmen URL ke entitas yang mendukung soft delete.
+var sampah = map[string]entitasSampah{
+	"program-studi": {"program_studi", daftarTerhapus[models.ProgramStudi], (*Handler).pulihkanProgramStudi},
+	"mata-kuliah":   {"mata_kuliah", daftarTerhapus[models.MataKuliah], (*Handler).pulihkanMataKuliah},
+	"dosen":         {"dosen", daftarTerhapus[models.Dosen], (*Handler).pulihkanDosen},
+	"jadwal":        {"jadwal", daftarTerhapus[models.Jadwal], (*Handler).pulihkanJadwal},
+	"users":         {"user", daftarTerhapus[models.User], (*Handler).pulihkanUser},
+	"presensi":      {"presensi", daftarTerhapus[models.Presensi], (*Handler).pulihkanPresensi},
+}
+
+func daftarTerhapus[T any](db *gorm.DB) (interface{}, error) {
+	var list []T
+	err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&list).Error
+	return list, err
+}
+
+// ambilTerhapus memuat baris yang ada di tempat sampah.
+func ambilTerhapus(tx *gorm.DB, dest interface{}, id string) error {
+	return tx.Unscoped().Where("deleted_at IS NOT NULL").First(dest, id).Error
+}
+
+// batalHapus mengosongkan deleted_at lalu memuat ulang data ke dest.
+func batalHapus(tx *gorm.DB, dest interface{}) error {
+	if err := tx.Unscoped().Model(dest).Update("deleted_at", nil).Error; err != nil {
+		return err
+	}
+	return tx.First(dest).Error
+}
+
+// GET /admin/sampah/:entitas
+func (h *Handler) GetSampah(c *gin.Context) {
+	entitas, ok := sampah[c.Param("entitas")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entitas tidak dikenal"})
+		return
+	}
+
+	list, err := entitas.daftar(h.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data terhapus"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// PUT /admin/sampah/:entitas/:id/pulihkan
+func (h *Handler) PulihkanSampah(c *gin.Context) {
+	entitas, ok := sampah[c.Param("entitas")]
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entitas tidak dikenal"})
+		return
+	}
+
+	var hasil interface{}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if hasil, err = entitas.pulihkan(h, c, tx, c.Param("id")); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, entitas.nama, c.Param("id"), "pulihkan", nil, hasil)
+	})
+
+	var gagal errPulihkan
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data tidak ada di tempat sampah"})
+	case errors.As(err, &gagal):
+		c.JSON(http.StatusConflict, gin.H{"error": gagal.pesan})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulihkan data"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Data berhasil dipulihkan", "data": hasil})
+	}
+}
+
+func (h *Handler) pulihkanProgramStudi(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var ps models.ProgramStudi
+	if err := ambilTerhapus(tx, &ps, id); err != nil {
+		return nil, err
+	}
+	if err := batalHapus(tx, &ps); err != nil {
+		return nil, err
+	}
+	return ps, nil
+}
+
+func (h *Handler) pulihkanMataKuliah(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var mk models.MataKuliah
+	if err := ambilTerhapus(tx, &mk, id); err != nil {
+		return nil, err
+	}
+	if err := tx.First(&models.ProgramStudi{}, mk.ProgramStudiID).Error; err != nil {
+		return nil, errPulihkan{"Program studi mata kuliah ini sudah dihapus, pulihkan terlebih dahulu"}
+	}
+	if err := batalHapus(tx, &mk); err != nil {
+		return nil, err
+	}
+	return mk, nil
+}
+
+func (h *Handler) pulihkanDosen(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var dosen models.Dosen
+	if err := ambilTerhapus(tx, &dosen, id); err != nil {
+		return nil, err
+	}
+	if err := batalHapus(tx, &dosen); err != nil {
+		return nil, err
+	}
+	return dosen, nil
+}
+
+// pulihkanJadwal juga memulihkan plotting yang terhapus bersama jadwal dan
+// menyusun ulang pertemuannya.
+func (h *Handler) pulihkanJadwal(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var jadwal models.Jadwal
+	if err := ambilTerhapus(tx, &jadwal, id); err != nil {
+		return nil, err
+	}
+	if periodeTerkunci(tx, jadwal.PeriodeID) {
+		return nil, errPulihkan{"Periode jadwal sudah ditutup"}
+	}
+	if err := tx.First(&models.MataKuliah{}, jadwal.MataKuliahID).Error; err != nil {
+		return nil, errPulihkan{"Mata kuliah jadwal ini sudah dihapus, pulihkan terlebih dahulu"}
+	}
+	if err := tx.First(&models.Dosen{}, jadwal.DosenID).Error; err != nil {
+		return nil, errPulihkan{"Dosen jadwal ini sudah dihapus, pulihkan terlebih dahulu"}
+	}
+
+	dihapus := jadwal.DeletedAt.Time
+	if err := batalHapus(tx, &jadwal); err != nil {
+		return nil, err
+	}
+	if err := tx.Unscoped().Model(&models.AsistenKelas{}).
+		Where("jadwal_id = ? AND deleted_at >= ?", jadwal.ID, dihapus).
+		Where("asisten_id IN (?)", tx.Model(&models.User{}).Select("id")).
+		Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	if _, err := susunPertemuan(tx, jadwal); err != nil {
+		return nil, err
+	}
+	return jadwal, nil
+}
+
+// pulihkanUser juga memulihkan plotting yang terhapus bersama user.
+func (h *Handler) pulihkanUser(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var user models.User
+	if err := ambilTerhapus(tx, &user, id); err != nil {
+		return nil, err
+	}
+	dihapus := user.DeletedAt.Time
+	if err := batalHapus(tx, &user); err != nil {
+		return nil, err
+	}
+	periodeTerbuka := tx.Model(&models.Periode{}).Select("id").Where("ditutup = ?", false)
+	if err := tx.Unscoped().Model(&models.AsistenKelas{}).
+		Where("asisten_id = ? AND deleted_at >= ?", user.ID, dihapus).
+		Where("periode_id IN (?)", periodeTerbuka).
+		Where("jadwal_id IN (?)", tx.Model(&models.Jadwal{}).Select("id")).
+		Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// pulihkanPresensi memulihkan presensi lalu menyusun ulang rekapitulasi
+// asisten pada periodenya.
+func (h *Handler) pulihkanPresensi(c *gin.Context, tx *gorm.DB, id string) (interface{}, error) {
+	var presensi models.Presensi
+	if err := ambilTerhapus(tx, &presensi, id); err != nil {
+		return nil, err
+	}
+	if periodeTerkunci(tx, presensi.PeriodeID) {
+		return nil, errPulihkan{"Periode presensi sudah ditutup"}
+	}
+	if err := tx.First(&models.Jadwal{}, presensi.JadwalID).Error; err != nil {
+		return nil, errPulihkan{"Jadwal presensi ini sudah dihapus, pulihkan terlebih dahulu"}
+	}
+
+	if err := batalHapus(tx, &presensi); err != nil {
+		return nil, err
+	}
+	if _, err := rekapitulasi.Perbarui(tx, h.Config.Honor, presensi.AsistenID, presensi.PeriodeID); err != nil {
+		return nil, err
+	}
+	return presensi, nil
+}
@@ -2,18 +2,58 @@
 package controllers
 
 import (
-	"forum_asisten/config"
+	"errors"
+	"forum_asisten/authz"
 	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+var (
+	errStatusSanggahBerubah  = errors.New("status sanggahan sudah berubah")
+	errPresensiSanggahHilang = errors.New("presensi yang disanggah sudah tidak ada")
+)
+
+// transisiSanggah berisi perubahan status sanggahan yang diizinkan.
+var transisiSanggah = map[string][]string{
+	"diajukan": {"diproses", "diterima", "ditolak"},
+	"diproses": {"diterima", "ditolak"},
+}
+
+func bolehTransisiSanggah(dari, ke string) bool {
+	for _, s := range transisiSanggah[dari] {
+		if s == ke {
+			return true
+		}
+	}
+	return false
+}
+
+func preloadSanggah(db *gorm.DB) *gorm.DB {
+	return db.Preload("Rekapitulasi.Asisten", termasukTerhapus).
+		Preload("Presensi.Presensi.Pertemuan").
+		Preload("Balasan", func(db *gorm.DB) *gorm.DB { return db.Order("waktu") }).
+		Preload("Balasan.Penulis", termasukTerhapus)
+}
+
 // POST /sanggah
-func BuatSanggah(c *gin.Context) {
+func (h *Handler) BuatSanggah(c *gin.Context) {
+	aktor, ok := aktorDari(c)
+	if !ok {
+		return
+	}
+
 	var input struct {
 		RekapitulasiID uint   `json:"rekapitulasi_id" binding:"required"`
 		IsiSanggahan   string `json:"isi_sanggahan" binding:"required"`
+		Presensi       []struct {
+			PresensiID   uint   `json:"presensi_id" binding:"required"`
+			StatusUsulan string `json:"status_usulan" binding:"required,oneof=hadir izin alpha"`
+		} `json:"presensi" binding:"dive"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -21,12 +61,44 @@ func BuatSanggah(c *gin.Context) {
 		return
 	}
 
+	var rekap models.Rekapitulasi
+	if err := h.DB.First(&rekap, input.RekapitulasiID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rekapitulasi tidak ditemukan"})
+		return
+	}
+	if !authz.BolehSanggah(aktor, authz.BuatSanggah, rekap.AsistenID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda hanya dapat menyanggah rekapitulasi milik sendiri"})
+		return
+	}
+	if periodeTerkunci(h.DB, rekap.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rekapitulasi periode yang sudah ditutup tidak dapat disanggah"})
+		return
+	}
+
 	sanggah := models.Sanggah{
 		RekapitulasiID: input.RekapitulasiID,
 		IsiSanggahan:   input.IsiSanggahan,
+		Status:         "diajukan",
+	}
+
+	// Presensi yang disanggah harus bagian dari rekapitulasi tersebut
+	for _, p := range input.Presensi {
+		var presensi models.Presensi
+		if err := h.DB.First(&presensi, p.PresensiID).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Presensi yang disanggah tidak ditemukan"})
+			return
+		}
+		if presensi.AsistenID != rekap.AsistenID || presensi.PeriodeID != rekap.PeriodeID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Presensi yang disanggah bukan bagian dari rekapitulasi ini"})
+			return
+		}
+		sanggah.Presensi = append(sanggah.Presensi, models.SanggahPresensi{
+			PresensiID:   p.PresensiID,
+			StatusUsulan: p.StatusUsulan,
+		})
 	}
 
-	if err := config.DB.Create(&sanggah).Error; err != nil {
+	if err := h.DB.Create(&sanggah).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan sanggahan"})
 		return
 	}
@@ -35,10 +107,22 @@ func BuatSanggah(c *gin.Context) {
 }
 
 // GET /sanggah
-func GetSemuaSanggah(c *gin.Context) {
-	var list []models.Sanggah
+// Admin melihat semua sanggahan, asisten hanya sanggahan atas rekapitulasinya.
+func (h *Handler) GetSemuaSanggah(c *gin.Context) {
+	aktor, ok := aktorDari(c)
+	if !ok {
+		return
+	}
 
-	if err := config.DB.Preload("Rekapitulasi.Asisten").Find(&list).Error; err != nil {
+	query := preloadSanggah(h.DB)
+	if asistenID, semua := authz.FilterSanggah(aktor); !semua {
+		query = query.
+			Joins("JOIN rekapitulasi ON rekapitulasi.id = sanggah.rekapitulasi_id").
+			Where("rekapitulasi.asisten_id = ?", asistenID)
+	}
+
+	var list []models.Sanggah
+	if err := query.Find(&list).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data sanggahan"})
 		return
 	}
@@ -47,14 +131,199 @@ func GetSemuaSanggah(c *gin.Context) {
 }
 
 // GET /sanggah/:id
-func GetSanggahByID(c *gin.Context) {
+func (h *Handler) GetSanggahByID(c *gin.Context) {
+	aktor, ok := aktorDari(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 	var sanggah models.Sanggah
 
-	if err := config.DB.Preload("Rekapitulasi").First(&sanggah, id).Error; err != nil {
+	if err := preloadSanggah(h.DB).First(&sanggah, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Sanggahan tidak ditemukan"})
 		return
 	}
+	if !authz.BolehSanggah(aktor, authz.LihatSanggah, sanggah.Rekapitulasi.AsistenID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak berhak melihat sanggahan ini"})
+		return
+	}
 
 	c.JSON(http.StatusOK, sanggah)
 }
+
+// POST /sanggah/:id/balasan
+func (h *Handler) BalasSanggah(c *gin.Context) {
+	aktor, ok := aktorDari(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Isi string `json:"isi" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Isi balasan wajib diisi"})
+		return
+	}
+
+	var sanggah models.Sanggah
+	if err := h.DB.Preload("Rekapitulasi").First(&sanggah, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sanggahan tidak ditemukan"})
+		return
+	}
+	if !authz.BolehSanggah(aktor, authz.BalasSanggah, sanggah.Rekapitulasi.AsistenID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak berhak membalas sanggahan ini"})
+		return
+	}
+	if sanggah.Status == "diterima" || sanggah.Status == "ditolak" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Sanggahan sudah selesai"})
+		return
+	}
+
+	balasan := models.SanggahBalasan{
+		SanggahID: sanggah.ID,
+		PenulisID: aktor.UserID,
+		Isi:       input.Isi,
+	}
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&balasan).Error; err != nil {
+			return err
+		}
+		// Balasan pertama dari admin menandakan sanggahan mulai diproses
+		if aktor.Admin() && sanggah.Status == "diajukan" {
+			return tx.Model(&models.Sanggah{ID: sanggah.ID}).Update("status", "diproses").Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan balasan"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Balasan terkirim", "data": balasan})
+}
+
+// GET /admin/sanggah?status=&periode_id=&asisten_id=
+func (h *Handler) AdminGetSanggah(c *gin.Context) {
+	query := preloadSanggah(h.DB).
+		Joins("JOIN rekapitulasi ON rekapitulasi.id = sanggah.rekapitulasi_id")
+
+	if status := c.Query("status"); status != "" {
+		query = query.Where("sanggah.status = ?", status)
+	}
+	if periodeID := c.Query("periode_id"); periodeID != "" {
+		query = query.Where("rekapitulasi.periode_id = ?", periodeID)
+	}
+	if asistenID := c.Query("asisten_id"); asistenID != "" {
+		query = query.Where("rekapitulasi.asisten_id = ?", asistenID)
+	}
+
+	var list []models.Sanggah
+	if err := query.Order("sanggah.waktu DESC").Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data sanggahan"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// PUT /admin/sanggah/:id/status
+// Jika sanggahan diterima, koreksi presensi yang ditautkan diterapkan dan
+// rekapitulasi dihitung ulang dalam satu transaksi.
+func (h *Handler) UbahStatusSanggah(c *gin.Context) {
+	adminID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		Status  string `json:"status" binding:"required,oneof=diproses diterima ditolak"`
+		Catatan string `json:"catatan"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Status harus diproses, diterima, atau ditolak"})
+		return
+	}
+
+	var sanggah models.Sanggah
+	if err := h.DB.Preload("Presensi").Preload("Rekapitulasi").First(&sanggah, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sanggahan tidak ditemukan"})
+		return
+	}
+	if !bolehTransisiSanggah(sanggah.Status, input.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Status sanggahan tidak dapat diubah dari " + sanggah.Status + " ke " + input.Status})
+		return
+	}
+	if input.Status == "diterima" && periodeTerkunci(h.DB, sanggah.Rekapitulasi.PeriodeID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Periode rekapitulasi sudah ditutup"})
+		return
+	}
+
+	sebelum := sanggah.Status
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"status": input.Status}
+		if input.Status != "diproses" {
+			now := time.Now()
+			updates["diselesaikan_pada"] = &now
+			updates["diselesaikan_oleh"] = adminID
+		}
+		// Status di database harus masih sama dengan yang diperiksa, agar dua
+		// keputusan bersamaan tidak sama-sama diterapkan
+		res := tx.Model(&models.Sanggah{}).Where("id = ? AND status = ?", sanggah.ID, sebelum).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errStatusSanggahBerubah
+		}
+
+		if input.Catatan != "" {
+			balasan := models.SanggahBalasan{SanggahID: sanggah.ID, PenulisID: adminID, Isi: input.Catatan}
+			if err := tx.Create(&balasan).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := catatAudit(c, tx, "sanggah", sanggah.ID, "ubah_status",
+			gin.H{"status": sebelum}, gin.H{"status": input.Status, "catatan": input.Catatan}); err != nil {
+			return err
+		}
+		if input.Status != "diterima" {
+			return nil
+		}
+
+		for _, koreksi := range sanggah.Presensi {
+			var presensi models.Presensi
+			if err := tx.Where("id = ? AND asisten_id = ?", koreksi.PresensiID, sanggah.Rekapitulasi.AsistenID).
+				First(&presensi).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+				return errPresensiSanggahHilang
+			} else if err != nil {
+				return err
+			}
+			presensiSebelum := presensi
+			if err := tx.Model(&presensi).Update("status", koreksi.StatusUsulan).Error; err != nil {
+				return err
+			}
+			if err := catatAudit(c, tx, "presensi", presensi.ID, "koreksi_sanggah", presensiSebelum, presensi); err != nil {
+				return err
+			}
+		}
+		_, err := rekapitulasi.Perbarui(tx, h.Config.Honor, sanggah.Rekapitulasi.AsistenID, sanggah.Rekapitulasi.PeriodeID)
+		return err
+	})
+	switch {
+	case errors.Is(err, errStatusSanggahBerubah):
+		c.JSON(http.StatusConflict, gin.H{"error": "Status sanggahan sudah diubah, muat ulang data"})
+		return
+	case errors.Is(err, errPresensiSanggahHilang):
+		c.JSON(http.StatusConflict, gin.H{"error": "Presensi yang disanggah sudah tidak ada"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui sanggahan"})
+		return
+	}
+
+	var hasil models.Sanggah
+	preloadSanggah(h.DB).First(&hasil, sanggah.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Status sanggahan diperbarui", "data": hasil})
+}
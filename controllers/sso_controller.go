@@ -0,0 +1,207 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/sso"
+	"forum_asisten/utils"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GET /sso/login
+// Mengarahkan browser ke halaman login kampus.
+func (h *Handler) SSOLogin(c *gin.Context) {
+	if h.SSO == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Login SSO tidak diaktifkan"})
+		return
+	}
+
+	state, err := utils.TokenAcak()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login SSO"})
+		return
+	}
+	nonce, err := utils.TokenAcak()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login SSO"})
+		return
+	}
+	req := h.SSO.Mulai(state, nonce)
+
+	now := time.Now()
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("kedaluwarsa_pada < ?", now).Delete(&models.SesiSSO{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.SesiSSO{
+			State:           utils.HashToken(req.State),
+			Verifier:        req.Verifier,
+			Nonce:           req.Nonce,
+			KedaluwarsaPada: now.Add(h.Config.SSO.MasaSesi),
+		}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memulai login SSO"})
+		return
+	}
+
+	h.pasangCookieSSO(c, req.State, int(h.Config.SSO.MasaSesi.Seconds()))
+	c.Redirect(http.StatusFound, req.URL)
+}
+
+// cookieSSO mengikat state ke browser yang memulai login, sehingga callback
+// dengan state milik orang lain (login CSRF) ditolak.
+const cookieSSO = "sso_state"
+
+// pasangCookieSSO menulis cookie state untuk path callback; umur negatif
+// menghapusnya. SameSite Lax tetap mengirim cookie saat penyedia identitas
+// mengarahkan browser kembali dengan GET.
+func (h *Handler) pasangCookieSSO(c *gin.Context, nilai string, umur int) {
+	path, aman := "/", false
+	if u, err := url.Parse(h.Config.SSO.Klien.RedirectURL); err == nil {
+		if u.Path != "" {
+			path = u.Path
+		}
+		aman = u.Scheme == "https"
+	}
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(cookieSSO, nilai, umur, path, "", aman, true)
+}
+
+// GET /sso/callback
+// Hasil login dikirim ke frontend lewat fragment URL agar token tidak
+// tercatat di log server.
+func (h *Handler) SSOCallback(c *gin.Context) {
+	if h.SSO == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Login SSO tidak diaktifkan"})
+		return
+	}
+	cookie, _ := c.Cookie(cookieSSO)
+	h.pasangCookieSSO(c, "", -1)
+	if c.Query("error") != "" {
+		h.redirectSSO(c, url.Values{"error": {"sso_dibatalkan"}})
+		return
+	}
+	state := c.Query("state")
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		h.redirectSSO(c, url.Values{"error": {"sso_tidak_valid"}})
+		return
+	}
+
+	// State dipakai sekali; penghapusan atomik menolak callback ganda
+	var sesi models.SesiSSO
+	hash := utils.HashToken(state)
+	if err := h.DB.Where("state = ?", hash).First(&sesi).Error; err != nil {
+		h.redirectSSO(c, url.Values{"error": {"sso_tidak_valid"}})
+		return
+	}
+	res := h.DB.Where("id = ?", sesi.ID).Delete(&models.SesiSSO{})
+	if res.Error != nil || res.RowsAffected == 0 || time.Now().After(sesi.KedaluwarsaPada) {
+		h.redirectSSO(c, url.Values{"error": {"sso_tidak_valid"}})
+		return
+	}
+
+	id, err := h.SSO.Tukar(c.Request.Context(), c.Query("code"), sesi.Verifier, sesi.Nonce)
+	if errors.Is(err, sso.ErrEmailBelumDiverifikasi) {
+		h.redirectSSO(c, url.Values{"error": {"email_belum_diverifikasi"}})
+		return
+	}
+	if err != nil {
+		log.Println("Login SSO gagal:", err)
+		h.redirectSSO(c, url.Values{"error": {"sso_gagal"}})
+		return
+	}
+
+	user, err := h.userDariIdentitas(id)
+	if errors.Is(err, errAkunSSODihapus) {
+		h.redirectSSO(c, url.Values{"error": {"akun_dihapus"}})
+		return
+	}
+	if err != nil {
+		log.Println("Gagal memetakan user SSO:", err)
+		h.redirectSSO(c, url.Values{"error": {"sso_gagal"}})
+		return
+	}
+	if user.Status != "aktif" {
+		h.redirectSSO(c, url.Values{"error": {kodeAkunTidakAktif(user)}})
+		return
+	}
+
+	token, err := h.terbitkanToken(c, h.DB, user, "")
+	if err != nil {
+		h.redirectSSO(c, url.Values{"error": {"sso_gagal"}})
+		return
+	}
+	h.redirectSSO(c, url.Values{
+		"token":         {token.Token},
+		"refresh_token": {token.RefreshToken},
+		"expires_in":    {strconv.Itoa(token.ExpiresIn)},
+	})
+}
+
+var errAkunSSODihapus = errors.New("akun dengan email ini ada di tempat sampah")
+
+// userDariIdentitas mencari user berdasarkan email lalu NIM. User yang belum
+// terdaftar dibuat dengan status menunggu aktivasi admin.
+func (h *Handler) userDariIdentitas(id sso.Identitas) (models.User, error) {
+	var user models.User
+	if id.Email != "" {
+		err := h.DB.Where("email = ?", id.Email).First(&user).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, err
+		}
+	}
+	if id.NIM != "" {
+		err := h.DB.Where("nim = ?", id.NIM).First(&user).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return user, err
+		}
+	}
+	if id.Email == "" {
+		return user, errors.New("identitas SSO tanpa email tidak dapat didaftarkan")
+	}
+	// Akun yang dihapus tidak dibuat ulang; admin dapat memulihkannya
+	if b, err := cariBentrokUnik(h.DB, &models.User{}, "email", id.Email, 0); err != nil {
+		return user, err
+	} else if b != nil {
+		return user, errAkunSSODihapus
+	}
+
+	// Password acak: akun SSO tidak bisa login dengan password sampai user
+	// mengatur ulang lewat lupa password
+	acak, err := utils.TokenAcak()
+	if err != nil {
+		return user, err
+	}
+	hash, err := utils.HashPassword(acak, h.Config.BcryptCost)
+	if err != nil {
+		return user, err
+	}
+
+	user = models.User{
+		Nama:     id.Nama,
+		Email:    id.Email,
+		Password: hash,
+		Role:     "asisten",
+		Status:   "non-aktif",
+	}
+	if user.Nama == "" {
+		user.Nama = id.Email
+	}
+	if id.NIM != "" {
+		user.NIM = &id.NIM
+	}
+	return user, h.DB.Create(&user).Error
+}
+
+func (h *Handler) redirectSSO(c *gin.Context, nilai url.Values) {
+	c.Redirect(http.StatusFound, h.Config.FrontendURL+"/sso/callback#"+nilai.Encode())
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/rekapitulasi"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var kodeTarifRegex = regexp.MustCompile(`^[A-Z0-9]{1,10}$`)
+
+var errTarifTerkunci = errors.New("tarif sudah dipakai periode yang ditutup")
+
+type TarifHonorInput struct {
+	Kode         string `json:"kode" binding:"required"`
+	Nominal      int    `json:"nominal" binding:"required,gt=0"`
+	BerlakuMulai string `json:"berlaku_mulai" binding:"required"` // format: "2006-01-02"
+	Keterangan   string `json:"keterangan"`
+}
+
+func kodeTarifAda(db *gorm.DB, kode string) bool {
+	var jumlah int64
+	db.Model(&models.TarifHonor{}).Where("kode = ?", kode).Count(&jumlah)
+	return jumlah > 0
+}
+
+// hitungUlangHonorKode menghitung ulang honor semua rekapitulasi pada periode
+// terbuka yang memakai kode tarif tertentu.
+func (h *Handler) hitungUlangHonorKode(tx *gorm.DB, kode string) error {
+	var rekapList []models.Rekapitulasi
+	if err := tx.Joins("JOIN periode ON periode.id = rekapitulasi.periode_id").
+		Where("rekapitulasi.tipe_honor = ? AND periode.ditutup = ?", kode, false).
+		Find(&rekapList).Error; err != nil {
+		return err
+	}
+	for i := range rekapList {
+		if err := rekapitulasi.Susun(tx, h.Config.Honor, &rekapList[i]); err != nil {
+			return err
+		}
+		if err := tx.Save(&rekapList[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tarifMenyentuhPeriodeDitutup mengecek apakah tarif yang berlaku mulai
+// tanggal tersebut akan ikut menentukan honor periode yang sudah dibekukan.
+func tarifMenyentuhPeriodeDitutup(db *gorm.DB, berlakuMulai time.Time) bool {
+	var jumlah int64
+	db.Model(&models.Periode{}).Where("ditutup = ? AND tanggal_selesai >= ?", true, berlakuMulai).Count(&jumlah)
+	return jumlah > 0
+}
+
+func (input TarifHonorInput) toModel() (models.TarifHonor, error) {
+	kode := strings.ToUpper(strings.TrimSpace(input.Kode))
+	if !kodeTarifRegex.MatchString(kode) {
+		return models.TarifHonor{}, errors.New("Kode tarif hanya boleh huruf/angka, maksimal 10 karakter")
+	}
+	berlaku, err := time.Parse(formatTanggal, input.BerlakuMulai)
+	if err != nil {
+		return models.TarifHonor{}, errors.New("Format berlaku_mulai harus YYYY-MM-DD")
+	}
+	return models.TarifHonor{
+		Kode:         kode,
+		Nominal:      input.Nominal,
+		BerlakuMulai: berlaku,
+		Keterangan:   input.Keterangan,
+	}, nil
+}
+
+func (h *Handler) GetAllTarifHonor(c *gin.Context) {
+	query := h.DB.Order("kode, berlaku_mulai DESC")
+	if kode := c.Query("kode"); kode != "" {
+		query = query.Where("kode = ?", strings.ToUpper(kode))
+	}
+
+	var list []models.TarifHonor
+	if err := query.Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal mengambil data tarif honor"})
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// POST /admin/tarif-honor
+// Perubahan tarif dilakukan dengan menambah tarif baru dengan tanggal
+// berlaku_mulai yang baru, bukan mengubah tarif lama.
+func (h *Handler) CreateTarifHonor(c *gin.Context) {
+	var input TarifHonorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
+		return
+	}
+
+	tarif, err := input.toModel()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if tarifMenyentuhPeriodeDitutup(tx, tarif.BerlakuMulai) {
+			return errTarifTerkunci
+		}
+		if err := tx.Create(&tarif).Error; err != nil {
+			return err
+		}
+		if err := h.hitungUlangHonorKode(tx, tarif.Kode); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "tarif_honor", tarif.ID, "buat", nil, tarif)
+	})
+	if errors.Is(err, errTarifTerkunci) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tanggal berlaku jatuh pada periode yang sudah ditutup"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menyimpan tarif, pastikan kode dan tanggal berlaku belum ada"})
+		return
+	}
+	c.JSON(http.StatusCreated, tarif)
+}
+
+func (h *Handler) UpdateTarifHonor(c *gin.Context) {
+	var tarif models.TarifHonor
+	if err := h.DB.First(&tarif, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarif honor tidak ditemukan"})
+		return
+	}
+
+	var input TarifHonorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Input tidak valid", "detail": err.Error()})
+		return
+	}
+	updated, err := input.toModel()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sebelum := tarif
+	kodeLama := tarif.Kode
+	err = h.DB.Transaction(func(tx *gorm.DB) error {
+		if tarifMenyentuhPeriodeDitutup(tx, tarif.BerlakuMulai) || tarifMenyentuhPeriodeDitutup(tx, updated.BerlakuMulai) {
+			return errTarifTerkunci
+		}
+		tarif.Kode = updated.Kode
+		tarif.Nominal = updated.Nominal
+		tarif.BerlakuMulai = updated.BerlakuMulai
+		tarif.Keterangan = updated.Keterangan
+		if err := tx.Save(&tarif).Error; err != nil {
+			return err
+		}
+		if kodeLama != tarif.Kode {
+			if err := h.hitungUlangHonorKode(tx, kodeLama); err != nil {
+				return err
+			}
+		}
+		if err := h.hitungUlangHonorKode(tx, tarif.Kode); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "tarif_honor", tarif.ID, "ubah", sebelum, tarif)
+	})
+	if errors.Is(err, errTarifTerkunci) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tarif sudah dipakai periode yang ditutup, tambahkan tarif baru"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui tarif honor"})
+		return
+	}
+	c.JSON(http.StatusOK, tarif)
+}
+
+func (h *Handler) DeleteTarifHonor(c *gin.Context) {
+	var tarif models.TarifHonor
+	if err := h.DB.First(&tarif, c.Param("id")).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Tarif honor tidak ditemukan"})
+		return
+	}
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if tarifMenyentuhPeriodeDitutup(tx, tarif.BerlakuMulai) {
+			return errTarifTerkunci
+		}
+		if err := tx.Delete(&tarif).Error; err != nil {
+			return err
+		}
+		if err := h.hitungUlangHonorKode(tx, tarif.Kode); err != nil {
+			return err
+		}
+		return catatAudit(c, tx, "tarif_honor", tarif.ID, "hapus", tarif, nil)
+	})
+	if errors.Is(err, errTarifTerkunci) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Tarif sudah dipakai periode yang ditutup dan tidak dapat dihapus"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menghapus tarif honor"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tarif honor berhasil dihapus"})
+}
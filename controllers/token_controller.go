@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+	"forum_asisten/models"
+	"forum_asisten/utils"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var errRefreshTidakValid = errors.New("refresh token tidak valid")
+
+type pasanganToken struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"` // umur access token, detik
+}
+
+// terbitkanToken membuat access token dan refresh token baru untuk user.
+// keluarga kosong berarti sesi login baru.
+func (h *Handler) terbitkanToken(c *gin.Context, tx *gorm.DB, user models.User, keluarga string) (pasanganToken, error) {
+	masa := h.Config.Token
+
+	nim := ""
+	if user.NIM != nil {
+		nim = *user.NIM
+	}
+	akses, err := h.JWT.Generate(user.ID, user.Email, user.Nama, nim, user.Role, user.VersiToken, masa.Akses)
+	if err != nil {
+		return pasanganToken{}, err
+	}
+
+	refresh, err := utils.TokenAcak()
+	if err != nil {
+		return pasanganToken{}, err
+	}
+	if keluarga == "" {
+		if keluarga, err = utils.TokenAcak(); err != nil {
+			return pasanganToken{}, err
+		}
+	}
+
+	rt := models.RefreshToken{
+		UserID:          user.ID,
+		Hash:            utils.HashToken(refresh),
+		Keluarga:        keluarga,
+		KedaluwarsaPada: time.Now().Add(masa.Refresh),
+		UserAgent:       c.Request.UserAgent(),
+		IP:              c.ClientIP(),
+	}
+	if err := tx.Create(&rt).Error; err != nil {
+		return pasanganToken{}, err
+	}
+
+	return pasanganToken{
+		Token:        akses,
+		RefreshToken: refresh,
+		ExpiresIn:    int(masa.Akses.Seconds()),
+	}, nil
+}
+
+// cabutSemuaToken menggugurkan semua access token dan refresh token user,
+// dipakai saat status, password, atau keberadaan user berubah.
+func cabutSemuaToken(tx *gorm.DB, userID uint) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).
+		UpdateColumn("versi_token", gorm.Expr("versi_token + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND dicabut_pada IS NULL", userID).
+		Update("dicabut_pada", time.Now()).Error
+}
+
+func cabutKeluargaToken(tx *gorm.DB, keluarga string) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("keluarga = ? AND dicabut_pada IS NULL", keluarga).
+		Update("dicabut_pada", time.Now()).Error
+}
+
+// POST /refresh
+// Refresh token hanya dapat dipakai sekali. Pemakaian ulang token lama
+// dianggap pencurian dan mencabut seluruh sesi turunannya.
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var input struct {
+		RefreshToken string `json:"refresh_token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token wajib diisi"})
+		return
+	}
+
+	var rt models.RefreshToken
+	if err := h.DB.Where("hash = ?", utils.HashToken(input.RefreshToken)).First(&rt).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token tidak valid"})
+		return
+	}
+	if rt.DipakaiPada != nil || rt.DicabutPada != nil {
+		cabutKeluargaToken(h.DB, rt.Keluarga)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesi sudah berakhir, silakan login ulang"})
+		return
+	}
+	if time.Now().After(rt.KedaluwarsaPada) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token kedaluwarsa, silakan login ulang"})
+		return
+	}
+
+	var hasil pasanganToken
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		// Tandai terpakai secara atomik agar dua permintaan bersamaan tidak
+		// sama-sama mendapat token baru
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND dipakai_pada IS NULL AND dicabut_pada IS NULL", rt.ID).
+			Update("dipakai_pada", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return errRefreshTidakValid
+		}
+
+		var user models.User
+		if err := tx.First(&user, rt.UserID).Error; err != nil || user.Status != "aktif" {
+			return errRefreshTidakValid
+		}
+
+		var err error
+		hasil, err = h.terbitkanToken(c, tx, user, rt.Keluarga)
+		return err
+	})
+	if errors.Is(err, errRefreshTidakValid) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesi sudah berakhir, silakan login ulang"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal memperbarui token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hasil)
+}
+
+// POST /logout
+// Mencabut access token yang sedang dipakai dan sesi refresh token-nya.
+// Dengan "semua": true, semua sesi user di perangkat lain ikut dicabut.
+func (h *Handler) Logout(c *gin.Context) {
+	userID, ok := ambilUserID(c)
+	if !ok {
+		return
+	}
+
+	var input struct {
+		RefreshToken string `json:"refresh_token"`
+		Semua        bool   `json:"semua"`
+	}
+	// Body opsional
+	_ = c.ShouldBindJSON(&input)
+
+	err := h.DB.Transaction(func(tx *gorm.DB) error {
+		if input.Semua {
+			return cabutSemuaToken(tx, userID)
+		}
+
+		// Bersihkan daftar cabut dari token yang sudah kedaluwarsa
+		now := time.Now()
+		if err := tx.Where("kedaluwarsa_pada < ?", now).Delete(&models.TokenDicabut{}).Error; err != nil {
+			return err
+		}
+
+		exp, _ := c.Get("exp")
+		expUnix, _ := exp.(float64)
+		if err := tx.Create(&models.TokenDicabut{
+			JTI:             c.GetString("jti"),
+			UserID:          userID,
+			KedaluwarsaPada: time.Unix(int64(expUnix), 0),
+		}).Error; err != nil {
+			return err
+		}
+
+		if input.RefreshToken == "" {
+			return nil
+		}
+		var rt models.RefreshToken
+		if err := tx.Where("hash = ? AND user_id = ?", utils.HashToken(input.RefreshToken), userID).First(&rt).Error; err != nil {
+			return nil
+		}
+		return cabutKeluargaToken(tx, rt.Keluarga)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal logout"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout berhasil"})
+}
@@ -2,10 +2,15 @@ import React, { lazy, Suspense } from 'react'
 import ReactDOM from 'react-dom/client'
 import { createBrowserRouter, RouterProvider } from 'react-router-dom'
 import './index.css'
+import { pasangRefreshOtomatis } from './services/auth'
+
+pasangRefreshOtomatis()
 
 // Public Pages
 const LoginPage = lazy(() => import('./pages/LoginPage'))
 const RegisterPage = lazy(() => import('./pages/RegisterPage'))
+const SSOCallbackPage = lazy(() => import('./pages/SSOCallbackPage'))
+const KonfirmasiEmailPage = lazy(() => import('./pages/KonfirmasiEmailPage'))
 const HomePage = lazy(() => import('./pages/HomePage'))
 const ProfilePage = lazy(() => import('./pages/ProfilePage'))
 const JadwalAsistenPage = lazy(() => import('./pages/JadwalAsistenPage'))
@@ -39,6 +44,16 @@ const router = createBrowserRouter([
     element: <RegisterPage />,
     errorElement: <ErrorPage />
   },
+  {
+    path: '/sso/callback',
+    element: <SSOCallbackPage />,
+    errorElement: <ErrorPage />
+  },
+  {
+    path: '/konfirmasi-email',
+    element: <KonfirmasiEmailPage />,
+    errorElement: <ErrorPage />
+  },
   {
     path: '*',
     element: <NotFoundPage />,
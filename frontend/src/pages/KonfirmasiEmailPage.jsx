@@ -0,0 +1,53 @@
+import React, { useEffect, useState } from "react";
+import axios from "axios";
+
+// Tautan di email konfirmasi (dari PUT /api/me) membuka halaman ini dengan
+// token di query string; email akun baru diganti setelah token dikirim balik.
+export default function KonfirmasiEmailPage() {
+  const [hasil, setHasil] = useState({ selesai: false, berhasil: false, pesan: "" });
+
+  useEffect(() => {
+    const token = new URLSearchParams(window.location.search).get("token");
+    window.history.replaceState(null, "", window.location.pathname);
+
+    axios
+      .post(`${import.meta.env.VITE_REACT_APP_BASEURL}/api/email/konfirmasi`, { token })
+      .then((res) => {
+        const user = JSON.parse(localStorage.getItem("user") || "null");
+        if (user) {
+          localStorage.setItem("user", JSON.stringify({ ...user, email: res.data.email }));
+        }
+        setHasil({ selesai: true, berhasil: true, pesan: `Email akun Anda kini ${res.data.email}.` });
+      })
+      .catch((err) =>
+        setHasil({
+          selesai: true,
+          berhasil: false,
+          pesan: err.response?.data?.error || "Konfirmasi email gagal. Silakan coba lagi.",
+        })
+      );
+  }, []);
+
+  return (
+    <div className="w-screen h-screen flex items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 p-4">
+      <div className="w-full max-w-md bg-white rounded-2xl shadow-xl p-6 text-center">
+        {hasil.selesai ? (
+          <>
+            <h2 className={`text-xl font-bold mb-4 ${hasil.berhasil ? "text-green-600" : "text-red-600"}`}>
+              {hasil.berhasil ? "Email Dikonfirmasi" : "Konfirmasi Gagal"}
+            </h2>
+            <p className="text-gray-700 mb-6">{hasil.pesan}</p>
+            <a
+              href="/"
+              className="inline-block w-full py-2 rounded-lg font-medium text-white bg-blue-500 hover:bg-blue-600 transition duration-200"
+            >
+              Kembali ke halaman login
+            </a>
+          </>
+        ) : (
+          <p className="text-gray-700">Memproses konfirmasi email...</p>
+        )}
+      </div>
+    </div>
+  );
+}
@@ -2,6 +2,7 @@ import React, { useState } from "react";
 import { useNavigate } from "react-router-dom";
 import axios from "axios";
 import logo from "../assets/logoFA.png";
+import { simpanSesi } from "../services/auth";
 
 export default function LoginPage() {
   const [identifier, setIdentifier] = useState("");
@@ -24,8 +25,8 @@ export default function LoginPage() {
         password,
       });
 
-      const { token, user } = res.data;
-      localStorage.setItem("token", token);
+      const { user } = res.data;
+      simpanSesi(res.data);
       localStorage.setItem("user", JSON.stringify(user));
 
       // Show success modal
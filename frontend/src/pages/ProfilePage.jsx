@@ -44,7 +44,7 @@ const [error, setError] = useState({ show: false, message: '' });
         
         // Fetch complete user data from API
         const response = await axios.get(
-          `${import.meta.env.VITE_REACT_APP_BASEURL}/api/users/${payload.user_id}`,
+          `${import.meta.env.VITE_REACT_APP_BASEURL}/api/me`,
           {
             headers: {
               'Authorization': `Bearer ${token}`
@@ -199,7 +199,7 @@ const [error, setError] = useState({ show: false, message: '' });
       });
   
       const response = await axios.put(
-        `${import.meta.env.VITE_REACT_APP_BASEURL}/api/users/${user.id}`,
+        `${import.meta.env.VITE_REACT_APP_BASEURL}/api/me`,
         formData,
         {
           headers: {
@@ -215,7 +215,7 @@ const [error, setError] = useState({ show: false, message: '' });
         setUser(prev => ({
           ...prev,
           nama: editData.nama,
-          email: editData.email,
+          email: response.data.data.email,
           nim: editData.nim,
           telepon: editData.telepon,
           photo: photoUrl
@@ -224,9 +224,13 @@ const [error, setError] = useState({ show: false, message: '' });
         setPhotoPreview(photoUrl);
         setIsEditing(false);
         
+        // Email baru berlaku setelah tautan konfirmasi dibuka
+        const menunggu = response.data.email_menunggu_konfirmasi;
         setSuccess({ 
           show: true, 
-          message: response.data.message 
+          message: menunggu
+            ? `${response.data.message}. Buka tautan yang dikirim ke ${menunggu} untuk mengganti email.`
+            : response.data.message
         });
         setError({ show: false, message: '' });
         
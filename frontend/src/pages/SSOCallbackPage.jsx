@@ -0,0 +1,66 @@
+import React, { useEffect, useState } from "react";
+import { useNavigate } from "react-router-dom";
+import axios from "axios";
+import { simpanSesi } from "../services/auth";
+
+// Backend (/api/sso/callback) mengarahkan ke halaman ini dengan hasil login
+// di fragment URL: token, refresh_token dan expires_in, atau error.
+const pesanError = {
+  sso_dibatalkan: "Login SSO dibatalkan.",
+  sso_tidak_valid: "Sesi login SSO tidak valid atau kedaluwarsa. Silakan ulangi dari halaman login.",
+  sso_gagal: "Login SSO gagal. Silakan coba lagi.",
+  email_belum_diverifikasi: "Email akun kampus Anda belum diverifikasi.",
+  menunggu_aktivasi: "Akun Anda menunggu aktivasi oleh Administrator.",
+  pendaftaran_ditolak: "Pendaftaran akun Anda ditolak.",
+  akun_nonaktif: "Akun Anda tidak aktif. Hubungi Administrator.",
+  akun_dihapus: "Akun Anda telah dihapus. Hubungi Administrator untuk memulihkannya.",
+};
+
+export default function SSOCallbackPage() {
+  const navigate = useNavigate();
+  const [error, setError] = useState("");
+
+  useEffect(() => {
+    const hasil = new URLSearchParams(window.location.hash.slice(1));
+    // Token tidak dibiarkan tersimpan di riwayat browser
+    window.history.replaceState(null, "", window.location.pathname);
+
+    if (hasil.get("error") || !hasil.get("token")) {
+      setError(pesanError[hasil.get("error")] || pesanError.sso_gagal);
+      return;
+    }
+
+    simpanSesi({ token: hasil.get("token"), refresh_token: hasil.get("refresh_token") });
+    axios
+      .get(`${import.meta.env.VITE_REACT_APP_BASEURL}/api/me`, {
+        headers: { Authorization: `Bearer ${hasil.get("token")}` },
+      })
+      .then((res) => {
+        const user = res.data.data;
+        localStorage.setItem("user", JSON.stringify(user));
+        navigate(user.role === "admin" ? "/admin/home" : "/home", { replace: true });
+      })
+      .catch(() => setError(pesanError.sso_gagal));
+  }, [navigate]);
+
+  return (
+    <div className="w-screen h-screen flex items-center justify-center bg-gradient-to-br from-blue-50 to-indigo-100 p-4">
+      <div className="w-full max-w-md bg-white rounded-2xl shadow-xl p-6 text-center">
+        {error ? (
+          <>
+            <h2 className="text-xl font-bold text-red-600 mb-4">Login Gagal</h2>
+            <p className="text-gray-700 mb-6">{error}</p>
+            <a
+              href="/"
+              className="inline-block w-full py-2 rounded-lg font-medium text-white bg-red-500 hover:bg-red-600 transition duration-200"
+            >
+              Kembali ke halaman login
+            </a>
+          </>
+        ) : (
+          <p className="text-gray-700">Memproses login SSO...</p>
+        )}
+      </div>
+    </div>
+  );
+}
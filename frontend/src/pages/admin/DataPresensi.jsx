@@ -648,6 +648,15 @@ export default function DataPresensi() {
                     </div>
                   )}
 
+                  {currentPresensi.keterangan && (
+                    <div>
+                      <h4 className="text-sm font-medium text-gray-500 mb-2">Keterangan</h4>
+                      <div className="bg-gray-50 p-4 rounded-lg h-full">
+                        <p className="text-gray-700">{currentPresensi.keterangan}</p>
+                      </div>
+                    </div>
+                  )}
+
                   <div>
                     <h4 className="text-sm font-medium text-gray-500 mb-2">
                       {currentPresensi.status === 'izin' ? 'Bukti Izin' : 'Bukti Kehadiran'}
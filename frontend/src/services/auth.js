@@ -0,0 +1,70 @@
+// src/services/auth.js
+import axios from 'axios';
+
+// Access token berumur pendek (JWT_AKSES_MENIT di backend). Saat API membalas
+// 401 untuk permintaan yang membawa token, token diperbarui sekali lewat
+// /api/refresh lalu permintaan diulang. Jika refresh gagal, sesi dihapus dan
+// pengguna diarahkan ke halaman login.
+
+const BASE_URL = import.meta.env.VITE_REACT_APP_BASEURL;
+
+export function simpanSesi({ token, refresh_token }) {
+  localStorage.setItem('token', token);
+  if (refresh_token) {
+    localStorage.setItem('refresh_token', refresh_token);
+  }
+}
+
+export function hapusSesi() {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refresh_token');
+  localStorage.removeItem('user');
+}
+
+// Refresh token hanya dapat dipakai sekali, jadi beberapa permintaan yang
+// gagal bersamaan harus menunggu satu refresh yang sama.
+let refreshBerjalan = null;
+
+function perbaruiToken() {
+  if (!refreshBerjalan) {
+    const refreshToken = localStorage.getItem('refresh_token');
+    refreshBerjalan = (refreshToken
+      ? axios.post(`${BASE_URL}/api/refresh`, { refresh_token: refreshToken })
+      : Promise.reject(new Error('Tidak ada refresh token')))
+      .then((res) => {
+        simpanSesi(res.data);
+        return res.data.token;
+      })
+      .finally(() => {
+        refreshBerjalan = null;
+      });
+  }
+  return refreshBerjalan;
+}
+
+export function pasangRefreshOtomatis() {
+  axios.interceptors.response.use(
+    (response) => response,
+    async (error) => {
+      const config = error.config;
+      const authorization = config?.headers?.Authorization ?? config?.headers?.authorization;
+      // Login, refresh, dan permintaan yang sudah diulang tidak diperbarui lagi
+      if (error.response?.status !== 401 || !authorization || config._sudahDiulang) {
+        return Promise.reject(error);
+      }
+
+      try {
+        const token = await perbaruiToken();
+        config._sudahDiulang = true;
+        config.headers.Authorization = `Bearer ${token}`;
+        return axios(config);
+      } catch {
+        hapusSesi();
+        if (window.location.pathname !== '/') {
+          window.location.assign('/');
+        }
+        return Promise.reject(error);
+      }
+    }
+  );
+}
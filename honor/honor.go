@@ -0,0 +1,199 @@
+// Package honor menghitung honor asisten per presensi dengan rangkaian aturan
+// yang diterapkan berurutan. Setiap aturan yang mengubah nominal dicatat
+// sebagai langkah sehingga perhitungan dapat ditelusuri.
+package honor
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Sesi adalah satu presensi yang akan dihitung honornya.
+type Sesi struct {
+	PresensiID       uint
+	Tanggal          time.Time
+	Status           string // hadir, izin, alpha
+	Jenis            string // utama, pengganti
+	PertemuanKe      int
+	Durasi           time.Duration
+	FaktorMataKuliah float64
+}
+
+// Kunci menentukan kelompok faktor sesi: jenis untuk presensi hadir, status
+// untuk presensi lainnya.
+func (s Sesi) Kunci() string {
+	if s.Status == "hadir" {
+		return s.Jenis
+	}
+	return s.Status
+}
+
+type Langkah struct {
+	Aturan  string `json:"aturan"`
+	Nominal int    `json:"nominal"` // nominal setelah aturan diterapkan
+}
+
+type Rincian struct {
+	PresensiID uint      `json:"presensi_id"`
+	Tanggal    time.Time `json:"tanggal"`
+	Status     string    `json:"status"`
+	Jenis      string    `json:"jenis"`
+	Nominal    int       `json:"nominal"`
+	Langkah    []Langkah `json:"langkah"`
+}
+
+type Hasil struct {
+	Total        int       `json:"total"`
+	SebelumBatas int       `json:"sebelum_batas,omitempty"` // diisi jika total dipotong batas
+	Rincian      []Rincian `json:"rincian"`
+}
+
+// Aturan mengubah nominal honor satu sesi. Keterangan kosong berarti aturan
+// tidak berpengaruh dan tidak dicatat pada rincian.
+type Aturan interface {
+	Terapkan(s Sesi, nominal float64) (hasil float64, keterangan string)
+}
+
+// Mesin menerapkan daftar aturan pada setiap sesi lalu membatasi totalnya.
+type Mesin struct {
+	Aturan    []Aturan
+	MaksTotal int // 0 berarti tanpa batas
+}
+
+func (m Mesin) Hitung(sesi []Sesi) Hasil {
+	var hasil Hasil
+	for _, s := range sesi {
+		rincian := Rincian{
+			PresensiID: s.PresensiID,
+			Tanggal:    s.Tanggal,
+			Status:     s.Status,
+			Jenis:      s.Jenis,
+			Langkah:    []Langkah{},
+		}
+		var nominal float64
+		for _, a := range m.Aturan {
+			var ket string
+			nominal, ket = a.Terapkan(s, nominal)
+			if ket != "" {
+				rincian.Langkah = append(rincian.Langkah, Langkah{Aturan: ket, Nominal: bulatkan(nominal)})
+			}
+		}
+		rincian.Nominal = bulatkan(nominal)
+		hasil.Total += rincian.Nominal
+		hasil.Rincian = append(hasil.Rincian, rincian)
+	}
+
+	if m.MaksTotal > 0 && hasil.Total > m.MaksTotal {
+		hasil.SebelumBatas = hasil.Total
+		hasil.Total = m.MaksTotal
+	}
+	return hasil
+}
+
+func bulatkan(v float64) int {
+	return int(math.Round(v))
+}
+
+// TarifDasar mengisi nominal awal dari tarif yang berlaku pada tanggal sesi.
+type TarifDasar struct {
+	Tarif func(tanggal time.Time) int
+}
+
+func (a TarifDasar) Terapkan(s Sesi, _ float64) (float64, string) {
+	tarif := a.Tarif(s.Tanggal)
+	return float64(tarif), fmt.Sprintf("tarif dasar %d", tarif)
+}
+
+// FaktorJenis mengalikan nominal sesuai kunci sesi (utama, pengganti, izin,
+// alpha). Presensi hadir tanpa faktor dibayar penuh, selainnya tidak dibayar.
+type FaktorJenis map[string]float64
+
+func (a FaktorJenis) Terapkan(s Sesi, nominal float64) (float64, string) {
+	faktor, ok := a[s.Kunci()]
+	if !ok {
+		faktor = 0
+		if s.Status == "hadir" {
+			faktor = 1
+		}
+	}
+	if faktor == 1 {
+		return nominal, ""
+	}
+	return nominal * faktor, fmt.Sprintf("faktor %s x%g", s.Kunci(), faktor)
+}
+
+// FaktorDurasi menyesuaikan nominal dengan lama sesi dibanding durasi standar.
+type FaktorDurasi struct {
+	Standar time.Duration // 0 berarti durasi tidak diperhitungkan
+}
+
+func (a FaktorDurasi) Terapkan(s Sesi, nominal float64) (float64, string) {
+	if a.Standar <= 0 || s.Durasi <= 0 || s.Durasi == a.Standar {
+		return nominal, ""
+	}
+	faktor := float64(s.Durasi) / float64(a.Standar)
+	return nominal * faktor, fmt.Sprintf("durasi %g menit x%.2f", s.Durasi.Minutes(), faktor)
+}
+
+// FaktorMataKuliah memakai faktor honor yang diatur pada mata kuliah.
+type FaktorMataKuliah struct{}
+
+func (FaktorMataKuliah) Terapkan(s Sesi, nominal float64) (float64, string) {
+	if s.FaktorMataKuliah <= 0 || s.FaktorMataKuliah == 1 {
+		return nominal, ""
+	}
+	return nominal * s.FaktorMataKuliah, fmt.Sprintf("faktor mata kuliah x%g", s.FaktorMataKuliah)
+}
+
+// PertemuanLebih memberi faktor tersendiri untuk pertemuan di luar jumlah
+// pertemuan yang direncanakan.
+type PertemuanLebih struct {
+	Maks   int // 0 berarti tidak dibatasi
+	Faktor float64
+}
+
+func (a PertemuanLebih) Terapkan(s Sesi, nominal float64) (float64, string) {
+	if a.Maks <= 0 || s.PertemuanKe <= a.Maks || a.Faktor == 1 {
+		return nominal, ""
+	}
+	return nominal * a.Faktor, fmt.Sprintf("pertemuan ke-%d melebihi %d x%g", s.PertemuanKe, a.Maks, a.Faktor)
+}
+
+// BatasPerSesi membatasi nominal maksimum satu sesi.
+type BatasPerSesi struct {
+	Maks int // 0 berarti tanpa batas
+}
+
+func (a BatasPerSesi) Terapkan(_ Sesi, nominal float64) (float64, string) {
+	if a.Maks <= 0 || nominal <= float64(a.Maks) {
+		return nominal, ""
+	}
+	return float64(a.Maks), fmt.Sprintf("batas per sesi %d", a.Maks)
+}
+
+// Konfigurasi adalah parameter aturan bawaan.
+type Konfigurasi struct {
+	FaktorJenis   map[string]float64
+	DurasiStandar time.Duration
+	MaksPertemuan int
+	FaktorLebih   float64
+	MaksPerSesi   int
+	MaksTotal     int
+}
+
+// Standar menyusun mesin dengan urutan aturan bawaan: tarif dasar, faktor
+// jenis, durasi, mata kuliah, pertemuan lebih, lalu batas per sesi.
+func Standar(tarif func(time.Time) int, k Konfigurasi) Mesin {
+	return Mesin{
+		Aturan: []Aturan{
+			TarifDasar{Tarif: tarif},
+			FaktorJenis(k.FaktorJenis),
+			FaktorDurasi{Standar: k.DurasiStandar},
+			FaktorMataKuliah{},
+			PertemuanLebih{Maks: k.MaksPertemuan, Faktor: k.FaktorLebih},
+			BatasPerSesi{Maks: k.MaksPerSesi},
+		},
+		MaksTotal: k.MaksTotal,
+	}
+}
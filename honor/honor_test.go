@@ -0,0 +1,141 @@
+package honor
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var (
+	tarifLama   = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tarifBaru   = time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)
+	sebelumBaru = time.Date(2025, 9, 8, 0, 0, 0, 0, time.UTC)
+)
+
+// tarifUji: 40.000 sebelum 15 September 2025, 50.000 sejak itu.
+func tarifUji(tanggal time.Time) int {
+	if tanggal.Before(tarifBaru) {
+		return 40000
+	}
+	return 50000
+}
+
+func TestAturan(t *testing.T) {
+	t.Parallel()
+	hadir := Sesi{Status: "hadir", Jenis: "utama", Tanggal: tarifBaru, PertemuanKe: 3, Durasi: 100 * time.Minute}
+
+	tests := []struct {
+		nama    string
+		aturan  Aturan
+		sesi    Sesi
+		nominal float64
+		want    float64
+		ket     string
+	}{
+		{"tarif dasar sebelum tarif baru", TarifDasar{Tarif: tarifUji}, Sesi{Tanggal: sebelumBaru}, 0, 40000, "tarif dasar 40000"},
+		{"tarif dasar tepat saat tarif baru berlaku", TarifDasar{Tarif: tarifUji}, Sesi{Tanggal: tarifBaru}, 0, 50000, "tarif dasar 50000"},
+
+		{"hadir tanpa faktor dibayar penuh", FaktorJenis{}, hadir, 50000, 50000, ""},
+		{"izin tanpa faktor tidak dibayar", FaktorJenis{}, Sesi{Status: "izin", Jenis: "utama"}, 50000, 0, "faktor izin x0"},
+		{"faktor pengganti", FaktorJenis{"pengganti": 1.5}, Sesi{Status: "hadir", Jenis: "pengganti"}, 50000, 75000, "faktor pengganti x1.5"},
+		{"faktor izin memakai status, bukan jenis", FaktorJenis{"utama": 2, "izin": 0.5}, Sesi{Status: "izin", Jenis: "utama"}, 50000, 25000, "faktor izin x0.5"},
+		{"faktor 1 tidak dicatat", FaktorJenis{"utama": 1}, hadir, 50000, 50000, ""},
+
+		{"durasi tidak diperhitungkan", FaktorDurasi{}, hadir, 50000, 50000, ""},
+		{"durasi sama dengan standar", FaktorDurasi{Standar: 100 * time.Minute}, hadir, 50000, 50000, ""},
+		{"durasi lebih pendek", FaktorDurasi{Standar: 200 * time.Minute}, hadir, 50000, 25000, "durasi 100 menit x0.50"},
+		{"durasi sesi tidak diketahui", FaktorDurasi{Standar: 200 * time.Minute}, Sesi{}, 50000, 50000, ""},
+
+		{"faktor mata kuliah kosong", FaktorMataKuliah{}, hadir, 50000, 50000, ""},
+		{"faktor mata kuliah", FaktorMataKuliah{}, Sesi{FaktorMataKuliah: 1.2}, 50000, 60000, "faktor mata kuliah x1.2"},
+
+		{"pertemuan dalam rencana", PertemuanLebih{Maks: 3, Faktor: 0.5}, hadir, 50000, 50000, ""},
+		{"pertemuan melebihi rencana", PertemuanLebih{Maks: 2, Faktor: 0.5}, hadir, 50000, 25000, "pertemuan ke-3 melebihi 2 x0.5"},
+		{"pertemuan tanpa batas rencana", PertemuanLebih{Faktor: 0.5}, hadir, 50000, 50000, ""},
+
+		{"di bawah batas per sesi", BatasPerSesi{Maks: 60000}, hadir, 50000, 50000, ""},
+		{"tepat batas per sesi", BatasPerSesi{Maks: 50000}, hadir, 50000, 50000, ""},
+		{"melebihi batas per sesi", BatasPerSesi{Maks: 45000}, hadir, 50000, 45000, "batas per sesi 45000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			got, ket := tt.aturan.Terapkan(tt.sesi, tt.nominal)
+			if got != tt.want || ket != tt.ket {
+				t.Errorf("Terapkan() = %g, %q; want %g, %q", got, ket, tt.want, tt.ket)
+			}
+		})
+	}
+}
+
+func TestMesinHitung(t *testing.T) {
+	t.Parallel()
+	sesi := []Sesi{
+		{PresensiID: 1, Tanggal: sebelumBaru, Status: "hadir", Jenis: "utama", PertemuanKe: 1},
+		{PresensiID: 2, Tanggal: tarifBaru, Status: "hadir", Jenis: "pengganti", PertemuanKe: 2},
+		{PresensiID: 3, Tanggal: tarifBaru, Status: "izin", Jenis: "utama", PertemuanKe: 3},
+		{PresensiID: 4, Tanggal: tarifBaru, Status: "alpha", Jenis: "utama", PertemuanKe: 4},
+	}
+
+	tests := []struct {
+		nama    string
+		k       Konfigurasi
+		nominal []int
+		total   int
+		sebelum int
+	}{
+		{"bawaan: hadir penuh, izin dan alpha tidak dibayar", Konfigurasi{}, []int{40000, 50000, 0, 0}, 90000, 0},
+		{"izin dibayar setengah", Konfigurasi{FaktorJenis: map[string]float64{"izin": 0.5}}, []int{40000, 50000, 25000, 0}, 115000, 0},
+		{"pertemuan lebih lalu batas per sesi", Konfigurasi{
+			FaktorJenis:   map[string]float64{"izin": 1, "alpha": 1},
+			MaksPertemuan: 2,
+			FaktorLebih:   1.5,
+			MaksPerSesi:   60000,
+		}, []int{40000, 50000, 60000, 60000}, 210000, 0},
+		{"total dibatasi", Konfigurasi{MaksTotal: 80000}, []int{40000, 50000, 0, 0}, 80000, 90000},
+		{"total tepat pada batas tidak dicatat", Konfigurasi{MaksTotal: 90000}, []int{40000, 50000, 0, 0}, 90000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			hasil := Standar(tarifUji, tt.k).Hitung(sesi)
+			var nominal []int
+			for i, r := range hasil.Rincian {
+				nominal = append(nominal, r.Nominal)
+				if r.PresensiID != sesi[i].PresensiID || r.Status != sesi[i].Status || r.Jenis != sesi[i].Jenis {
+					t.Errorf("rincian %d = %+v, tidak sesuai sesi %+v", i, r, sesi[i])
+				}
+			}
+			if !reflect.DeepEqual(nominal, tt.nominal) {
+				t.Errorf("nominal per sesi = %v, want %v", nominal, tt.nominal)
+			}
+			if hasil.Total != tt.total || hasil.SebelumBatas != tt.sebelum {
+				t.Errorf("total = %d (sebelum batas %d), want %d (%d)", hasil.Total, hasil.SebelumBatas, tt.total, tt.sebelum)
+			}
+		})
+	}
+}
+
+// TestMesinLangkah memastikan hanya aturan yang berpengaruh tercatat, dengan
+// nominal setelah aturan tersebut, dan nominal dibulatkan ke rupiah terdekat.
+func TestMesinLangkah(t *testing.T) {
+	t.Parallel()
+	m := Standar(func(time.Time) int { return 40001 }, Konfigurasi{
+		FaktorJenis:   map[string]float64{"pengganti": 0.5},
+		DurasiStandar: 100 * time.Minute,
+	})
+	hasil := m.Hitung([]Sesi{{Tanggal: tarifLama, Status: "hadir", Jenis: "pengganti", Durasi: 100 * time.Minute}})
+
+	want := []Langkah{
+		{Aturan: "tarif dasar 40001", Nominal: 40001},
+		{Aturan: "faktor pengganti x0.5", Nominal: 20001}, // 20000,5 dibulatkan ke atas
+	}
+	if len(hasil.Rincian) != 1 || !reflect.DeepEqual(hasil.Rincian[0].Langkah, want) {
+		t.Fatalf("langkah = %+v, want %+v", hasil.Rincian, want)
+	}
+	if hasil.Total != 20001 {
+		t.Errorf("total = %d, want 20001", hasil.Total)
+	}
+
+	if kosong := m.Hitung(nil); kosong.Total != 0 || len(kosong.Rincian) != 0 {
+		t.Errorf("tanpa sesi = %+v", kosong)
+	}
+}
@@ -0,0 +1,36 @@
+// Package mailer mengirim email transaksional (mis. tautan reset password)
+// lewat SMTP, atau menampungnya di outbox untuk pengembangan dan pengujian.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Pesan adalah email teks sederhana.
+type Pesan struct {
+	Kepada string
+	Subjek string
+	Isi    string
+}
+
+type Mailer interface {
+	Kirim(ctx context.Context, p Pesan) error
+}
+
+// format menyusun pesan menjadi email RFC 5322 berisi teks UTF-8.
+func format(dari string, p Pesan) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", dari)
+	fmt.Fprintf(&b, "To: %s\r\n", p.Kepada)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", p.Subjek))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(p.Isi, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Memori menampung pesan di memori; dipakai pada pengujian.
+type Memori struct {
+	mu    sync.Mutex
+	pesan []Pesan
+}
+
+func NewMemori() *Memori {
+	return &Memori{}
+}
+
+func (m *Memori) Kirim(_ context.Context, p Pesan) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pesan = append(m.pesan, p)
+	return nil
+}
+
+// Pesan mengembalikan salinan semua pesan yang sudah "terkirim".
+func (m *Memori) Pesan() []Pesan {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Pesan(nil), m.pesan...)
+}
+
+// File menulis setiap pesan sebagai berkas .eml pada sebuah direktori;
+// dipakai saat pengembangan tanpa server SMTP.
+type File struct {
+	dir  string
+	dari string
+	mu   sync.Mutex
+	n    int
+}
+
+func NewFile(dir, dari string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("gagal membuat direktori outbox: %w", err)
+	}
+	return &File{dir: dir, dari: dari}, nil
+}
+
+func (f *File) Kirim(_ context.Context, p Pesan) error {
+	f.mu.Lock()
+	f.n++
+	nama := fmt.Sprintf("%s-%03d.eml", time.Now().Format("20060102-150405"), f.n)
+	f.mu.Unlock()
+	return os.WriteFile(filepath.Join(f.dir, nama), format(f.dari, p), 0o600)
+}
@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	Dari     string // alamat pengirim
+}
+
+// SMTP mengirim email ke server SMTP. STARTTLS dipakai otomatis jika
+// didukung server.
+type SMTP struct {
+	cfg SMTPConfig
+}
+
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" || cfg.Dari == "" {
+		return nil, fmt.Errorf("SMTP host dan alamat pengirim wajib diisi")
+	}
+	if cfg.Port == "" {
+		cfg.Port = "587"
+	}
+	return &SMTP{cfg: cfg}, nil
+}
+
+func (s *SMTP) Kirim(_ context.Context, p Pesan) error {
+	if strings.ContainsAny(p.Kepada, "\r\n") {
+		return fmt.Errorf("alamat tujuan tidak valid")
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	return smtp.SendMail(addr, auth, s.cfg.Dari, []string{p.Kepada}, format(s.cfg.Dari, p))
+}
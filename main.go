@@ -1,76 +1,113 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
-	"strings"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"forum_asisten/app"
 	"forum_asisten/config"
+	"forum_asisten/migrasi"
 	"forum_asisten/routes"
+	"forum_asisten/versi"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	// Load environment variables from .env (for development)
-	// In production, these should be set in the environment
-	_ = godotenv.Load()
+	// Configuration comes from the environment, .env and an optional YAML
+	// file (see config.Load); malformed values stop startup immediately
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Konfigurasi tidak valid:\n", err)
+	}
+
+	// Subcommand migrate: kelola skema database lalu keluar
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := cfg.DB.Validasi(); err != nil {
+			log.Fatal("Konfigurasi tidak valid:\n", err)
+		}
+		db, err := config.ConnectDB(cfg.DB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migrasi.Jalankan(db, os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Refuse to start with missing or unsafe settings, then log what is
+	// in effect with secrets redacted
+	if err := cfg.Validasi(); err != nil {
+		log.Fatal("Konfigurasi tidak valid:\n", err)
+	}
+	log.Print(cfg.Ringkasan())
 
-	// Initialize database
-	config.InitDB()
+	// Build the application: database (refuses to start on a pending
+	// schema), file storage, email delivery and optional OIDC login
+	a, err := app.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// Set up Gin router
 	r := gin.Default()
 
-	// Get allowed origins from environment
-	allowedOrigins := getOriginsFromEnv()
-
 	// CORS configuration
 	r.Use(cors.New(cors.Config{
-		AllowOrigins:     allowedOrigins,
+		AllowOrigins:     cfg.AllowedOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 	}))
 
-	routes.SetupRoutes(r)
+	routes.SetupRoutes(r, a)
 
-	// Get port from environment or use default
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	srv := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           r,
+		ReadHeaderTimeout: cfg.HTTP.ReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTP.ReadTimeout,
+		WriteTimeout:      cfg.HTTP.WriteTimeout,
+		IdleTimeout:       cfg.HTTP.IdleTimeout,
 	}
 
-	// Run server
-	log.Printf("Server running on port %s", port)
-	log.Printf("Allowed origins: %v", allowedOrigins)
-	r.Run(":" + port)
-}
+	// Run server until SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-func getOriginsFromEnv() []string {
-	// Default origins for development
-	defaultOrigins := []string{
-		"http://localhost:5173", 
-		"http://localhost:5174",
-	}
+	info := versi.Baca()
+	log.Printf("Server running on port %s (versi %s, commit %s)", cfg.Port, info.Versi, info.Commit)
+	gagal := make(chan error, 1)
+	go func() { gagal <- srv.ListenAndServe() }()
 
-	// Get additional origins from environment
-	envOrigins := os.Getenv("ALLOWED_ORIGINS")
-	if envOrigins == "" {
-		return defaultOrigins
+	select {
+	case err := <-gagal:
+		log.Fatal(err)
+	case <-ctx.Done():
 	}
+	stop()
 
-	// Split multiple origins separated by comma
-	var origins []string
-	for _, origin := range strings.Split(envOrigins, ",") {
-		trimmed := strings.TrimSpace(origin)
-		if trimmed != "" {
-			origins = append(origins, trimmed)
-		}
+	// Graceful drain: fail readiness, give the load balancer time to notice,
+	// then stop accepting connections and let in-flight requests finish
+	// within the shutdown timeout
+	log.Println("Menghentikan server...")
+	a.MulaiBerhenti()
+	time.Sleep(cfg.HTTP.DrainDelay)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Sebagian request tidak selesai sebelum batas waktu:", err)
 	}
-
-	return append(defaultOrigins, origins...)
-}
\ No newline at end of file
+	if err := a.Tutup(); err != nil {
+		log.Println("Gagal menutup database:", err)
+	}
+	log.Println("Server berhenti.")
+}
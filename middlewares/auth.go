@@ -1,14 +1,17 @@
 package middlewares
 
 import (
-	"forum_asisten/utils"
+	"forum_asisten/app"
+	"forum_asisten/models"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware memverifikasi access token dengan penanda tangan JWT milik
+// app dan menolak token yang sudah dicabut atau versinya kedaluwarsa.
+func AuthMiddleware(a *app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -24,16 +27,45 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.VerifyToken(parts[1])
+		claims, err := a.JWT.Verify(parts[1])
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token tidak valid"})
 			c.Abort()
 			return
 		}
 
-		// Simpan claims agar bisa dipakai di handler berikutnya
+		// Token yang sudah di-logout masuk daftar cabut
+		jti, _ := claims["jti"].(string)
+		var dicabut int64
+		a.DB.Model(&models.TokenDicabut{}).Where("jti = ?", jti).Count(&dicabut)
+		if jti == "" || dicabut > 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token sudah dicabut"})
+			c.Abort()
+			return
+		}
+
+		// Versi token berubah saat status/password user berubah; user yang
+		// sudah dihapus juga tidak lagi dikenali
+		userID, _ := claims["user_id"].(float64)
+		versi, _ := claims["ver"].(float64)
+		var user models.User
+		if err := a.DB.Select("id", "versi_token", "status", "role").First(&user, uint(userID)).Error; err != nil || user.VersiToken != uint(versi) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Sesi sudah berakhir, silakan login ulang"})
+			c.Abort()
+			return
+		}
+		if user.Status != "aktif" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Akun tidak aktif"})
+			c.Abort()
+			return
+		}
+
+		// Simpan claims agar bisa dipakai di handler berikutnya. Role dibaca
+		// dari database agar perubahan role langsung berlaku.
 		c.Set("user_id", claims["user_id"])
-		c.Set("role", claims["role"])
+		c.Set("role", user.Role)
+		c.Set("jti", jti)
+		c.Set("exp", claims["exp"])
 
 		c.Next()
 	}
@@ -0,0 +1,60 @@
+package middlewares
+
+import (
+	"forum_asisten/app"
+	"forum_asisten/authz"
+	"forum_asisten/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MuatHak mengumpulkan role utama user beserta role tambahannya.
+func MuatHak(db *gorm.DB, userID uint) (authz.Hak, error) {
+	var user models.User
+	if err := db.Select("id", "role").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	var roles []models.UserRole
+	if err := db.Where("user_id = ?", userID).Find(&roles).Error; err != nil {
+		return nil, err
+	}
+
+	hak := authz.Hak{{Role: user.Role}}
+	for _, r := range roles {
+		// Baris lama berisi role selain role tambahan (mis. admin per prodi)
+		// diabaikan agar tidak memberi izin tanpa batas program studi
+		if !authz.RoleTambahanValid(r.Role) {
+			continue
+		}
+		hak = append(hak, authz.Pemberian{Role: r.Role, ProgramStudiID: r.ProgramStudiID})
+	}
+	return hak, nil
+}
+
+// RequirePermission menolak request jika user tidak memiliki izin tersebut
+// pada program studi mana pun. Pembatasan per program studi dicek handler
+// memakai hak yang disimpan di context dengan key "hak".
+func RequirePermission(a *app.App, izin authz.Izin) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("user_id")
+		id, _ := userID.(float64)
+
+		hak, err := MuatHak(a.DB, uint(id))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User tidak ditemukan"})
+			c.Abort()
+			return
+		}
+		if !hak.Punya(izin) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Anda tidak memiliki izin untuk aksi ini"})
+			c.Abort()
+			return
+		}
+
+		c.Set("hak", hak)
+		c.Next()
+	}
+}
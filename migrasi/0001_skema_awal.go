@@ -0,0 +1,464 @@
+package migrasi
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// skemaAwal membuat seluruh tabel aplikasi beserta indeks, check constraint
+// dan foreign key. Pada database lama yang dibuat AutoMigrate, migrasi ini
+// mengadopsi tabel yang sudah ada dan hanya menambahkan yang kurang.
+//
+// Migrasi ini tidak dapat dibatalkan: pembatalannya berarti menghapus semua
+// tabel, termasuk data produksi yang diadopsi. Kosongkan database secara
+// manual jika memang itu yang diinginkan.
+var skemaAwal = Migrasi{
+	Versi: 1,
+	Nama:  "skema_awal",
+	Naik: func(tx *gorm.DB) error {
+		if err := periksaYatim(tx, relasiAwal); err != nil {
+			return err
+		}
+		return tx.AutoMigrate(tabelAwal...)
+	},
+}
+
+var tabelAwal = []interface{}{
+	&programStudiV1{},
+	&mataKuliahV1{},
+	&dosenV1{},
+	&periodeV1{},
+	&jadwalV1{},
+	&userV1{},
+	&asistenKelasV1{},
+	&pertemuanV1{},
+	&berkasV1{},
+	&presensiV1{},
+	&rekapitulasiV1{},
+	&hariLiburV1{},
+	&penggantianV1{},
+	&sanggahV1{},
+	&sanggahPresensiV1{},
+	&sanggahBalasanV1{},
+	&tarifHonorV1{},
+	&refreshTokenV1{},
+	&tokenDicabutV1{},
+	&resetPasswordV1{},
+	&userRoleV1{},
+	&percobaanLoginV1{},
+	&loginGagalV1{},
+	&sesiSSOV1{},
+	&auditLogV1{},
+}
+
+// relasi adalah satu foreign key: kolom pada tabel yang merujuk id induk.
+type relasi struct {
+	tabel, kolom, induk string
+}
+
+// relasiAwal adalah foreign key yang dibuat skemaAwal.
+var relasiAwal = []relasi{
+	{"mata_kuliahs", "program_studi_id", "program_studis"},
+	{"jadwals", "mata_kuliah_id", "mata_kuliahs"},
+	{"jadwals", "dosen_id", "dosens"},
+	{"jadwals", "periode_id", "periode"},
+	{"asisten_kelas", "jadwal_id", "jadwals"},
+	{"asisten_kelas", "asisten_id", "users"},
+	{"pertemuan", "jadwal_id", "jadwals"},
+	{"pertemuan", "periode_id", "periode"},
+	{"presensi", "jadwal_id", "jadwals"},
+	{"presensi", "pertemuan_id", "pertemuan"},
+	{"presensi", "asisten_id", "users"},
+	{"rekapitulasi", "asisten_id", "users"},
+	{"rekapitulasi", "periode_id", "periode"},
+	{"penggantian", "pertemuan_id", "pertemuan"},
+	{"penggantian", "asisten_asal_id", "users"},
+	{"penggantian", "asisten_pengganti_id", "users"},
+	{"sanggah", "rekapitulasi_id", "rekapitulasi"},
+	{"sanggah_presensi", "sanggah_id", "sanggah"},
+	{"sanggah_presensi", "presensi_id", "presensi"},
+	{"sanggah_balasan", "sanggah_id", "sanggah"},
+	{"sanggah_balasan", "penulis_id", "users"},
+	{"refresh_token", "user_id", "users"},
+	{"reset_password", "user_id", "users"},
+	{"user_role", "user_id", "users"},
+	{"user_role", "program_studi_id", "program_studis"},
+	{"audit_log", "user_id", "users"},
+}
+
+// periksaYatim menolak membuat foreign key jika tabel lama masih memuat baris
+// yang merujuk data yang sudah tidak ada. Baris tersebut harus dibereskan
+// manual karena tidak ada cara aman untuk menebak perbaikannya.
+func periksaYatim(tx *gorm.DB, daftar []relasi) error {
+	m := tx.Migrator()
+	for _, r := range daftar {
+		if !m.HasTable(r.tabel) || !m.HasTable(r.induk) || !m.HasColumn(r.tabel, r.kolom) {
+			continue
+		}
+		var jumlah int64
+		err := tx.Table(r.tabel).
+			Where(r.kolom+" IS NOT NULL AND "+r.kolom+" NOT IN (?)", tx.Table(r.induk).Select("id")).
+			Count(&jumlah).Error
+		if err != nil {
+			return err
+		}
+		if jumlah > 0 {
+			return fmt.Errorf("%d baris %s.%s merujuk %s yang tidak ada; bereskan dulu sebelum migrasi", jumlah, r.tabel, r.kolom, r.induk)
+		}
+	}
+	return nil
+}
+
+// Struct di bawah adalah salinan skema pada versi 1. Jangan diubah; buat
+// migrasi baru untuk perubahan skema.
+
+type programStudiV1 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Nama      string `gorm:"unique;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (programStudiV1) TableName() string { return "program_studis" }
+
+type mataKuliahV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Nama           string `gorm:"not null"`
+	Semester       uint   `gorm:"not null"`
+	Kode           string `gorm:"unique;not null"`
+	ProgramStudiID uint
+	ProgramStudi   programStudiV1 `gorm:"foreignKey:ProgramStudiID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	FaktorHonor    float64        `gorm:"default:1"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `gorm:"index"`
+}
+
+func (mataKuliahV1) TableName() string { return "mata_kuliahs" }
+
+type dosenV1 struct {
+	ID        uint           `gorm:"primaryKey"`
+	Nama      string         `gorm:"type:varchar(100);not null"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (dosenV1) TableName() string { return "dosens" }
+
+type periodeV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Nama           string `gorm:"type:varchar(50);unique;not null"`
+	TahunAjaran    string `gorm:"type:varchar(9);not null"`
+	Semester       string `gorm:"type:varchar(10);not null"`
+	TanggalMulai   time.Time
+	TanggalSelesai time.Time
+	Aktif          bool `gorm:"default:false"`
+	Ditutup        bool `gorm:"default:false"`
+	DitutupPada    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (periodeV1) TableName() string { return "periode" }
+
+type jadwalV1 struct {
+	ID           uint `gorm:"primaryKey"`
+	MataKuliahID uint
+	DosenID      uint
+	Hari         string
+	JamMulai     string
+	JamSelesai   string
+	Lab          string
+	Kelas        string
+	Semester     int
+	PeriodeID    uint           `gorm:"index"`
+	DeletedAt    gorm.DeletedAt `gorm:"index"`
+	MataKuliah   mataKuliahV1   `gorm:"foreignKey:MataKuliahID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Dosen        dosenV1        `gorm:"foreignKey:DosenID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Periode      periodeV1      `gorm:"foreignKey:PeriodeID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (jadwalV1) TableName() string { return "jadwals" }
+
+type userV1 struct {
+	ID               uint `gorm:"primaryKey"`
+	Nama             string
+	Email            string `gorm:"unique"`
+	Password         string
+	Role             string `gorm:"size:20;default:'asisten';check:chk_users_role,role IN ('admin','asisten')"`
+	NIM              *string
+	Telepon          *string
+	Status           string `gorm:"size:20;default:'non-aktif';check:chk_users_status,status IN ('aktif','non-aktif','ditolak')"`
+	Photo            *string
+	VersiToken       uint `gorm:"not null;default:0"`
+	DiverifikasiPada *time.Time
+	DiverifikasiOleh *uint
+	AlasanPenolakan  *string
+	CreatedAt        time.Time
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+}
+
+func (userV1) TableName() string { return "users" }
+
+type asistenKelasV1 struct {
+	ID        uint `gorm:"primaryKey"`
+	JadwalID  uint
+	AsistenID uint
+	PeriodeID uint           `gorm:"index"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+	Jadwal    jadwalV1       `gorm:"foreignKey:JadwalID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	User      userV1         `gorm:"foreignKey:AsistenID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (asistenKelasV1) TableName() string { return "asisten_kelas" }
+
+type pertemuanV1 struct {
+	ID         uint `gorm:"primaryKey"`
+	JadwalID   uint `gorm:"uniqueIndex:idx_pertemuan_jadwal_tanggal"`
+	PeriodeID  uint `gorm:"index"`
+	Ke         int
+	Tanggal    time.Time `gorm:"type:date;uniqueIndex:idx_pertemuan_jadwal_tanggal"`
+	JamMulai   string
+	JamSelesai string
+	Jadwal     jadwalV1  `gorm:"foreignKey:JadwalID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Periode    periodeV1 `gorm:"foreignKey:PeriodeID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (pertemuanV1) TableName() string { return "pertemuan" }
+
+type berkasV1 struct {
+	ID           uint   `gorm:"primaryKey"`
+	PemilikID    uint   `gorm:"index"`
+	Kategori     string `gorm:"type:varchar(20)"`
+	NamaAsli     string
+	Key          string `gorm:"type:varchar(255);index"`
+	ThumbnailKey string `gorm:"type:varchar(255)"`
+	ContentType  string
+	Ukuran       int64
+	SHA256       string `gorm:"type:char(64)"`
+	CreatedAt    time.Time
+}
+
+func (berkasV1) TableName() string { return "berkas" }
+
+type presensiV1 struct {
+	ID               uint `gorm:"primaryKey"`
+	JadwalID         uint
+	AsistenID        uint  `gorm:"uniqueIndex:idx_presensi_pertemuan_asisten"`
+	PeriodeID        uint  `gorm:"index"`
+	PertemuanID      *uint `gorm:"uniqueIndex:idx_presensi_pertemuan_asisten"`
+	Jenis            string
+	Status           string
+	BuktiKehadiranID *uint
+	BuktiIzinID      *uint
+	BuktiKehadiran   string
+	BuktiIzin        string
+	IsiMateri        string
+	WaktuInput       time.Time      `gorm:"autoCreateTime"`
+	Terlambat        bool           `gorm:"default:false"`
+	MenitTerlambat   int            `gorm:"default:0"`
+	DeletedAt        gorm.DeletedAt `gorm:"index"`
+	Jadwal           jadwalV1       `gorm:"foreignKey:JadwalID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Pertemuan        *pertemuanV1   `gorm:"foreignKey:PertemuanID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Asisten          userV1         `gorm:"foreignKey:AsistenID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (presensiV1) TableName() string { return "presensi" }
+
+type rekapitulasiV1 struct {
+	ID              uint `gorm:"primaryKey"`
+	AsistenID       uint `gorm:"uniqueIndex:idx_rekap_asisten_periode"`
+	PeriodeID       uint `gorm:"uniqueIndex:idx_rekap_asisten_periode"`
+	JumlahHadir     int
+	JumlahIzin      int
+	JumlahAlpha     int
+	JumlahPengganti int
+	TipeHonor       string
+	HonorPertemuan  int
+	TotalHonor      int
+	RincianHonor    json.RawMessage `gorm:"type:text"`
+	Asisten         userV1          `gorm:"foreignKey:AsistenID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Periode         periodeV1       `gorm:"foreignKey:PeriodeID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (rekapitulasiV1) TableName() string { return "rekapitulasi" }
+
+type hariLiburV1 struct {
+	ID         uint      `gorm:"primaryKey"`
+	Tanggal    time.Time `gorm:"type:date;unique;not null"`
+	Keterangan string
+}
+
+func (hariLiburV1) TableName() string { return "hari_libur" }
+
+type penggantianV1 struct {
+	ID                 uint   `gorm:"primaryKey"`
+	PertemuanID        uint   `gorm:"index;not null"`
+	AsistenAsalID      uint   `gorm:"index;not null"`
+	AsistenPenggantiID uint   `gorm:"index;not null"`
+	Alasan             string `gorm:"type:text"`
+	Status             string `gorm:"type:varchar(20);default:'diajukan'"`
+	CatatanAdmin       string `gorm:"type:text"`
+	DiterimaPada       *time.Time
+	DiputuskanPada     *time.Time
+	DiputuskanOleh     *uint
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	Pertemuan          pertemuanV1 `gorm:"foreignKey:PertemuanID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	AsistenAsal        userV1      `gorm:"foreignKey:AsistenAsalID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	AsistenPengganti   userV1      `gorm:"foreignKey:AsistenPenggantiID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (penggantianV1) TableName() string { return "penggantian" }
+
+type sanggahV1 struct {
+	ID               uint      `gorm:"primaryKey"`
+	RekapitulasiID   uint      `gorm:"not null"`
+	IsiSanggahan     string    `gorm:"type:text;not null"`
+	Status           string    `gorm:"type:varchar(20);default:'diajukan'"`
+	Waktu            time.Time `gorm:"autoCreateTime"`
+	DiselesaikanPada *time.Time
+	DiselesaikanOleh *uint
+	Rekapitulasi     rekapitulasiV1      `gorm:"foreignKey:RekapitulasiID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Presensi         []sanggahPresensiV1 `gorm:"foreignKey:SanggahID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Balasan          []sanggahBalasanV1  `gorm:"foreignKey:SanggahID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (sanggahV1) TableName() string { return "sanggah" }
+
+// sanggahPresensiV1 ikut terhapus bersama presensinya: presensi di tempat
+// sampah dibuang permanen saat asisten mengisi ulang pertemuan yang sama.
+type sanggahPresensiV1 struct {
+	ID           uint       `gorm:"primaryKey"`
+	SanggahID    uint       `gorm:"index;not null"`
+	PresensiID   uint       `gorm:"not null"`
+	StatusUsulan string     `gorm:"type:varchar(10);not null"`
+	Presensi     presensiV1 `gorm:"foreignKey:PresensiID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (sanggahPresensiV1) TableName() string { return "sanggah_presensi" }
+
+type sanggahBalasanV1 struct {
+	ID        uint      `gorm:"primaryKey"`
+	SanggahID uint      `gorm:"index;not null"`
+	PenulisID uint      `gorm:"not null"`
+	Isi       string    `gorm:"type:text;not null"`
+	Waktu     time.Time `gorm:"autoCreateTime"`
+	Penulis   userV1    `gorm:"foreignKey:PenulisID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+func (sanggahBalasanV1) TableName() string { return "sanggah_balasan" }
+
+type tarifHonorV1 struct {
+	ID           uint      `gorm:"primaryKey"`
+	Kode         string    `gorm:"type:varchar(10);not null;uniqueIndex:idx_tarif_kode_berlaku"`
+	Nominal      int       `gorm:"not null"`
+	BerlakuMulai time.Time `gorm:"type:date;not null;uniqueIndex:idx_tarif_kode_berlaku"`
+	Keterangan   string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (tarifHonorV1) TableName() string { return "tarif_honor" }
+
+type refreshTokenV1 struct {
+	ID              uint   `gorm:"primaryKey"`
+	UserID          uint   `gorm:"index;not null"`
+	Hash            string `gorm:"type:char(64);uniqueIndex;not null"`
+	Keluarga        string `gorm:"type:varchar(64);index;not null"`
+	KedaluwarsaPada time.Time
+	DipakaiPada     *time.Time
+	DicabutPada     *time.Time
+	UserAgent       string
+	IP              string
+	CreatedAt       time.Time
+	User            userV1 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (refreshTokenV1) TableName() string { return "refresh_token" }
+
+type tokenDicabutV1 struct {
+	ID              uint      `gorm:"primaryKey"`
+	JTI             string    `gorm:"type:varchar(64);uniqueIndex;not null"`
+	UserID          uint      `gorm:"index"`
+	KedaluwarsaPada time.Time `gorm:"index"`
+}
+
+func (tokenDicabutV1) TableName() string { return "token_dicabut" }
+
+type resetPasswordV1 struct {
+	ID              uint   `gorm:"primaryKey"`
+	UserID          uint   `gorm:"index;not null"`
+	Hash            string `gorm:"type:char(64);uniqueIndex;not null"`
+	KedaluwarsaPada time.Time
+	DipakaiPada     *time.Time
+	CreatedAt       time.Time
+	User            userV1 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (resetPasswordV1) TableName() string { return "reset_password" }
+
+type userRoleV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	UserID         uint   `gorm:"index;not null"`
+	Role           string `gorm:"type:varchar(20);not null"`
+	ProgramStudiID *uint
+	ProgramStudi   *programStudiV1 `gorm:"foreignKey:ProgramStudiID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	User           userV1          `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (userRoleV1) TableName() string { return "user_role" }
+
+type percobaanLoginV1 struct {
+	ID             uint   `gorm:"primaryKey"`
+	Kunci          string `gorm:"type:varchar(191);uniqueIndex;not null"`
+	Gagal          int
+	TerakhirGagal  time.Time
+	TerkunciSampai *time.Time
+}
+
+func (percobaanLoginV1) TableName() string { return "percobaan_login" }
+
+type loginGagalV1 struct {
+	ID         uint   `gorm:"primaryKey"`
+	Identifier string `gorm:"type:varchar(191);index"`
+	UserID     *uint  `gorm:"index"`
+	IP         string `gorm:"type:varchar(64);index"`
+	UserAgent  string
+	Alasan     string
+	Waktu      time.Time `gorm:"autoCreateTime"`
+}
+
+func (loginGagalV1) TableName() string { return "login_gagal" }
+
+type sesiSSOV1 struct {
+	ID              uint   `gorm:"primaryKey"`
+	State           string `gorm:"type:char(64);uniqueIndex;not null"`
+	Verifier        string `gorm:"size:128;not null"`
+	Nonce           string `gorm:"size:64;not null"`
+	KedaluwarsaPada time.Time
+	CreatedAt       time.Time
+}
+
+func (sesiSSOV1) TableName() string { return "sesi_sso" }
+
+type auditLogV1 struct {
+	ID        uint            `gorm:"primaryKey"`
+	UserID    *uint           `gorm:"index"`
+	Entitas   string          `gorm:"size:50;index:idx_audit_entitas;not null"`
+	EntitasID string          `gorm:"size:50;index:idx_audit_entitas"`
+	Aksi      string          `gorm:"size:30;not null"`
+	Sebelum   json.RawMessage `gorm:"type:text"`
+	Sesudah   json.RawMessage `gorm:"type:text"`
+	IP        string          `gorm:"size:45"`
+	UserAgent string          `gorm:"size:255"`
+	Method    string          `gorm:"size:10"`
+	Path      string          `gorm:"size:255"`
+	Waktu     time.Time       `gorm:"autoCreateTime;index"`
+	User      *userV1         `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+func (auditLogV1) TableName() string { return "audit_log" }
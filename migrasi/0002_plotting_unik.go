@@ -0,0 +1,69 @@
+package migrasi
+
+import (
+	"gorm.io/gorm"
+)
+
+// plottingUnik menjamin satu asisten hanya punya satu baris plotting per
+// jadwal, termasuk baris yang ada di tempat sampah. Duplikat lama dibuang
+// dulu: baris aktif diutamakan, lalu baris dengan id terkecil.
+var plottingUnik = Migrasi{
+	Versi: 2,
+	Nama:  "plotting_unik",
+	Naik: func(tx *gorm.DB) error {
+		if err := buangDuplikatPlotting(tx); err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&asistenKelasV2{}, "idx_asisten_kelas_jadwal_asisten")
+	},
+	Turun: func(tx *gorm.DB) error {
+		return tx.Migrator().DropIndex(&asistenKelasV2{}, "idx_asisten_kelas_jadwal_asisten")
+	},
+}
+
+type asistenKelasV2 struct {
+	ID        uint `gorm:"primaryKey"`
+	JadwalID  uint `gorm:"uniqueIndex:idx_asisten_kelas_jadwal_asisten"`
+	AsistenID uint `gorm:"uniqueIndex:idx_asisten_kelas_jadwal_asisten"`
+	DeletedAt gorm.DeletedAt
+}
+
+func (asistenKelasV2) TableName() string { return "asisten_kelas" }
+
+func buangDuplikatPlotting(tx *gorm.DB) error {
+	type pasangan struct {
+		JadwalID  uint
+		AsistenID uint
+	}
+	var duplikat []pasangan
+	err := tx.Table("asisten_kelas").
+		Select("jadwal_id, asisten_id").
+		Group("jadwal_id, asisten_id").
+		Having("COUNT(*) > 1").
+		Scan(&duplikat).Error
+	if err != nil {
+		return err
+	}
+
+	for _, p := range duplikat {
+		var rows []asistenKelasV2
+		if err := tx.Unscoped().
+			Where("jadwal_id = ? AND asisten_id = ?", p.JadwalID, p.AsistenID).
+			Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		simpan := rows[0]
+		for _, r := range rows {
+			if !r.DeletedAt.Valid {
+				simpan = r
+				break
+			}
+		}
+		if err := tx.Unscoped().
+			Where("jadwal_id = ? AND asisten_id = ? AND id <> ?", p.JadwalID, p.AsistenID, simpan.ID).
+			Delete(&asistenKelasV2{}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package migrasi
+
+import (
+	"gorm.io/gorm"
+)
+
+// presensiKeterangan memisahkan keterangan bebas dari kolom bukti_izin yang
+// kini hanya berisi URL berkas bukti. Keterangan lama (mis. "Digantikan oleh
+// ..." dari persetujuan penggantian) dipindahkan ke kolom baru.
+var presensiKeterangan = Migrasi{
+	Versi: 3,
+	Nama:  "presensi_keterangan",
+	Naik: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&presensiV3{}, "Keterangan"); err != nil {
+			return err
+		}
+		return tx.Exec(`UPDATE presensi SET keterangan = bukti_izin, bukti_izin = ''
+			WHERE bukti_izin <> '' AND bukti_izin NOT LIKE '/api/berkas/%'`).Error
+	},
+	Turun: func(tx *gorm.DB) error {
+		if err := tx.Exec(`UPDATE presensi SET bukti_izin = keterangan
+			WHERE keterangan <> '' AND (bukti_izin IS NULL OR bukti_izin = '')`).Error; err != nil {
+			return err
+		}
+		return tx.Migrator().DropColumn(&presensiV3{}, "Keterangan")
+	},
+}
+
+type presensiV3 struct {
+	ID         uint   `gorm:"primaryKey"`
+	Keterangan string `gorm:"type:text"`
+}
+
+func (presensiV3) TableName() string { return "presensi" }
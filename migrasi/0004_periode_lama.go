@@ -0,0 +1,110 @@
+package migrasi
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// periodeLama menampung data dari database sebelum ada periode akademik.
+// skemaAwal hanya menambahkan kolom periode_id yang kosong, padahal semua
+// query menyaring menurut periode; tanpa migrasi ini presensi dan rekap lama
+// tidak pernah tampil. Baris tanpa periode dimasukkan ke satu periode "lama"
+// yang dibuat aktif jika belum ada periode aktif lain.
+//
+// Turun tidak mengembalikan periode_id menjadi kosong: baris yang sudah
+// diberi periode tetap sah pada skema sebelumnya.
+var periodeLama = Migrasi{
+	Versi: 4,
+	Nama:  "periode_lama",
+	Naik: func(tx *gorm.DB) error {
+		var yatim int64
+		for _, tabel := range tabelBerperiode {
+			var n int64
+			if err := tx.Table(tabel).Where("periode_id IS NULL").Count(&n).Error; err != nil {
+				return err
+			}
+			yatim += n
+		}
+		if yatim == 0 {
+			return nil
+		}
+
+		var ganda int64
+		err := tx.Table("rekapitulasi").Where("periode_id IS NULL").
+			Select("asisten_id").Group("asisten_id").Having("COUNT(*) > 1").Count(&ganda).Error
+		if err != nil {
+			return err
+		}
+		if ganda > 0 {
+			return fmt.Errorf("%d asisten memiliki lebih dari satu rekapitulasi tanpa periode; gabungkan dulu sebelum migrasi", ganda)
+		}
+
+		periode, err := buatPeriodeLama(tx)
+		if err != nil {
+			return err
+		}
+		for _, tabel := range tabelBerperiode {
+			if err := tx.Table(tabel).Where("periode_id IS NULL").Update("periode_id", periode.ID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Turun: func(tx *gorm.DB) error { return nil },
+}
+
+// tabelBerperiode diurutkan dari induk ke anak.
+var tabelBerperiode = []string{"jadwals", "asisten_kelas", "pertemuan", "presensi", "rekapitulasi"}
+
+const namaPeriodeLama = "Periode lama"
+
+func buatPeriodeLama(tx *gorm.DB) (periodeV1, error) {
+	var periode periodeV1
+	err := tx.Where("nama = ?", namaPeriodeLama).Limit(1).Find(&periode).Error
+	if err != nil || periode.ID != 0 {
+		return periode, err
+	}
+
+	// Rentang tanggal mengikuti presensi tertua dan terbaru
+	var awal, akhir struct{ WaktuInput time.Time }
+	tx.Table("presensi").Select("waktu_input").Order("waktu_input").Limit(1).Scan(&awal)
+	tx.Table("presensi").Select("waktu_input").Order("waktu_input DESC").Limit(1).Scan(&akhir)
+	if awal.WaktuInput.IsZero() {
+		awal.WaktuInput = time.Now()
+	}
+	if akhir.WaktuInput.IsZero() {
+		akhir.WaktuInput = awal.WaktuInput
+	}
+
+	var adaAktif int64
+	if err := tx.Model(&periodeV1{}).Where("aktif = ?", true).Count(&adaAktif).Error; err != nil {
+		return periode, err
+	}
+
+	tahunAjaran, semester := semesterPada(awal.WaktuInput)
+	periode = periodeV1{
+		Nama:           namaPeriodeLama,
+		TahunAjaran:    tahunAjaran,
+		Semester:       semester,
+		TanggalMulai:   awal.WaktuInput,
+		TanggalSelesai: akhir.WaktuInput,
+		Aktif:          adaAktif == 0,
+	}
+	return periode, tx.Create(&periode).Error
+}
+
+// semesterPada menentukan tahun ajaran dan semester sebuah tanggal: ganjil
+// Agustus–Januari, genap Februari–Juli.
+func semesterPada(t time.Time) (string, string) {
+	tahun := t.Year()
+	switch {
+	case t.Month() >= time.August:
+		return fmt.Sprintf("%d/%d", tahun, tahun+1), "ganjil"
+	case t.Month() == time.January:
+		return fmt.Sprintf("%d/%d", tahun-1, tahun), "ganjil"
+	default:
+		return fmt.Sprintf("%d/%d", tahun-1, tahun), "genap"
+	}
+}
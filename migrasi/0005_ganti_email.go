@@ -0,0 +1,33 @@
+package migrasi
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// gantiEmail menambahkan tabel token konfirmasi penggantian email lewat
+// PUT /me.
+var gantiEmail = Migrasi{
+	Versi: 5,
+	Nama:  "ganti_email",
+	Naik: func(tx *gorm.DB) error {
+		return tx.Migrator().CreateTable(&gantiEmailV5{})
+	},
+	Turun: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&gantiEmailV5{})
+	},
+}
+
+type gantiEmailV5 struct {
+	ID              uint   `gorm:"primaryKey"`
+	UserID          uint   `gorm:"index;not null"`
+	EmailBaru       string `gorm:"type:varchar(255);not null"`
+	Hash            string `gorm:"type:char(64);uniqueIndex;not null"`
+	KedaluwarsaPada time.Time
+	DipakaiPada     *time.Time
+	CreatedAt       time.Time
+	User            userV1 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (gantiEmailV5) TableName() string { return "ganti_email" }
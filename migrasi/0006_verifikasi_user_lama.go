@@ -0,0 +1,24 @@
+package migrasi
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// verifikasiUserLama mengisi diverifikasi_pada untuk user aktif dari database
+// sebelum ada antrian pendaftaran. Tanpa ini, user lama yang kemudian
+// dinonaktifkan muncul di antrian pendaftaran dan mendapat kode
+// menunggu_aktivasi. User non-aktif lama tidak dapat dibedakan dari
+// pendaftar baru, sehingga dibiarkan di antrian.
+//
+// Turun tidak mengosongkan kolom kembali: user tersebut memang sudah aktif.
+var verifikasiUserLama = Migrasi{
+	Versi: 6,
+	Nama:  "verifikasi_user_lama",
+	Naik: func(tx *gorm.DB) error {
+		return tx.Exec(`UPDATE users SET diverifikasi_pada = COALESCE(created_at, ?)
+			WHERE status = 'aktif' AND diverifikasi_pada IS NULL`, time.Now()).Error
+	},
+	Turun: func(tx *gorm.DB) error { return nil },
+}
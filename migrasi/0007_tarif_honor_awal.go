@@ -0,0 +1,45 @@
+package migrasi
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// tarifHonorAwal mengisi tabel tarif honor yang masih kosong dengan tarif
+// per pertemuan yang dahulu tertanam di kode, agar rekapitulasi lama tetap
+// dihitung dengan tarif yang sama. Nilainya salinan tetap; perubahan tarif
+// berikutnya dilakukan lewat /admin/tarif-honor.
+//
+// Turun tidak menghapus tarif: rekap yang sudah dihitung bergantung padanya.
+var tarifHonorAwal = Migrasi{
+	Versi: 7,
+	Nama:  "tarif_honor_awal",
+	Naik: func(tx *gorm.DB) error {
+		var jumlah int64
+		if err := tx.Model(&tarifHonorV1{}).Count(&jumlah).Error; err != nil || jumlah > 0 {
+			return err
+		}
+
+		berlaku := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+		var tarif []tarifHonorV1
+		for _, kode := range []string{"A", "B", "C", "D", "E"} {
+			tarif = append(tarif, tarifHonorV1{
+				Kode:         kode,
+				Nominal:      nominalHonorAwal[kode],
+				BerlakuMulai: berlaku,
+				Keterangan:   "Tarif awal",
+			})
+		}
+		return tx.Create(&tarif).Error
+	},
+	Turun: func(tx *gorm.DB) error { return nil },
+}
+
+var nominalHonorAwal = map[string]int{
+	"A": 12500,
+	"B": 14500,
+	"C": 16500,
+	"D": 22500,
+	"E": 24500,
+}
@@ -0,0 +1,210 @@
+package migrasi_test
+
+import (
+	"testing"
+	"time"
+
+	"forum_asisten/migrasi"
+)
+
+// Struct di bawah meniru tabel database lama yang dibuat AutoMigrate sebelum
+// ada migrasi berversi (tanpa tipe enum MySQL).
+
+type prodiLama struct {
+	ID        uint   `gorm:"primaryKey"`
+	Nama      string `gorm:"unique;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (prodiLama) TableName() string { return "program_studis" }
+
+type mataKuliahLama struct {
+	ID             uint   `gorm:"primaryKey"`
+	Nama           string `gorm:"not null"`
+	Semester       uint   `gorm:"not null"`
+	Kode           string `gorm:"unique;not null"`
+	ProgramStudiID uint
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (mataKuliahLama) TableName() string { return "mata_kuliahs" }
+
+type dosenLama struct {
+	ID   uint   `gorm:"primaryKey"`
+	Nama string `gorm:"type:varchar(100);not null"`
+}
+
+func (dosenLama) TableName() string { return "dosens" }
+
+type jadwalLama struct {
+	ID                   uint `gorm:"primaryKey"`
+	MataKuliahID         uint
+	DosenID              uint
+	Hari                 string
+	JamMulai, JamSelesai string
+	Lab, Kelas           string
+	Semester             int
+}
+
+func (jadwalLama) TableName() string { return "jadwals" }
+
+type userLama struct {
+	ID       uint `gorm:"primaryKey"`
+	Nama     string
+	Email    string `gorm:"unique"`
+	Password string
+	Role     string `gorm:"default:'asisten'"`
+	NIM      *string
+	Telepon  *string
+	Status   string `gorm:"default:'non-aktif'"`
+	Photo    *string
+}
+
+func (userLama) TableName() string { return "users" }
+
+type asistenKelasLama struct {
+	ID        uint `gorm:"primaryKey"`
+	JadwalID  uint
+	AsistenID uint
+}
+
+func (asistenKelasLama) TableName() string { return "asisten_kelas" }
+
+type presensiLama struct {
+	ID             uint `gorm:"primaryKey"`
+	JadwalID       uint
+	AsistenID      uint
+	Jenis          string
+	Status         string
+	BuktiKehadiran string
+	BuktiIzin      string
+	IsiMateri      string
+	WaktuInput     time.Time
+}
+
+func (presensiLama) TableName() string { return "presensi" }
+
+type rekapLama struct {
+	ID              uint `gorm:"primaryKey"`
+	AsistenID       uint
+	JumlahHadir     int
+	JumlahIzin      int
+	JumlahAlpha     int
+	JumlahPengganti int
+	TipeHonor       string
+	HonorPertemuan  int
+	TotalHonor      int
+}
+
+func (rekapLama) TableName() string { return "rekapitulasi" }
+
+// TestAdopsiDatabaseLama menjalankan semua migrasi di atas database lama yang
+// berisi data: baris tanpa periode harus masuk ke periode lama agar tetap
+// tampil pada query per periode.
+func TestAdopsiDatabaseLama(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	err := db.AutoMigrate(&prodiLama{}, &mataKuliahLama{}, &dosenLama{}, &jadwalLama{},
+		&userLama{}, &asistenKelasLama{}, &presensiLama{}, &rekapLama{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wib := time.FixedZone("WIB", 7*60*60)
+	data := []interface{}{
+		&prodiLama{ID: 1, Nama: "Informatika"},
+		&mataKuliahLama{ID: 1, Nama: "Praktikum Basis Data", Semester: 3, Kode: "IF301P", ProgramStudiID: 1},
+		&dosenLama{ID: 1, Nama: "Dr. Dosen"},
+		&jadwalLama{ID: 1, MataKuliahID: 1, DosenID: 1, Hari: "Senin", JamMulai: "08:00", JamSelesai: "10:00"},
+		&userLama{ID: 1, Nama: "Asisten", Email: "asisten@uji.local", Password: "-", Role: "asisten", Status: "aktif"},
+		&userLama{ID: 2, Nama: "Pendaftar", Email: "pendaftar@uji.local", Password: "-", Role: "asisten", Status: "non-aktif"},
+		&asistenKelasLama{ID: 1, JadwalID: 1, AsistenID: 1},
+		&presensiLama{ID: 1, JadwalID: 1, AsistenID: 1, Jenis: "utama", Status: "hadir", WaktuInput: time.Date(2024, 9, 2, 8, 5, 0, 0, wib)},
+		&presensiLama{ID: 2, JadwalID: 1, AsistenID: 1, Jenis: "utama", Status: "izin", WaktuInput: time.Date(2024, 12, 16, 8, 5, 0, 0, wib)},
+		&rekapLama{ID: 1, AsistenID: 1, JumlahHadir: 1, JumlahIzin: 1, TipeHonor: "A"},
+	}
+	for _, d := range data {
+		if err := db.Create(d).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var periode struct {
+		ID          uint
+		Nama        string
+		TahunAjaran string
+		Semester    string
+		Aktif       bool
+	}
+	if err := db.Table("periode").Where("nama = ?", "Periode lama").Take(&periode).Error; err != nil {
+		t.Fatalf("periode lama tidak dibuat: %v", err)
+	}
+	if !periode.Aktif || periode.TahunAjaran != "2024/2025" || periode.Semester != "ganjil" {
+		t.Errorf("periode lama = %+v, want aktif 2024/2025 ganjil", periode)
+	}
+	for _, tabel := range []string{"jadwals", "asisten_kelas", "presensi", "rekapitulasi"} {
+		var jumlah, tanpaPeriode int64
+		db.Table(tabel).Where("periode_id = ?", periode.ID).Count(&jumlah)
+		db.Table(tabel).Where("periode_id IS NULL").Count(&tanpaPeriode)
+		if jumlah == 0 || tanpaPeriode != 0 {
+			t.Errorf("%s: %d baris di periode lama, %d tanpa periode", tabel, jumlah, tanpaPeriode)
+		}
+	}
+
+	// User aktif lama dianggap sudah diverifikasi; yang non-aktif tetap di
+	// antrian pendaftaran
+	var terverifikasi []uint
+	db.Table("users").Where("diverifikasi_pada IS NOT NULL").Order("id").Pluck("id", &terverifikasi)
+	if want := []uint{1}; !sama(terverifikasi, want) {
+		t.Errorf("user terverifikasi = %v, want %v", terverifikasi, want)
+	}
+}
+
+// TestPeriodeLamaTanpaDataLama memastikan database baru tidak mendapat
+// periode lama.
+func TestPeriodeLamaTanpaDataLama(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	var jumlah int64
+	db.Table("periode").Count(&jumlah)
+	if jumlah != 0 {
+		t.Errorf("database baru memiliki %d periode, want 0", jumlah)
+	}
+}
+
+// TestTarifHonorAwal memastikan tarif awal hanya diisi ke tabel yang masih
+// kosong.
+func TestTarifHonorAwal(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	var nominal int
+	db.Table("tarif_honor").Select("nominal").Where("kode = ?", "D").Scan(&nominal)
+	if nominal != 22500 {
+		t.Errorf("tarif awal D = %d, want 22500", nominal)
+	}
+
+	// Tabel yang sudah berisi tidak ditambah
+	db.Exec("DELETE FROM schema_migrations WHERE versi = 7")
+	db.Exec("DELETE FROM tarif_honor")
+	db.Exec("INSERT INTO tarif_honor (kode, nominal, berlaku_mulai) VALUES ('A', 30000, '2025-01-01')")
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	var jumlah int64
+	db.Table("tarif_honor").Count(&jumlah)
+	if jumlah != 1 {
+		t.Errorf("jumlah tarif = %d, want 1", jumlah)
+	}
+}
@@ -0,0 +1,230 @@
+// Package migrasi mengelola skema database lewat migrasi berversi yang
+// dijalankan berurutan. Versi yang sudah dijalankan dicatat pada tabel
+// schema_migrations.
+//
+// Setiap migrasi memakai struct salinan (snapshot) miliknya sendiri, bukan
+// struct di package models, agar hasilnya tidak berubah ketika model
+// berkembang. Perubahan skema berikutnya ditambahkan sebagai migrasi baru,
+// bukan dengan mengubah migrasi lama.
+package migrasi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migrasi adalah satu langkah perubahan skema beserta pembatalannya. Turun
+// nil berarti migrasi tidak dapat dibatalkan.
+type Migrasi struct {
+	Versi uint
+	Nama  string
+	Naik  func(tx *gorm.DB) error
+	Turun func(tx *gorm.DB) error
+}
+
+// Keadaan adalah status satu migrasi pada database. DijalankanPada nil
+// berarti migrasi masih tertunda.
+type Keadaan struct {
+	Versi          uint
+	Nama           string
+	DijalankanPada *time.Time
+	TidakDikenal   bool // tercatat di database tetapi tidak ada di aplikasi ini
+}
+
+// catatan adalah baris pada tabel schema_migrations.
+type catatan struct {
+	Versi          uint      `gorm:"primaryKey;autoIncrement:false"`
+	Nama           string    `gorm:"size:100;not null"`
+	DijalankanPada time.Time `gorm:"not null"`
+}
+
+func (catatan) TableName() string {
+	return "schema_migrations"
+}
+
+// ErrTertunda dikembalikan Periksa jika masih ada migrasi yang belum dijalankan.
+var ErrTertunda = errors.New("skema database belum mutakhir")
+
+// daftar berisi semua migrasi, diurutkan menurut versi oleh urutkan.
+var daftar = urutkan([]Migrasi{
+	skemaAwal,
+	plottingUnik,
+	presensiKeterangan,
+	periodeLama,
+	gantiEmail,
+	verifikasiUserLama,
+	tarifHonorAwal,
+})
+
+func urutkan(m []Migrasi) []Migrasi {
+	sort.Slice(m, func(i, j int) bool { return m[i].Versi < m[j].Versi })
+	for i := 1; i < len(m); i++ {
+		if m[i].Versi == m[i-1].Versi {
+			panic(fmt.Sprintf("migrasi: versi %d terdaftar dua kali", m[i].Versi))
+		}
+	}
+	return m
+}
+
+// Daftar mengembalikan semua migrasi yang dikenal aplikasi, urut menurut versi.
+func Daftar() []Migrasi {
+	return append([]Migrasi(nil), daftar...)
+}
+
+// sudahDijalankan menyiapkan tabel schema_migrations lalu membaca isinya.
+// Hanya untuk Naik dan Turun; pemeriksaan status memakai bacaCatatan.
+func sudahDijalankan(db *gorm.DB) (map[uint]catatan, error) {
+	if err := db.AutoMigrate(&catatan{}); err != nil {
+		return nil, fmt.Errorf("menyiapkan tabel schema_migrations: %w", err)
+	}
+	return bacaCatatan(db)
+}
+
+// bacaCatatan membaca versi yang sudah dijalankan tanpa mengubah skema:
+// tabel schema_migrations yang belum ada berarti belum ada migrasi.
+func bacaCatatan(db *gorm.DB) (map[uint]catatan, error) {
+	if !db.Migrator().HasTable(&catatan{}) {
+		return map[uint]catatan{}, nil
+	}
+	var rows []catatan
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	hasil := make(map[uint]catatan, len(rows))
+	for _, r := range rows {
+		hasil[r.Versi] = r
+	}
+	return hasil, nil
+}
+
+// Status mengembalikan keadaan setiap migrasi, termasuk versi yang tercatat
+// di database tetapi tidak dikenal aplikasi. Status hanya membaca, sehingga
+// aman dipanggil dari pemeriksaan readiness.
+func Status(db *gorm.DB) ([]Keadaan, error) {
+	jalan, err := bacaCatatan(db)
+	if err != nil {
+		return nil, err
+	}
+	var hasil []Keadaan
+	for _, m := range daftar {
+		k := Keadaan{Versi: m.Versi, Nama: m.Nama}
+		if c, ok := jalan[m.Versi]; ok {
+			waktu := c.DijalankanPada
+			k.DijalankanPada = &waktu
+			delete(jalan, m.Versi)
+		}
+		hasil = append(hasil, k)
+	}
+	for _, c := range jalan {
+		waktu := c.DijalankanPada
+		hasil = append(hasil, Keadaan{Versi: c.Versi, Nama: c.Nama, DijalankanPada: &waktu, TidakDikenal: true})
+	}
+	sort.Slice(hasil, func(i, j int) bool { return hasil[i].Versi < hasil[j].Versi })
+	return hasil, nil
+}
+
+// Periksa memastikan skema database sama dengan yang diharapkan aplikasi:
+// semua migrasi sudah dijalankan dan tidak ada versi yang tidak dikenal.
+func Periksa(db *gorm.DB) error {
+	status, err := Status(db)
+	if err != nil {
+		return err
+	}
+	var tertunda, asing []string
+	for _, k := range status {
+		switch {
+		case k.TidakDikenal:
+			asing = append(asing, fmt.Sprint(k.Versi))
+		case k.DijalankanPada == nil:
+			tertunda = append(tertunda, fmt.Sprintf("%d_%s", k.Versi, k.Nama))
+		}
+	}
+	if len(asing) > 0 {
+		return fmt.Errorf("database memuat versi skema yang tidak dikenal aplikasi ini: %s", strings.Join(asing, ", "))
+	}
+	if len(tertunda) > 0 {
+		return fmt.Errorf("%w, migrasi tertunda: %s", ErrTertunda, strings.Join(tertunda, ", "))
+	}
+	return nil
+}
+
+// Naik menjalankan semua migrasi yang tertunda secara berurutan dan
+// mengembalikan migrasi yang berhasil dijalankan. Setiap migrasi dijalankan
+// dalam transaksinya sendiri (pada MySQL, DDL tetap di-commit otomatis).
+func Naik(db *gorm.DB) ([]Migrasi, error) {
+	jalan, err := sudahDijalankan(db)
+	if err != nil {
+		return nil, err
+	}
+	var selesai []Migrasi
+	for _, m := range daftar {
+		if _, ok := jalan[m.Versi]; ok {
+			continue
+		}
+		m := m
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Naik(tx); err != nil {
+				return err
+			}
+			return tx.Create(&catatan{Versi: m.Versi, Nama: m.Nama, DijalankanPada: time.Now()}).Error
+		})
+		if err != nil {
+			return selesai, fmt.Errorf("migrasi %d_%s: %w", m.Versi, m.Nama, err)
+		}
+		selesai = append(selesai, m)
+	}
+	return selesai, nil
+}
+
+// Turun membatalkan n migrasi terakhir yang sudah dijalankan, dimulai dari
+// versi tertinggi. Jika salah satunya tidak dikenal atau tidak dapat
+// dibatalkan, tidak ada migrasi yang dibatalkan.
+func Turun(db *gorm.DB, n int) ([]Migrasi, error) {
+	jalan, err := sudahDijalankan(db)
+	if err != nil {
+		return nil, err
+	}
+	dikenal := make(map[uint]Migrasi, len(daftar))
+	for _, m := range daftar {
+		dikenal[m.Versi] = m
+	}
+	versi := make([]uint, 0, len(jalan))
+	for v := range jalan {
+		versi = append(versi, v)
+	}
+	sort.Slice(versi, func(i, j int) bool { return versi[i] > versi[j] })
+
+	if len(versi) > n {
+		versi = versi[:n]
+	}
+	for _, v := range versi {
+		m, ok := dikenal[v]
+		if !ok {
+			return nil, fmt.Errorf("versi skema %d tidak dikenal aplikasi ini", v)
+		}
+		if m.Turun == nil {
+			return nil, fmt.Errorf("migrasi %04d_%s tidak dapat dibatalkan", m.Versi, m.Nama)
+		}
+	}
+
+	var selesai []Migrasi
+	for _, v := range versi {
+		m := dikenal[v]
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Turun(tx); err != nil {
+				return err
+			}
+			return tx.Delete(&catatan{Versi: m.Versi}).Error
+		})
+		if err != nil {
+			return selesai, fmt.Errorf("membatalkan migrasi %d_%s: %w", m.Versi, m.Nama, err)
+		}
+		selesai = append(selesai, m)
+	}
+	return selesai, nil
+}
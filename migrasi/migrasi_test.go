@@ -0,0 +1,235 @@
+package migrasi_test
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"forum_asisten/config"
+	"forum_asisten/migrasi"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func bukaDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := config.BukaDB("sqlite", filepath.Join(t.TempDir(), "migrasi.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// versiMigrasi mengambil nomor versi, agar hasil mudah dibandingkan.
+func versiMigrasi(list []migrasi.Migrasi) []uint {
+	var v []uint
+	for _, m := range list {
+		v = append(v, m.Versi)
+	}
+	return v
+}
+
+// tertunda mengembalikan versi yang belum dijalankan menurut Status.
+func tertunda(t *testing.T, db *gorm.DB) []uint {
+	t.Helper()
+	status, err := migrasi.Status(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v []uint
+	for _, k := range status {
+		if k.DijalankanPada == nil {
+			v = append(v, k.Versi)
+		}
+	}
+	return v
+}
+
+func sama(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNaikTurun(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	semua := versiMigrasi(migrasi.Daftar())
+	terakhir := semua[len(semua)-1]
+
+	if err := migrasi.Periksa(db); !errors.Is(err, migrasi.ErrTertunda) {
+		t.Fatalf("Periksa database kosong = %v, want ErrTertunda", err)
+	}
+	if got := tertunda(t, db); !sama(got, semua) {
+		t.Fatalf("tertunda = %v, want %v", got, semua)
+	}
+	// Pemeriksaan status tidak boleh mengubah skema
+	if db.Migrator().HasTable("schema_migrations") {
+		t.Fatal("Periksa membuat tabel schema_migrations")
+	}
+
+	naik, err := migrasi.Naik(db)
+	if err != nil || !sama(versiMigrasi(naik), semua) {
+		t.Fatalf("Naik = %v, %v; want %v", versiMigrasi(naik), err, semua)
+	}
+	if naik, err := migrasi.Naik(db); err != nil || len(naik) != 0 {
+		t.Fatalf("Naik ulang = %v, %v; want tanpa migrasi", versiMigrasi(naik), err)
+	}
+	if err := migrasi.Periksa(db); err != nil {
+		t.Fatalf("Periksa setelah Naik = %v", err)
+	}
+
+	turun, err := migrasi.Turun(db, 1)
+	if err != nil || !sama(versiMigrasi(turun), []uint{terakhir}) {
+		t.Fatalf("Turun(1) = %v, %v; want [%d]", versiMigrasi(turun), err, terakhir)
+	}
+	if err := migrasi.Periksa(db); !errors.Is(err, migrasi.ErrTertunda) {
+		t.Errorf("Periksa setelah Turun = %v, want ErrTertunda", err)
+	}
+	if got := tertunda(t, db); !sama(got, []uint{terakhir}) {
+		t.Errorf("tertunda = %v, want [%d]", got, terakhir)
+	}
+	if naik, err := migrasi.Naik(db); err != nil || !sama(versiMigrasi(naik), []uint{terakhir}) {
+		t.Fatalf("Naik setelah Turun = %v, %v", versiMigrasi(naik), err)
+	}
+
+	// Skema awal tidak dapat dibatalkan; permintaan yang mencakupnya ditolak
+	// utuh tanpa membatalkan migrasi lain
+	if turun, err := migrasi.Turun(db, len(semua)); err == nil || len(turun) != 0 {
+		t.Fatalf("Turun semua = %v, %v; want ditolak", versiMigrasi(turun), err)
+	}
+	if got := tertunda(t, db); len(got) != 0 {
+		t.Errorf("tertunda setelah Turun ditolak = %v, want tidak ada", got)
+	}
+	if !db.Migrator().HasTable("users") {
+		t.Error("tabel users terhapus")
+	}
+}
+
+func TestVersiTidakDikenal(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO schema_migrations (versi, nama, dijalankan_pada) VALUES (?, ?, ?)",
+		99, "dari_versi_baru", "2025-01-01 00:00:00").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := migrasi.Status(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k := status[len(status)-1]; k.Versi != 99 || !k.TidakDikenal {
+		t.Errorf("status terakhir = %+v, want versi 99 tidak dikenal", k)
+	}
+	if err := migrasi.Periksa(db); err == nil || errors.Is(err, migrasi.ErrTertunda) {
+		t.Errorf("Periksa = %v, want galat versi tidak dikenal", err)
+	}
+	if turun, err := migrasi.Turun(db, 1); err == nil || len(turun) != 0 {
+		t.Errorf("Turun = %v, %v; want ditolak", versiMigrasi(turun), err)
+	}
+}
+
+// TestBuangDuplikatPlotting menjalankan ulang migrasi plotting_unik di atas
+// data plotting ganda: baris aktif diutamakan, lalu id terkecil.
+func TestBuangDuplikatPlotting(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	// Kembali ke skema sebelum indeks unik plotting
+	for len(tertunda(t, db)) < len(migrasi.Daftar())-1 {
+		if _, err := migrasi.Turun(db, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Satu koneksi agar pragma berlaku untuk semua perintah berikutnya;
+	// plotting diisi tanpa jadwal dan user sungguhan
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	db.Exec("PRAGMA foreign_keys = OFF")
+	baris := []struct {
+		id, jadwal, asisten uint
+		dihapus             bool
+	}{
+		{1, 10, 100, true},
+		{2, 10, 100, false}, // aktif: disimpan walau bukan id terkecil
+		{3, 10, 100, false},
+		{4, 11, 100, true}, // semua terhapus: id terkecil disimpan
+		{5, 11, 100, true},
+		{6, 10, 101, false}, // tidak ganda
+	}
+	for _, b := range baris {
+		var dihapus interface{}
+		if b.dihapus {
+			dihapus = "2025-01-01 00:00:00"
+		}
+		if err := db.Exec("INSERT INTO asisten_kelas (id, jadwal_id, asisten_id, periode_id, deleted_at) VALUES (?, ?, ?, 1, ?)",
+			b.id, b.jadwal, b.asisten, dihapus).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+	var sisa []uint
+	db.Raw("SELECT id FROM asisten_kelas ORDER BY id").Scan(&sisa)
+	if want := []uint{2, 4, 6}; !sama(sisa, want) {
+		t.Errorf("plotting tersisa = %v, want %v", sisa, want)
+	}
+	if err := db.Exec("INSERT INTO asisten_kelas (jadwal_id, asisten_id, periode_id) VALUES (10, 101, 1)").Error; err == nil {
+		t.Error("plotting ganda diterima setelah migrasi")
+	}
+}
+
+func TestJalankan(t *testing.T) {
+	t.Parallel()
+	db := bukaDB(t)
+
+	jalankan := func(args ...string) (string, error) {
+		var w bytes.Buffer
+		err := migrasi.Jalankan(db, args, &w)
+		return w.String(), err
+	}
+
+	if out, err := jalankan("status"); err != nil || !strings.Contains(out, "0001_skema_awal") || !strings.Contains(out, "tertunda") {
+		t.Errorf("status awal = %q, %v", out, err)
+	}
+	if out, err := jalankan("up"); err != nil || !strings.Contains(out, "naik   0001_skema_awal") {
+		t.Errorf("up = %q, %v", out, err)
+	}
+	if out, err := jalankan("up"); err != nil || !strings.Contains(out, "skema sudah mutakhir") {
+		t.Errorf("up ulang = %q, %v", out, err)
+	}
+	if out, err := jalankan("status"); err != nil || strings.Contains(out, "tertunda") {
+		t.Errorf("status setelah up = %q, %v", out, err)
+	}
+	if out, err := jalankan("down"); err != nil || !strings.Contains(out, "turun") {
+		t.Errorf("down = %q, %v", out, err)
+	}
+	for _, args := range [][]string{nil, {"down", "0"}, {"down", "x"}, {"hapus"}} {
+		if _, err := jalankan(args...); err == nil {
+			t.Errorf("Jalankan(%q) berhasil, want galat", args)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package migrasi
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// Penggunaan adalah bantuan singkat subcommand migrate.
+const Penggunaan = `penggunaan: migrate <perintah>
+
+  up         jalankan semua migrasi yang tertunda
+  down [n]   batalkan n migrasi terakhir (bawaan 1); 0001_skema_awal
+             tidak dapat dibatalkan karena akan menghapus semua data
+  status     tampilkan status setiap migrasi`
+
+// Jalankan menjalankan subcommand migrate (up, down, status) dan menulis
+// hasilnya ke w.
+func Jalankan(db *gorm.DB, args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("perintah migrate belum diisi\n%s", Penggunaan)
+	}
+
+	switch args[0] {
+	case "up":
+		selesai, err := Naik(db)
+		for _, m := range selesai {
+			fmt.Fprintf(w, "naik   %04d_%s\n", m.Versi, m.Nama)
+		}
+		if err == nil && len(selesai) == 0 {
+			fmt.Fprintln(w, "skema sudah mutakhir")
+		}
+		return err
+
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			v, err := strconv.Atoi(args[1])
+			if err != nil || v < 1 {
+				return fmt.Errorf("jumlah langkah tidak valid: %q", args[1])
+			}
+			n = v
+		}
+		selesai, err := Turun(db, n)
+		for _, m := range selesai {
+			fmt.Fprintf(w, "turun  %04d_%s\n", m.Versi, m.Nama)
+		}
+		if err == nil && len(selesai) == 0 {
+			fmt.Fprintln(w, "tidak ada migrasi yang bisa dibatalkan")
+		}
+		return err
+
+	case "status":
+		status, err := Status(db)
+		if err != nil {
+			return err
+		}
+		for _, k := range status {
+			keterangan := "tertunda"
+			if k.DijalankanPada != nil {
+				keterangan = "dijalankan " + k.DijalankanPada.Format("2006-01-02 15:04:05")
+			}
+			if k.TidakDikenal {
+				keterangan += " (tidak dikenal)"
+			}
+			fmt.Fprintf(w, "%04d_%-30s %s\n", k.Versi, k.Nama, keterangan)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("perintah migrate tidak dikenal: %q\n%s", args[0], Penggunaan)
+}
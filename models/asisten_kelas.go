@@ -1,9 +1,13 @@
 package models
 
+import "gorm.io/gorm"
+
 type AsistenKelas struct {
 	ID        uint   `gorm:"primaryKey" json:"id"`
 	JadwalID  uint   `json:"jadwal_id"`
 	AsistenID uint   `json:"asisten_id"`
+	PeriodeID uint   `json:"periode_id" gorm:"index"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
 	Jadwal Jadwal `gorm:"foreignKey:JadwalID;references:ID" json:"jadwal"`
 	User   User   `gorm:"foreignKey:AsistenID;references:ID" json:"user"`
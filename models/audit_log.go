@@ -0,0 +1,29 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditLog mencatat satu perubahan data: siapa, apa, kapan, dan isi data
+// sebelum serta sesudahnya.
+type AuditLog struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	UserID    *uint           `json:"user_id" gorm:"index"` // nil: aksi sistem atau tanpa login
+	Entitas   string          `json:"entitas" gorm:"size:50;index:idx_audit_entitas;not null"`
+	EntitasID string          `json:"entitas_id" gorm:"size:50;index:idx_audit_entitas"`
+	Aksi      string          `json:"aksi" gorm:"size:30;not null"`
+	Sebelum   json.RawMessage `json:"sebelum" gorm:"type:text"`
+	Sesudah   json.RawMessage `json:"sesudah" gorm:"type:text"`
+	IP        string          `json:"ip" gorm:"size:45"`
+	UserAgent string          `json:"user_agent" gorm:"size:255"`
+	Method    string          `json:"method" gorm:"size:10"`
+	Path      string          `json:"path" gorm:"size:255"`
+	Waktu     time.Time       `json:"waktu" gorm:"autoCreateTime;index"`
+
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Berkas adalah metadata berkas unggahan. Isinya disimpan di storage dengan
+// key berdasarkan hash isi, sehingga beberapa baris bisa menunjuk key yang sama.
+type Berkas struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	PemilikID    uint      `json:"pemilik_id" gorm:"index"`
+	Kategori     string    `json:"kategori" gorm:"type:varchar(20)"` // "bukti_kehadiran" | "bukti_izin" | "foto"
+	NamaAsli     string    `json:"nama_asli"`
+	Key          string    `json:"-" gorm:"type:varchar(255);index"`
+	ThumbnailKey string    `json:"-" gorm:"type:varchar(255)"`
+	ContentType  string    `json:"content_type"`
+	Ukuran       int64     `json:"ukuran"`
+	SHA256       string    `json:"sha256" gorm:"type:char(64)"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (Berkas) TableName() string {
+	return "berkas"
+}
+
+// URL adalah endpoint unduhan berkas (memerlukan token).
+func (b Berkas) URL() string {
+	return "/api/berkas/" + strconv.FormatUint(uint64(b.ID), 10)
+}
@@ -1,6 +1,9 @@
 package models
 
+import "gorm.io/gorm"
+
 type Dosen struct {
-	ID   uint   `json:"id" gorm:"primaryKey"`
-	Nama string `json:"nama" gorm:"type:varchar(100);not null"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Nama      string         `json:"nama" gorm:"type:varchar(100);not null"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// GantiEmail adalah permintaan penggantian email dari PUT /me. Email baru
+// baru berlaku setelah tautan konfirmasi yang dikirim ke alamat itu dibuka.
+// Hanya hash token yang disimpan.
+type GantiEmail struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;not null"`
+	EmailBaru       string     `json:"email_baru" gorm:"type:varchar(255);not null"`
+	Hash            string     `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	KedaluwarsaPada time.Time  `json:"kedaluwarsa_pada"`
+	DipakaiPada     *time.Time `json:"dipakai_pada,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	User            User       `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (GantiEmail) TableName() string {
+	return "ganti_email"
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type HariLibur struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Tanggal    time.Time `json:"tanggal" gorm:"type:date;unique;not null"`
+	Keterangan string    `json:"keterangan"`
+}
+
+func (HariLibur) TableName() string {
+	return "hari_libur"
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type MataKuliah struct {
 	ID             uint         `json:"id" gorm:"primaryKey"`
@@ -9,6 +13,8 @@ type MataKuliah struct {
 	Kode           string       `json:"kode" gorm:"unique;not null"`
 	ProgramStudiID uint         `json:"program_studi_id"`
 	ProgramStudi   ProgramStudi `json:"program_studi" gorm:"foreignKey:ProgramStudiID"`
+	FaktorHonor    float64      `json:"faktor_honor" gorm:"default:1"` // pengali honor, mis. 1.5 untuk praktikum panjang
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	DeletedAt      gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 }
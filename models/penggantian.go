@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Penggantian mencatat permintaan asisten (asal) agar sesinya pada satu
+// pertemuan digantikan oleh asisten lain (pengganti).
+//
+// Alur status: diajukan -> diterima (oleh pengganti) -> disetujui (oleh admin).
+// Permintaan dapat ditolak oleh pengganti atau admin, dan dibatalkan oleh
+// asisten asal selama belum disetujui.
+type Penggantian struct {
+	ID                 uint       `json:"id" gorm:"primaryKey"`
+	PertemuanID        uint       `json:"pertemuan_id" gorm:"index;not null"`
+	AsistenAsalID      uint       `json:"asisten_asal_id" gorm:"index;not null"`
+	AsistenPenggantiID uint       `json:"asisten_pengganti_id" gorm:"index;not null"`
+	Alasan             string     `json:"alasan" gorm:"type:text"`
+	Status             string     `json:"status" gorm:"type:varchar(20);default:'diajukan'"` // "diajukan" | "diterima" | "disetujui" | "ditolak" | "dibatalkan"
+	CatatanAdmin       string     `json:"catatan_admin,omitempty" gorm:"type:text"`
+	DiterimaPada       *time.Time `json:"diterima_pada,omitempty"`
+	DiputuskanPada     *time.Time `json:"diputuskan_pada,omitempty"`
+	DiputuskanOleh     *uint      `json:"diputuskan_oleh,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+
+	Pertemuan        Pertemuan `json:"pertemuan" gorm:"foreignKey:PertemuanID"`
+	AsistenAsal      User      `json:"asisten_asal" gorm:"foreignKey:AsistenAsalID"`
+	AsistenPengganti User      `json:"asisten_pengganti" gorm:"foreignKey:AsistenPenggantiID"`
+}
+
+func (Penggantian) TableName() string {
+	return "penggantian"
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// PercobaanLogin mencatat kegagalan login berturut-turut per kunci, yaitu
+// identifier (email/NIM) atau alamat IP.
+type PercobaanLogin struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Kunci          string     `json:"kunci" gorm:"type:varchar(191);uniqueIndex;not null"` // "id:<identifier>" atau "ip:<alamat>"
+	Gagal          int        `json:"gagal"`
+	TerakhirGagal  time.Time  `json:"terakhir_gagal"`
+	TerkunciSampai *time.Time `json:"terkunci_sampai,omitempty"`
+}
+
+func (PercobaanLogin) TableName() string {
+	return "percobaan_login"
+}
+
+// LoginGagal adalah jejak audit setiap percobaan login yang gagal.
+type LoginGagal struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Identifier string    `json:"identifier" gorm:"type:varchar(191);index"`
+	UserID     *uint     `json:"user_id,omitempty" gorm:"index"`
+	IP         string    `json:"ip" gorm:"type:varchar(64);index"`
+	UserAgent  string    `json:"user_agent"`
+	Alasan     string    `json:"alasan"` // tidak_ditemukan, password_salah, diblokir
+	Waktu      time.Time `json:"waktu" gorm:"autoCreateTime"`
+}
+
+func (LoginGagal) TableName() string {
+	return "login_gagal"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type Periode struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	Nama           string     `json:"nama" gorm:"type:varchar(50);unique;not null"` // contoh: "2025/2026 Ganjil"
+	TahunAjaran    string     `json:"tahun_ajaran" gorm:"type:varchar(9);not null"` // contoh: "2025/2026"
+	Semester       string     `json:"semester" gorm:"type:varchar(10);not null"`    // "ganjil" | "genap"
+	TanggalMulai   time.Time  `json:"tanggal_mulai"`
+	TanggalSelesai time.Time  `json:"tanggal_selesai"`
+	Aktif          bool       `json:"aktif" gorm:"default:false"`
+	Ditutup        bool       `json:"ditutup" gorm:"default:false"`
+	DitutupPada    *time.Time `json:"ditutup_pada,omitempty"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+func (Periode) TableName() string {
+	return "periode"
+}
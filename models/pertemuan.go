@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Pertemuan adalah satu sesi terjadwal (pertemuan ke-n) dari sebuah Jadwal
+// dalam satu periode akademik.
+type Pertemuan struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	JadwalID   uint      `json:"jadwal_id" gorm:"uniqueIndex:idx_pertemuan_jadwal_tanggal"`
+	PeriodeID  uint      `json:"periode_id" gorm:"index"`
+	Ke         int       `json:"ke"`
+	Tanggal    time.Time `json:"tanggal" gorm:"type:date;uniqueIndex:idx_pertemuan_jadwal_tanggal"`
+	JamMulai   string    `json:"jam_mulai"`   // format: "08:00"
+	JamSelesai string    `json:"jam_selesai"` // format: "10:00"
+
+	Jadwal Jadwal `json:"jadwal" gorm:"foreignKey:JadwalID"`
+}
+
+func (Pertemuan) TableName() string {
+	return "pertemuan"
+}
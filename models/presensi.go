@@ -1,20 +1,33 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Presensi struct {
 	ID              uint      `json:"id" gorm:"primaryKey"`
 	JadwalID        uint      `json:"jadwal_id"`
-	AsistenID       uint      `json:"asisten_id"`
+	AsistenID       uint      `json:"asisten_id" gorm:"uniqueIndex:idx_presensi_pertemuan_asisten"`
+	PeriodeID       uint      `json:"periode_id" gorm:"index"`
+	PertemuanID     *uint     `json:"pertemuan_id" gorm:"uniqueIndex:idx_presensi_pertemuan_asisten"`
 	Jenis           string    `json:"jenis"` // "utama" | "pengganti"
 	Status          string    `json:"status"` // "hadir" | "izin" | "alpha"
-	BuktiKehadiran  string    `json:"bukti_kehadiran,omitempty"`
-	BuktiIzin       string    `json:"bukti_izin,omitempty"`
+	BuktiKehadiranID *uint    `json:"bukti_kehadiran_id,omitempty"`
+	BuktiIzinID      *uint    `json:"bukti_izin_id,omitempty"`
+	BuktiKehadiran  string    `json:"bukti_kehadiran,omitempty"` // URL unduhan berkas bukti
+	BuktiIzin       string    `json:"bukti_izin,omitempty"`      // URL unduhan berkas bukti
+	Keterangan      string    `json:"keterangan,omitempty" gorm:"type:text"`
 	IsiMateri       string    `json:"isi_materi,omitempty"`
 	WaktuInput      time.Time `json:"waktu_input" gorm:"autoCreateTime"`
+	Terlambat       bool      `json:"terlambat" gorm:"default:false"`
+	MenitTerlambat  int       `json:"menit_terlambat" gorm:"default:0"`
+	DeletedAt       gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
 
-	Jadwal  Jadwal `json:"jadwal" gorm:"foreignKey:JadwalID"`
-	Asisten User   `json:"asisten" gorm:"foreignKey:AsistenID"`
+	Jadwal    Jadwal     `json:"jadwal" gorm:"foreignKey:JadwalID"`
+	Pertemuan *Pertemuan `json:"pertemuan,omitempty" gorm:"foreignKey:PertemuanID"`
+	Asisten   User       `json:"asisten" gorm:"foreignKey:AsistenID"`
 }
 
 func (Presensi) TableName() string {
@@ -1,16 +1,23 @@
 package models
 
+import "forum_asisten/honor"
+
+// Rekapitulasi adalah counter presensi dan honor satu asisten pada satu
+// periode; TotalHonor disimpan dari hasil rekapitulasi.Susun.
 type Rekapitulasi struct {
-	ID              uint   `json:"id" gorm:"primaryKey"`
-	AsistenID       uint   `json:"asisten_id"`
-	JumlahHadir     int    `json:"jumlah_hadir"`
-	JumlahIzin      int    `json:"jumlah_izin"`
-	JumlahAlpha     int    `json:"jumlah_alpha"`
-	JumlahPengganti int    `json:"jumlah_pengganti"`
-	TipeHonor       string `json:"tipe_honor"` // A, B, C, D, E
-	HonorPertemuan  int    `json:"honor_pertemuan"`
-	TotalHonor      int    `json:"total_honor" gorm:"->"`
-	Asisten         User   `json:"asisten" gorm:"foreignKey:AsistenID"`
+	ID              uint        `json:"id" gorm:"primaryKey"`
+	AsistenID       uint        `json:"asisten_id" gorm:"uniqueIndex:idx_rekap_asisten_periode"`
+	PeriodeID       uint        `json:"periode_id" gorm:"uniqueIndex:idx_rekap_asisten_periode"`
+	JumlahHadir     int         `json:"jumlah_hadir"`
+	JumlahIzin      int         `json:"jumlah_izin"`
+	JumlahAlpha     int         `json:"jumlah_alpha"`
+	JumlahPengganti int         `json:"jumlah_pengganti"`
+	TipeHonor       string      `json:"tipe_honor"`      // kode pada tabel tarif_honor, mis. A-E
+	HonorPertemuan  int         `json:"honor_pertemuan"` // tarif yang berlaku saat ini/akhir periode
+	TotalHonor      int         `json:"total_honor"`     // jumlah honor per sesi, lihat RincianHonor
+	RincianHonor    honor.Hasil `json:"rincian_honor" gorm:"serializer:json;type:text"`
+	Asisten         User        `json:"asisten" gorm:"foreignKey:AsistenID"`
+	Periode         Periode     `json:"periode" gorm:"foreignKey:PeriodeID"`
 }
 
 func (Rekapitulasi) TableName() string {
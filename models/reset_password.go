@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// ResetPassword adalah token sekali pakai untuk mengatur ulang password.
+// Hanya hash token yang disimpan.
+type ResetPassword struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;not null"`
+	Hash            string     `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	KedaluwarsaPada time.Time  `json:"kedaluwarsa_pada"`
+	DipakaiPada     *time.Time `json:"dipakai_pada,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	User            User       `json:"-" gorm:"foreignKey:UserID"`
+}
+
+func (ResetPassword) TableName() string {
+	return "reset_password"
+}
@@ -2,15 +2,54 @@ package models
 
 import "time"
 
+// Sanggah adalah keberatan asisten atas rekapitulasinya.
+//
+// Alur status: diajukan -> diproses -> diterima | ditolak.
 type Sanggah struct {
-	ID             uint      `gorm:"primaryKey" json:"id"`
-	RekapitulasiID uint      `gorm:"not null" json:"rekapitulasi_id"`
-	IsiSanggahan   string    `gorm:"type:text;not null" json:"isi_sanggahan"`
-	Waktu          time.Time `gorm:"autoCreateTime" json:"waktu"`
+	ID               uint       `gorm:"primaryKey" json:"id"`
+	RekapitulasiID   uint       `gorm:"not null" json:"rekapitulasi_id"`
+	IsiSanggahan     string     `gorm:"type:text;not null" json:"isi_sanggahan"`
+	Status           string     `gorm:"type:varchar(20);default:'diajukan'" json:"status"` // "diajukan" | "diproses" | "diterima" | "ditolak"
+	Waktu            time.Time  `gorm:"autoCreateTime" json:"waktu"`
+	DiselesaikanPada *time.Time `json:"diselesaikan_pada,omitempty"`
+	DiselesaikanOleh *uint      `json:"diselesaikan_oleh,omitempty"`
 
-	Rekapitulasi Rekapitulasi `gorm:"foreignKey:RekapitulasiID;references:ID"`
+	Rekapitulasi Rekapitulasi      `gorm:"foreignKey:RekapitulasiID;references:ID"`
+	Presensi     []SanggahPresensi `gorm:"foreignKey:SanggahID" json:"presensi,omitempty"`
+	Balasan      []SanggahBalasan  `gorm:"foreignKey:SanggahID" json:"balasan,omitempty"`
 }
 
 func (Sanggah) TableName() string {
 	return "sanggah"
 }
+
+// SanggahPresensi menautkan sanggahan ke presensi yang disengketakan beserta
+// koreksi yang diusulkan. Koreksi diterapkan saat sanggahan diterima.
+type SanggahPresensi struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	SanggahID    uint   `gorm:"index;not null" json:"sanggah_id"`
+	PresensiID   uint   `gorm:"not null" json:"presensi_id"`
+	StatusUsulan string `gorm:"type:varchar(10);not null" json:"status_usulan"` // "hadir" | "izin" | "alpha"
+
+	Presensi Presensi `gorm:"foreignKey:PresensiID" json:"data_presensi"`
+}
+
+func (SanggahPresensi) TableName() string {
+	return "sanggah_presensi"
+}
+
+// SanggahBalasan adalah satu pesan dalam percakapan sanggahan antara
+// asisten dan admin.
+type SanggahBalasan struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	SanggahID uint      `gorm:"index;not null" json:"sanggah_id"`
+	PenulisID uint      `gorm:"not null" json:"penulis_id"`
+	Isi       string    `gorm:"type:text;not null" json:"isi"`
+	Waktu     time.Time `gorm:"autoCreateTime" json:"waktu"`
+
+	Penulis User `gorm:"foreignKey:PenulisID" json:"penulis"`
+}
+
+func (SanggahBalasan) TableName() string {
+	return "sanggah_balasan"
+}
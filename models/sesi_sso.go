@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// SesiSSO menyimpan state login SSO yang sedang berjalan sampai penyedia
+// identitas memanggil callback. Hanya hash state yang disimpan.
+type SesiSSO struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	State           string    `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	Verifier        string    `json:"-" gorm:"size:128;not null"` // PKCE code verifier
+	Nonce           string    `json:"-" gorm:"size:64;not null"`
+	KedaluwarsaPada time.Time `json:"kedaluwarsa_pada"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (SesiSSO) TableName() string {
+	return "sesi_sso"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// TarifHonor adalah nominal honor per pertemuan untuk satu tipe honor yang
+// berlaku mulai tanggal tertentu. Perubahan tarif dicatat sebagai baris baru
+// sehingga rekapitulasi lama tetap dihitung dengan tarif yang berlaku saat itu.
+type TarifHonor struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Kode         string    `json:"kode" gorm:"type:varchar(10);not null;uniqueIndex:idx_tarif_kode_berlaku"` // contoh: "A"
+	Nominal      int       `json:"nominal" gorm:"not null"`
+	BerlakuMulai time.Time `json:"berlaku_mulai" gorm:"type:date;not null;uniqueIndex:idx_tarif_kode_berlaku"`
+	Keterangan   string    `json:"keterangan"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (TarifHonor) TableName() string {
+	return "tarif_honor"
+}
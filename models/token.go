@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// RefreshToken disimpan sebagai hash. Setiap pemakaian menghasilkan token
+// baru dalam keluarga yang sama; token lama yang dipakai ulang menandakan
+// kebocoran sehingga seluruh keluarga dicabut.
+type RefreshToken struct {
+	ID              uint       `json:"id" gorm:"primaryKey"`
+	UserID          uint       `json:"user_id" gorm:"index;not null"`
+	Hash            string     `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	Keluarga        string     `json:"-" gorm:"type:varchar(64);index;not null"`
+	KedaluwarsaPada time.Time  `json:"kedaluwarsa_pada"`
+	DipakaiPada     *time.Time `json:"dipakai_pada,omitempty"`
+	DicabutPada     *time.Time `json:"dicabut_pada,omitempty"`
+	UserAgent       string     `json:"user_agent"`
+	IP              string     `json:"ip"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_token"
+}
+
+// TokenDicabut adalah daftar access token (berdasarkan jti) yang dicabut
+// sebelum kedaluwarsa, misalnya karena logout.
+type TokenDicabut struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	JTI             string    `json:"jti" gorm:"type:varchar(64);uniqueIndex;not null"`
+	UserID          uint      `json:"user_id" gorm:"index"`
+	KedaluwarsaPada time.Time `json:"kedaluwarsa_pada" gorm:"index"`
+}
+
+func (TokenDicabut) TableName() string {
+	return "token_dicabut"
+}
@@ -0,0 +1,15 @@
+package models
+
+// UserRole adalah role tambahan user di luar User.Role, misalnya koordinator
+// lab untuk satu program studi. ProgramStudiID nil berarti semua program studi.
+type UserRole struct {
+	ID             uint          `json:"id" gorm:"primaryKey"`
+	UserID         uint          `json:"user_id" gorm:"index;not null"`
+	Role           string        `json:"role" gorm:"type:varchar(20);not null"`
+	ProgramStudiID *uint         `json:"program_studi_id,omitempty"`
+	ProgramStudi   *ProgramStudi `json:"program_studi,omitempty" gorm:"foreignKey:ProgramStudiID"`
+}
+
+func (UserRole) TableName() string {
+	return "user_role"
+}
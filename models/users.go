@@ -1,13 +1,55 @@
 package models
 
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
 type User struct {
-	ID       uint    `json:"id" gorm:"primaryKey"`
-	Nama     string  `json:"nama"`
-	Email    string  `json:"email" gorm:"unique"`
-	Password string  `json:"-"`
-	Role     string  `json:"role" gorm:"type:enum('admin','asisten');default:'asisten'"`
-	NIM      *string `json:"nim,omitempty"`
-	Telepon *string `json:"telepon,omitempty"`
-	Status string  `json:"status" gorm:"type:enum('aktif','non-aktif');default:'non-aktif'"`
-	Photo  *string `json:"photo,omitempty"`
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	Nama             string         `json:"nama"`
+	Email            string         `json:"email" gorm:"unique"`
+	Password         string         `json:"-"`
+	Role             string         `json:"role" gorm:"size:20;default:'asisten'"` // "admin" | "asisten"
+	NIM              *string        `json:"nim,omitempty"`
+	Telepon          *string        `json:"telepon,omitempty"`
+	Status           string         `json:"status" gorm:"size:20;default:'non-aktif'"` // "aktif" | "non-aktif" | "ditolak"
+	Photo            *string        `json:"photo,omitempty"`
+	VersiToken       uint           `json:"-" gorm:"not null;default:0"` // dinaikkan untuk mencabut semua token user
+	DiverifikasiPada *time.Time     `json:"diverifikasi_pada,omitempty"` // nil: pendaftaran belum diperiksa admin
+	DiverifikasiOleh *uint          `json:"diverifikasi_oleh,omitempty"`
+	AlasanPenolakan  *string        `json:"alasan_penolakan,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	DeletedAt        gorm.DeletedAt `json:"deleted_at,omitempty" gorm:"index"`
+}
+
+// RoleUser dan StatusUser adalah nilai yang sah untuk User.Role dan
+// User.Status. Kolomnya berupa varchar agar portabel antar database, dengan
+// CHECK constraint di database sebagai pengaman terakhir.
+var (
+	RoleUser   = []string{"admin", "asisten"}
+	StatusUser = []string{"aktif", "non-aktif", "ditolak"}
+)
+
+func salahSatu(nilai string, pilihan []string) bool {
+	for _, p := range pilihan {
+		if nilai == p {
+			return true
+		}
+	}
+	return false
+}
+
+// BeforeSave menolak role atau status yang tidak dikenal sebelum sampai ke
+// database. Nilai kosong dibiarkan agar default kolom berlaku.
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	if u.Role != "" && !salahSatu(u.Role, RoleUser) {
+		return fmt.Errorf("role user tidak valid: %q", u.Role)
+	}
+	if u.Status != "" && !salahSatu(u.Status, StatusUser) {
+		return fmt.Errorf("status user tidak valid: %q", u.Status)
+	}
+	return nil
 }
@@ -0,0 +1,109 @@
+package rekapitulasi
+
+import (
+	"forum_asisten/honor"
+	"forum_asisten/models"
+
+	"gorm.io/gorm"
+)
+
+// Selisih adalah rekap yang tersimpan berbeda dari hasil hitung ulang.
+type Selisih struct {
+	RekapitulasiID       uint   `json:"rekapitulasi_id"` // 0 jika rekap belum pernah dibuat
+	AsistenID            uint   `json:"asisten_id"`
+	PeriodeID            uint   `json:"periode_id"`
+	Tersimpan            Jumlah `json:"tersimpan"`
+	Seharusnya           Jumlah `json:"seharusnya"`
+	TotalHonorTersimpan  int    `json:"total_honor_tersimpan"`
+	TotalHonorSeharusnya int    `json:"total_honor_seharusnya"`
+}
+
+type pasangan struct {
+	AsistenID uint
+	PeriodeID uint
+}
+
+// daftarPasangan mengumpulkan asisten-periode yang memiliki presensi aktif
+// atau rekap. periodeID 0 berarti semua periode.
+func daftarPasangan(db *gorm.DB, periodeID uint, hanyaTerbuka bool) ([]pasangan, error) {
+	var list []pasangan
+	for _, tabel := range []string{"presensi", "rekapitulasi"} {
+		query := db.Table(tabel).Distinct(tabel+".asisten_id", tabel+".periode_id")
+		if periodeID != 0 {
+			query = query.Where(tabel+".periode_id = ?", periodeID)
+		}
+		if tabel == "presensi" {
+			query = query.Where("presensi.deleted_at IS NULL")
+		}
+		if hanyaTerbuka {
+			query = query.Joins("JOIN periode ON periode.id = "+tabel+".periode_id").
+				Where("periode.ditutup = ?", false)
+		}
+		var hasil []pasangan
+		if err := query.Scan(&hasil).Error; err != nil {
+			return nil, err
+		}
+		list = append(list, hasil...)
+	}
+
+	unik := make(map[pasangan]bool, len(list))
+	var gabungan []pasangan
+	for _, p := range list {
+		if !unik[p] {
+			unik[p] = true
+			gabungan = append(gabungan, p)
+		}
+	}
+	return gabungan, nil
+}
+
+// Periksa membandingkan rekap tersimpan dengan hasil hitung ulang tanpa
+// mengubah data.
+func Periksa(db *gorm.DB, aturan honor.Konfigurasi, periodeID uint) ([]Selisih, error) {
+	list, err := daftarPasangan(db, periodeID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	selisih := []Selisih{}
+	for _, p := range list {
+		var tersimpan models.Rekapitulasi
+		if err := db.Where("asisten_id = ? AND periode_id = ?", p.AsistenID, p.PeriodeID).First(&tersimpan).Error; err != nil {
+			tersimpan = models.Rekapitulasi{AsistenID: p.AsistenID, PeriodeID: p.PeriodeID}
+		}
+
+		seharusnya := tersimpan
+		if err := Susun(db, aturan, &seharusnya); err != nil {
+			return nil, err
+		}
+
+		if jumlahDari(tersimpan) != jumlahDari(seharusnya) || tersimpan.TotalHonor != seharusnya.TotalHonor {
+			selisih = append(selisih, Selisih{
+				RekapitulasiID:       tersimpan.ID,
+				AsistenID:            p.AsistenID,
+				PeriodeID:            p.PeriodeID,
+				Tersimpan:            jumlahDari(tersimpan),
+				Seharusnya:           jumlahDari(seharusnya),
+				TotalHonorTersimpan:  tersimpan.TotalHonor,
+				TotalHonorSeharusnya: seharusnya.TotalHonor,
+			})
+		}
+	}
+	return selisih, nil
+}
+
+// HitungUlangSemua menyusun ulang semua rekap pada periode yang belum
+// ditutup dan mengembalikan jumlah rekap yang diperbarui. Rekap periode yang
+// sudah ditutup tidak disentuh karena dibekukan.
+func HitungUlangSemua(tx *gorm.DB, aturan honor.Konfigurasi, periodeID uint) (int, error) {
+	list, err := daftarPasangan(tx, periodeID, true)
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range list {
+		if _, err := Perbarui(tx, aturan, p.AsistenID, p.PeriodeID); err != nil {
+			return 0, err
+		}
+	}
+	return len(list), nil
+}
@@ -0,0 +1,160 @@
+// Package rekapitulasi menyusun ulang rekapitulasi asisten langsung dari tabel
+// presensi. Semua perubahan presensi memanggil Perbarui di dalam transaksi
+// yang sama sehingga counter dan honor tidak pernah dihitung dengan cara lain.
+package rekapitulasi
+
+import (
+	"forum_asisten/honor"
+	"forum_asisten/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Jumlah adalah counter rekapitulasi hasil hitung dari presensi.
+type Jumlah struct {
+	Hadir     int `json:"jumlah_hadir"`
+	Izin      int `json:"jumlah_izin"`
+	Alpha     int `json:"jumlah_alpha"`
+	Pengganti int `json:"jumlah_pengganti"`
+}
+
+func jumlahDari(r models.Rekapitulasi) Jumlah {
+	return Jumlah{Hadir: r.JumlahHadir, Izin: r.JumlahIzin, Alpha: r.JumlahAlpha, Pengganti: r.JumlahPengganti}
+}
+
+// hitungJumlah menerapkan aturan counter: hadir utama masuk JumlahHadir,
+// hadir pengganti masuk JumlahPengganti, izin dan alpha dihitung untuk
+// semua jenis presensi.
+func hitungJumlah(presensi []models.Presensi) Jumlah {
+	var j Jumlah
+	for _, p := range presensi {
+		switch p.Status {
+		case "hadir":
+			if p.Jenis == "pengganti" {
+				j.Pengganti++
+			} else {
+				j.Hadir++
+			}
+		case "izin":
+			j.Izin++
+		case "alpha":
+			j.Alpha++
+		}
+	}
+	return j
+}
+
+func muatPresensi(tx *gorm.DB, asistenID, periodeID uint) ([]models.Presensi, error) {
+	var presensi []models.Presensi
+	err := tx.Preload("Pertemuan").Preload("Jadwal.MataKuliah").
+		Where("asisten_id = ? AND periode_id = ?", asistenID, periodeID).
+		Order("waktu_input").
+		Find(&presensi).Error
+	return presensi, err
+}
+
+// Susun mengisi counter dan honor rekap dari presensi asisten pada periode
+// rekap tersebut dengan aturan honor yang diberikan. Rekap tidak disimpan.
+func Susun(tx *gorm.DB, aturan honor.Konfigurasi, rekap *models.Rekapitulasi) error {
+	presensi, err := muatPresensi(tx, rekap.AsistenID, rekap.PeriodeID)
+	if err != nil {
+		return err
+	}
+
+	j := hitungJumlah(presensi)
+	rekap.JumlahHadir = j.Hadir
+	rekap.JumlahIzin = j.Izin
+	rekap.JumlahAlpha = j.Alpha
+	rekap.JumlahPengganti = j.Pengganti
+
+	return hitungHonor(tx, aturan, rekap, presensi)
+}
+
+// Perbarui menyusun ulang dan menyimpan rekap asisten pada satu periode,
+// membuat rekap baru jika belum ada.
+func Perbarui(tx *gorm.DB, aturan honor.Konfigurasi, asistenID, periodeID uint) (models.Rekapitulasi, error) {
+	var rekap models.Rekapitulasi
+	if err := tx.Where("asisten_id = ? AND periode_id = ?", asistenID, periodeID).First(&rekap).Error; err != nil {
+		rekap = models.Rekapitulasi{AsistenID: asistenID, PeriodeID: periodeID}
+	}
+	if err := Susun(tx, aturan, &rekap); err != nil {
+		return rekap, err
+	}
+	return rekap, tx.Save(&rekap).Error
+}
+
+// riwayatTarif adalah daftar tarif satu kode, terurut dari yang paling baru berlaku.
+type riwayatTarif []models.TarifHonor
+
+// pada mengembalikan nominal yang berlaku pada tanggal tertentu.
+func (r riwayatTarif) pada(tanggal time.Time) int {
+	for _, t := range r {
+		if !t.BerlakuMulai.After(tanggal) {
+			return t.Nominal
+		}
+	}
+	return 0
+}
+
+// hitungHonor mengisi HonorPertemuan, TotalHonor, dan RincianHonor.
+// Setiap presensi dihitung dengan tarif yang berlaku pada tanggal sesinya,
+// sehingga perubahan tarif tidak mengubah honor sesi sebelum tarif berlaku,
+// lalu disesuaikan dengan aturan honor (jenis, durasi, mata kuliah, batas).
+func hitungHonor(tx *gorm.DB, aturan honor.Konfigurasi, rekap *models.Rekapitulasi, presensi []models.Presensi) error {
+	if rekap.TipeHonor == "" {
+		rekap.HonorPertemuan = 0
+		rekap.TotalHonor = 0
+		rekap.RincianHonor = honor.Hasil{}
+		return nil
+	}
+
+	var riwayat riwayatTarif
+	if err := tx.Where("kode = ?", rekap.TipeHonor).Order("berlaku_mulai DESC").Find(&riwayat).Error; err != nil {
+		return err
+	}
+
+	// Tarif acuan: hari ini, atau akhir periode jika periode sudah lewat
+	acuan := time.Now()
+	var periode models.Periode
+	if err := tx.First(&periode, rekap.PeriodeID).Error; err == nil && periode.TanggalSelesai.Before(acuan) {
+		acuan = periode.TanggalSelesai
+	}
+	rekap.HonorPertemuan = riwayat.pada(acuan)
+
+	sesi := make([]honor.Sesi, 0, len(presensi))
+	for _, p := range presensi {
+		sesi = append(sesi, sesiHonor(p))
+	}
+
+	hasil := honor.Standar(riwayat.pada, aturan).Hitung(sesi)
+	rekap.TotalHonor = hasil.Total
+	rekap.RincianHonor = hasil
+	return nil
+}
+
+// sesiHonor mengubah presensi menjadi masukan mesin honor. Durasi diambil
+// dari jam pertemuan, atau jam jadwal untuk presensi tanpa pertemuan.
+func sesiHonor(p models.Presensi) honor.Sesi {
+	s := honor.Sesi{
+		PresensiID:       p.ID,
+		Tanggal:          p.WaktuInput,
+		Status:           p.Status,
+		Jenis:            p.Jenis,
+		FaktorMataKuliah: p.Jadwal.MataKuliah.FaktorHonor,
+	}
+
+	jamMulai, jamSelesai := p.Jadwal.JamMulai, p.Jadwal.JamSelesai
+	if p.Pertemuan != nil {
+		s.Tanggal = p.Pertemuan.Tanggal
+		s.PertemuanKe = p.Pertemuan.Ke
+		jamMulai, jamSelesai = p.Pertemuan.JamMulai, p.Pertemuan.JamSelesai
+	}
+
+	mulai, err1 := time.Parse("15:04", jamMulai)
+	selesai, err2 := time.Parse("15:04", jamSelesai)
+	if err1 == nil && err2 == nil && selesai.After(mulai) {
+		s.Durasi = selesai.Sub(mulai)
+	}
+	return s
+}
@@ -0,0 +1,311 @@
+package rekapitulasi
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"forum_asisten/config"
+	"forum_asisten/honor"
+	"forum_asisten/migrasi"
+	"forum_asisten/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestHitungJumlah(t *testing.T) {
+	t.Parallel()
+	p := func(status, jenis string) models.Presensi { return models.Presensi{Status: status, Jenis: jenis} }
+
+	tests := []struct {
+		nama     string
+		presensi []models.Presensi
+		want     Jumlah
+	}{
+		{"tanpa presensi", nil, Jumlah{}},
+		{"hadir utama", []models.Presensi{p("hadir", "utama"), p("hadir", "")}, Jumlah{Hadir: 2}},
+		{"hadir pengganti", []models.Presensi{p("hadir", "pengganti")}, Jumlah{Pengganti: 1}},
+		{"izin dan alpha semua jenis", []models.Presensi{
+			p("izin", "utama"), p("izin", "pengganti"), p("alpha", "utama"), p("alpha", "pengganti"),
+		}, Jumlah{Izin: 2, Alpha: 2}},
+		{"status tidak dikenal diabaikan", []models.Presensi{p("sakit", "utama")}, Jumlah{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			if got := hitungJumlah(tt.presensi); got != tt.want {
+				t.Errorf("hitungJumlah() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiwayatTarifPada(t *testing.T) {
+	t.Parallel()
+	tgl := func(bulan time.Month, hari int) time.Time { return time.Date(2025, bulan, hari, 0, 0, 0, 0, time.UTC) }
+	riwayat := riwayatTarif{
+		{Nominal: 50000, BerlakuMulai: tgl(9, 15)},
+		{Nominal: 40000, BerlakuMulai: tgl(1, 1)},
+	}
+
+	tests := []struct {
+		tanggal time.Time
+		want    int
+	}{
+		{tgl(9, 14), 40000},
+		{tgl(9, 15), 50000},
+		{tgl(12, 1), 50000},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), 0}, // sebelum tarif pertama
+	}
+	for _, tt := range tests {
+		if got := riwayat.pada(tt.tanggal); got != tt.want {
+			t.Errorf("pada(%s) = %d, want %d", tt.tanggal.Format("2006-01-02"), got, tt.want)
+		}
+	}
+}
+
+func TestSesiHonor(t *testing.T) {
+	t.Parallel()
+	tanggal := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	input := time.Date(2025, 9, 3, 9, 0, 0, 0, time.UTC)
+	jadwal := models.Jadwal{JamMulai: "08:00", JamSelesai: "10:00", MataKuliah: models.MataKuliah{FaktorHonor: 1.5}}
+
+	tests := []struct {
+		nama     string
+		presensi models.Presensi
+		want     honor.Sesi
+	}{
+		{
+			"jam dan tanggal dari pertemuan",
+			models.Presensi{ID: 7, Status: "hadir", Jenis: "utama", WaktuInput: input, Jadwal: jadwal,
+				Pertemuan: &models.Pertemuan{Ke: 3, Tanggal: tanggal, JamMulai: "13:00", JamSelesai: "16:30"}},
+			honor.Sesi{PresensiID: 7, Status: "hadir", Jenis: "utama", Tanggal: tanggal, PertemuanKe: 3,
+				Durasi: 210 * time.Minute, FaktorMataKuliah: 1.5},
+		},
+		{
+			"tanpa pertemuan memakai jadwal dan waktu input",
+			models.Presensi{ID: 8, Status: "izin", Jenis: "utama", WaktuInput: input, Jadwal: jadwal},
+			honor.Sesi{PresensiID: 8, Status: "izin", Jenis: "utama", Tanggal: input, Durasi: 2 * time.Hour, FaktorMataKuliah: 1.5},
+		},
+		{
+			"jam tidak valid tanpa durasi",
+			models.Presensi{ID: 9, Status: "hadir", Jenis: "utama", WaktuInput: input,
+				Jadwal: models.Jadwal{JamMulai: "10:00", JamSelesai: "08:00"}},
+			honor.Sesi{PresensiID: 9, Status: "hadir", Jenis: "utama", Tanggal: input},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			if got := sesiHonor(tt.presensi); got != tt.want {
+				t.Errorf("sesiHonor() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// data adalah isi database minimum untuk menyusun rekap satu asisten.
+type data struct {
+	asisten   models.User
+	periode   models.Periode
+	jadwal    models.Jadwal
+	pertemuan []models.Pertemuan
+}
+
+func siapkanDB(t *testing.T) (*gorm.DB, data) {
+	t.Helper()
+	db, err := config.BukaDB("sqlite", filepath.Join(t.TempDir(), "rekap.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if _, err := migrasi.Naik(db); err != nil {
+		t.Fatal(err)
+	}
+
+	var d data
+	prodi := models.ProgramStudi{Nama: "Informatika"}
+	dosen := models.Dosen{Nama: "Dr. Dosen"}
+	mk := models.MataKuliah{Nama: "Praktikum Basis Data", Semester: 3, Kode: "IF301P", FaktorHonor: 1}
+	d.asisten = models.User{Nama: "Asisten", Email: "asisten@uji.local", Password: "-", Role: "asisten", Status: "aktif"}
+	d.periode = models.Periode{
+		Nama: "Ganjil", TahunAjaran: "2025/2026", Semester: "ganjil",
+		TanggalMulai:   time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC),
+		TanggalSelesai: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC),
+	}
+	langkah := []func() error{
+		func() error { return db.Create(&prodi).Error },
+		func() error { return db.Create(&dosen).Error },
+		func() error { return db.Create(&d.periode).Error },
+		func() error { return db.Create(&d.asisten).Error },
+		func() error { mk.ProgramStudiID = prodi.ID; return db.Create(&mk).Error },
+		func() error {
+			d.jadwal = models.Jadwal{MataKuliahID: mk.ID, DosenID: dosen.ID, Hari: "Senin",
+				JamMulai: "08:00", JamSelesai: "10:00", PeriodeID: d.periode.ID}
+			return db.Create(&d.jadwal).Error
+		},
+		func() error {
+			for ke := 1; ke <= 4; ke++ {
+				p := models.Pertemuan{JadwalID: d.jadwal.ID, PeriodeID: d.periode.ID, Ke: ke,
+					Tanggal:  d.periode.TanggalMulai.AddDate(0, 0, 7*(ke-1)),
+					JamMulai: "08:00", JamSelesai: "10:00"}
+				if err := db.Create(&p).Error; err != nil {
+					return err
+				}
+				d.pertemuan = append(d.pertemuan, p)
+			}
+			return nil
+		},
+		func() error {
+			// Tarif naik mulai pertemuan ketiga (15 September 2025)
+			return db.Create([]models.TarifHonor{
+				{Kode: "A", Nominal: 40000, BerlakuMulai: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+				{Kode: "A", Nominal: 50000, BerlakuMulai: time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+			}).Error
+		},
+	}
+	for _, l := range langkah {
+		if err := l(); err != nil {
+			t.Fatalf("menyiapkan data: %v", err)
+		}
+	}
+	return db, d
+}
+
+func TestSusun(t *testing.T) {
+	t.Parallel()
+	db, d := siapkanDB(t)
+	presensi := []struct{ status, jenis string }{
+		{"hadir", "utama"},
+		{"izin", "utama"},
+		{"hadir", "utama"},
+		{"hadir", "pengganti"},
+	}
+	for i, p := range presensi {
+		row := models.Presensi{
+			JadwalID: d.jadwal.ID, AsistenID: d.asisten.ID, PeriodeID: d.periode.ID,
+			PertemuanID: &d.pertemuan[i].ID, Status: p.status, Jenis: p.jenis,
+		}
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		nama       string
+		tipe       string
+		aturan     honor.Konfigurasi
+		jumlah     Jumlah
+		total      int
+		honorAkhir int
+	}{
+		{"tanpa tipe honor", "", honor.Konfigurasi{}, Jumlah{Hadir: 2, Izin: 1, Pengganti: 1}, 0, 0},
+		{"tarif per tanggal sesi", "A", honor.Konfigurasi{}, Jumlah{Hadir: 2, Izin: 1, Pengganti: 1}, 40000 + 50000 + 50000, 50000},
+		{"izin dibayar separuh", "A", honor.Konfigurasi{FaktorJenis: map[string]float64{"izin": 0.5}},
+			Jumlah{Hadir: 2, Izin: 1, Pengganti: 1}, 40000 + 20000 + 50000 + 50000, 50000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			rekap := models.Rekapitulasi{AsistenID: d.asisten.ID, PeriodeID: d.periode.ID, TipeHonor: tt.tipe}
+			if err := Susun(db, tt.aturan, &rekap); err != nil {
+				t.Fatal(err)
+			}
+			if got := jumlahDari(rekap); got != tt.jumlah {
+				t.Errorf("jumlah = %+v, want %+v", got, tt.jumlah)
+			}
+			if rekap.TotalHonor != tt.total || rekap.RincianHonor.Total != tt.total {
+				t.Errorf("total honor = %d (rincian %d), want %d", rekap.TotalHonor, rekap.RincianHonor.Total, tt.total)
+			}
+			if rekap.HonorPertemuan != tt.honorAkhir {
+				t.Errorf("honor pertemuan = %d, want %d", rekap.HonorPertemuan, tt.honorAkhir)
+			}
+			if rekap.ID != 0 {
+				t.Error("Susun menyimpan rekap")
+			}
+		})
+	}
+}
+
+// TestPerbaruiDanPeriksa memastikan Perbarui menyimpan hasil Susun dan
+// Periksa melaporkan rekap yang tersimpan berbeda dari presensi.
+func TestPerbaruiDanPeriksa(t *testing.T) {
+	t.Parallel()
+	db, d := siapkanDB(t)
+	aturan := honor.Konfigurasi{}
+
+	hadir := models.Presensi{
+		JadwalID: d.jadwal.ID, AsistenID: d.asisten.ID, PeriodeID: d.periode.ID,
+		PertemuanID: &d.pertemuan[0].ID, Status: "hadir", Jenis: "utama",
+	}
+	if err := db.Create(&hadir).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	selisih, err := Periksa(db, aturan, d.periode.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(selisih) != 1 || selisih[0].RekapitulasiID != 0 || selisih[0].Seharusnya.Hadir != 1 {
+		t.Fatalf("Periksa sebelum rekap dibuat = %+v", selisih)
+	}
+
+	rekap, err := Perbarui(db, aturan, d.asisten.ID, d.periode.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rekap.ID == 0 || rekap.JumlahHadir != 1 {
+		t.Fatalf("Perbarui = %+v", rekap)
+	}
+	if selisih, _ := Periksa(db, aturan, d.periode.ID); len(selisih) != 0 {
+		t.Fatalf("Periksa setelah Perbarui = %+v", selisih)
+	}
+
+	// Ubah counter langsung di database tanpa lewat Perbarui
+	db.Model(&rekap).Update("jumlah_alpha", 3)
+	selisih, _ = Periksa(db, aturan, d.periode.ID)
+	if len(selisih) != 1 || selisih[0].Tersimpan.Alpha != 3 || selisih[0].Seharusnya.Alpha != 0 {
+		t.Fatalf("Periksa setelah counter diubah = %+v", selisih)
+	}
+
+	// Perbarui kedua memperbaiki rekap yang sama, bukan membuat baris baru
+	if _, err := Perbarui(db, aturan, d.asisten.ID, d.periode.ID); err != nil {
+		t.Fatal(err)
+	}
+	var jumlah int64
+	db.Model(&models.Rekapitulasi{}).Where("asisten_id = ?", d.asisten.ID).Count(&jumlah)
+	if jumlah != 1 {
+		t.Errorf("jumlah rekap = %d, want 1", jumlah)
+	}
+}
+
+// TestHitungUlangMengabaikanPresensiTerhapus memastikan presensi di tempat
+// sampah tidak membuat rekap kosong untuk asistennya.
+func TestHitungUlangMengabaikanPresensiTerhapus(t *testing.T) {
+	t.Parallel()
+	db, d := siapkanDB(t)
+
+	presensi := models.Presensi{
+		JadwalID: d.jadwal.ID, AsistenID: d.asisten.ID, PeriodeID: d.periode.ID,
+		PertemuanID: &d.pertemuan[0].ID, Status: "hadir", Jenis: "utama",
+	}
+	if err := db.Create(&presensi).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Delete(&presensi).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := HitungUlangSemua(db, honor.Konfigurasi{}, d.periode.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jumlah int64
+	db.Model(&models.Rekapitulasi{}).Where("asisten_id = ?", d.asisten.ID).Count(&jumlah)
+	if n != 0 || jumlah != 0 {
+		t.Errorf("HitungUlangSemua = %d, %d rekap; want 0, 0", n, jumlah)
+	}
+}
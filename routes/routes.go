@@ -1,90 +1,175 @@
 package routes
 
 import (
+	"forum_asisten/app"
+	"forum_asisten/authz"
 	"forum_asisten/controllers"
 	"forum_asisten/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(r *gin.Engine) {
+// SetupRoutes memasang semua route API di atas dependensi App.
+func SetupRoutes(r *gin.Engine, a *app.App) {
+	h := controllers.New(a)
+
+	// Probe orkestrator, di luar /api dan tanpa autentikasi
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+
 	api := r.Group("/api")
 	{
-		api.POST("/register", controllers.Register)
-		api.POST("/login", controllers.Login)
-		// api.GET("/program-studi", controllers.GetAllProgramStudi)
-		// api.GET("/mata-kuliah", controllers.GetAllMataKuliah)
-		// api.GET("/dosen", controllers.GetAllDosen)
-		api.GET("/jadwal", controllers.GetAllJadwal)
-		// api.GET("/asisten-kelas", controllers.GetJadwalAsisten)
-		// api.GET("/presensi", controllers.GetAllPresensi)
-		// api.GET("/rekapitulasi", controllers.GetRekapitulasi)
-		api.GET("/sanggah", controllers.GetSemuaSanggah)
-		api.GET("/sanggah/:id", controllers.GetSanggahByID)
+		api.GET("/versi", h.GetVersi)
+		api.POST("/register", h.Register)
+		api.POST("/login", h.Login)
+		api.POST("/refresh", h.RefreshToken)
+		api.POST("/password/forgot", h.ForgotPassword)
+		api.POST("/password/reset", h.ResetPassword)
+		api.POST("/email/konfirmasi", h.KonfirmasiEmail)
+		api.GET("/sso/login", h.SSOLogin)
+		api.GET("/sso/callback", h.SSOCallback)
+		// api.GET("/program-studi", h.GetAllProgramStudi)
+		// api.GET("/mata-kuliah", h.GetAllMataKuliah)
+		// api.GET("/dosen", h.GetAllDosen)
+		api.GET("/jadwal", h.GetAllJadwal)
+		// api.GET("/asisten-kelas", h.GetJadwalAsisten)
+		// api.GET("/presensi", h.GetAllPresensi)
+		// api.GET("/rekapitulasi", h.GetRekapitulasi)
 
 		protected := api.Group("/")
-		protected.Use(middlewares.AuthMiddleware())
+		protected.Use(middlewares.AuthMiddleware(a))
 		{
-			protected.POST("/asisten-kelas", controllers.PilihJadwalAsisten)
-			protected.DELETE("/asisten-kelas/:jadwal_id/:asisten_id", controllers.DeleteAsistenFromJadwal)
+			protected.POST("/logout", h.Logout)
+			protected.POST("/password/change", h.ChangePassword)
+
+			protected.GET("/me", h.GetProfilSaya)
+			protected.PUT("/me", h.UpdateProfilSaya)
+			protected.PUT("/me/photo", h.UnggahFotoSaya)
+
+			protected.POST("/asisten-kelas", h.PilihJadwalAsisten)
+			protected.DELETE("/asisten-kelas/:jadwal_id/:asisten_id", h.DeleteAsistenFromJadwal)
+
+			protected.POST("/berkas", h.UnggahBerkas)
+			protected.GET("/berkas/:id", h.UnduhBerkas)
+			protected.GET("/berkas/:id/thumbnail", h.UnduhThumbnailBerkas)
 
-			protected.POST("/presensi", controllers.CreatePresensi)
-			protected.GET("/presensi", controllers.GetAllPresensi)
+			protected.POST("/presensi", middlewares.RequirePermission(a, authz.IsiPresensi), h.CreatePresensi)
+			protected.GET("/presensi", h.GetAllPresensi)
 
-			protected.POST("/sanggah", controllers.BuatSanggah)
+			protected.GET("/sanggah", h.GetSemuaSanggah)
+			protected.GET("/sanggah/:id", h.GetSanggahByID)
+			protected.POST("/sanggah", h.BuatSanggah)
+			protected.POST("/sanggah/:id/balasan", h.BalasSanggah)
 
-			protected.GET("/asisten-kelas", controllers.GetJadwalAsisten)
-			protected.GET("/api/asisten-kelas/user/:user_id", controllers.GetJadwalAsistenById)
-			protected.GET("/rekapitulasi", controllers.GetRekapitulasi)
+			protected.GET("/asisten-kelas", h.GetJadwalAsisten)
+			protected.GET("/api/asisten-kelas/user/:user_id", h.GetJadwalAsistenById)
+			protected.GET("/rekapitulasi", h.GetRekapitulasi)
 
-			protected.POST("/users", controllers.Register)
-			protected.GET("/users", controllers.GetUsers)
-			protected.GET("/users/:id", controllers.GetUserByID)
-			protected.PUT("/users/:id", controllers.UpdateUserAsisten)
+			protected.GET("/pertemuan", h.GetPertemuan)
 
+			protected.POST("/penggantian", h.AjukanPenggantian)
+			protected.GET("/penggantian", h.GetPenggantianSaya)
+			protected.PUT("/penggantian/:id/terima", h.TerimaPenggantian)
+			protected.PUT("/penggantian/:id/tolak", h.TolakPenggantian)
+			protected.PUT("/penggantian/:id/batal", h.BatalkanPenggantian)
+			protected.GET("/periode", h.GetAllPeriode)
+			protected.GET("/periode/aktif", h.GetPeriodeAktif)
+
+		}
+		// Route pengelolaan yang juga dapat diakses koordinator; cakupan
+		// program studi dicek di handler
+		kelola := api.Group("/admin")
+		kelola.Use(middlewares.AuthMiddleware(a))
+		{
+			plotting := middlewares.RequirePermission(a, authz.KelolaPlotting)
+			kelola.POST("/asisten-kelas", plotting, h.AdminPilihJadwalAsisten)
+			kelola.GET("/asisten-kelas", plotting, h.GetJadwalAsisten)
+			kelola.PUT("/asisten-kelas/:id", plotting, h.UpdateAsistenKelas)
+			kelola.DELETE("/asisten-kelas/:jadwal_id/:asisten_id", plotting, h.DeleteAsistenFromJadwal)
+
+			presensi := middlewares.RequirePermission(a, authz.KelolaPresensi)
+			kelola.GET("/presensi", presensi, h.GetAllPresensi)
+			kelola.PUT("/presensi/:id", presensi, h.UpdatePresensi)
+			kelola.DELETE("/presensi/:id", presensi, h.DeletePresensi)
+
+			role := middlewares.RequirePermission(a, authz.KelolaRole)
+			kelola.GET("/users/:id/roles", role, h.GetRoleUser)
+			kelola.POST("/users/:id/roles", role, h.TambahRoleUser)
+			kelola.DELETE("/users/:id/roles/:role_id", role, h.HapusRoleUser)
 		}
+
 		admin := api.Group("/admin")
-		admin.Use(middlewares.AuthMiddleware(), middlewares.AdminMiddleware())
+		admin.Use(middlewares.AuthMiddleware(a), middlewares.AdminMiddleware())
 		{
-			admin.POST("/users", controllers.Register)
-			admin.GET("/users", controllers.GetUsers)
-			admin.PUT("/users/:id", controllers.UpdateUser)
-			admin.PUT("/users/:id/status", controllers.UpdateUserStatus)
-			admin.DELETE("/users/:id", controllers.DeleteUser)
-
-			admin.GET("/program-studi", controllers.GetAllProgramStudi)
-			admin.POST("/program-studi", controllers.CreateProgramStudi)
-			admin.PUT("/program-studi/:id", controllers.UpdateProgramStudi)
-			admin.DELETE("/program-studi/:id", controllers.DeleteProgramStudi)
-
-			admin.GET("/mata-kuliah", controllers.GetAllMataKuliah)
-			admin.POST("/mata-kuliah", controllers.CreateMataKuliah)
-			admin.PUT("/mata-kuliah/:id", controllers.UpdateMataKuliah)
-			admin.DELETE("/mata-kuliah/:id", controllers.DeleteMataKuliah)
-
-			admin.GET("/dosen", controllers.GetAllDosen)
-			admin.POST("/dosen", controllers.CreateDosen)
-			admin.PUT("/dosen/:id", controllers.UpdateDosen)
-			admin.DELETE("/dosen/:id", controllers.DeleteDosen)
-
-			admin.GET("/jadwal", controllers.GetAllJadwal)
-			admin.POST("/jadwal", controllers.CreateJadwal)
-			admin.PUT("/jadwal/:id", controllers.UpdateJadwal)
-			admin.DELETE("/jadwal/:id", controllers.DeleteJadwal)
-
-			admin.POST("/asisten-kelas", controllers.AdminPilihJadwalAsisten)
-			admin.GET("/asisten-kelas", controllers.GetJadwalAsisten)
-			admin.PUT("/asisten-kelas/:id", controllers.UpdateAsistenKelas)
-			admin.DELETE("/asisten-kelas/:jadwal_id/:asisten_id", controllers.DeleteAsistenFromJadwal)
-
-			admin.GET("/presensi", controllers.GetAllPresensi)
-			admin.PUT("/presensi/:id", controllers.UpdatePresensi)
-			admin.DELETE("/presensi/:id", controllers.DeletePresensi)
-
-			admin.GET("/rekapitulasi", controllers.GetRekapitulasi)
-			admin.POST("/rekapitulasi", controllers.SetTipeHonor)
-			admin.PUT("/rekapitulasi/:id", controllers.UpdateRekapitulasi)
-			admin.DELETE("/rekapitulasi/:id", controllers.DeleteRekapitulasi)
+			admin.POST("/users", h.Register)
+			admin.GET("/users", h.GetUsers)
+			admin.GET("/users/:id", h.GetUserByID)
+			admin.PUT("/users/:id", h.UpdateUser)
+			admin.PUT("/users/:id/status", h.UpdateUserStatus)
+			admin.PUT("/users/:id/unlock", h.UnlockUser)
+
+			admin.GET("/audit", h.GetAuditLog)
+
+			admin.GET("/sampah/:entitas", h.GetSampah)
+			admin.PUT("/sampah/:entitas/:id/pulihkan", h.PulihkanSampah)
+			admin.DELETE("/users/:id", h.DeleteUser)
+
+			admin.GET("/pendaftaran", h.GetPendaftaranMenunggu)
+			admin.PUT("/pendaftaran/:id/setujui", h.SetujuiPendaftaran)
+			admin.PUT("/pendaftaran/:id/tolak", h.TolakPendaftaran)
+
+			admin.GET("/periode", h.GetAllPeriode)
+			admin.POST("/periode", h.CreatePeriode)
+			admin.PUT("/periode/:id", h.UpdatePeriode)
+			admin.PUT("/periode/:id/aktifkan", h.AktifkanPeriode)
+			admin.PUT("/periode/:id/tutup", h.TutupPeriode)
+			admin.DELETE("/periode/:id", h.DeletePeriode)
+			admin.POST("/periode/:id/pertemuan", h.GeneratePertemuanPeriode)
+
+			admin.GET("/hari-libur", h.GetAllHariLibur)
+			admin.POST("/hari-libur", h.CreateHariLibur)
+			admin.DELETE("/hari-libur/:id", h.DeleteHariLibur)
+
+			admin.GET("/program-studi", h.GetAllProgramStudi)
+			admin.POST("/program-studi", h.CreateProgramStudi)
+			admin.PUT("/program-studi/:id", h.UpdateProgramStudi)
+			admin.DELETE("/program-studi/:id", h.DeleteProgramStudi)
+
+			admin.GET("/mata-kuliah", h.GetAllMataKuliah)
+			admin.POST("/mata-kuliah", h.CreateMataKuliah)
+			admin.PUT("/mata-kuliah/:id", h.UpdateMataKuliah)
+			admin.DELETE("/mata-kuliah/:id", h.DeleteMataKuliah)
+
+			admin.GET("/dosen", h.GetAllDosen)
+			admin.POST("/dosen", h.CreateDosen)
+			admin.PUT("/dosen/:id", h.UpdateDosen)
+			admin.DELETE("/dosen/:id", h.DeleteDosen)
+
+			admin.GET("/jadwal", h.GetAllJadwal)
+			admin.POST("/jadwal", h.CreateJadwal)
+			admin.PUT("/jadwal/:id", h.UpdateJadwal)
+			admin.DELETE("/jadwal/:id", h.DeleteJadwal)
+			admin.POST("/jadwal/:id/pertemuan", h.GeneratePertemuanJadwal)
+			admin.GET("/pertemuan", h.GetPertemuan)
+
+			admin.GET("/penggantian", h.GetAllPenggantian)
+			admin.PUT("/penggantian/:id/setujui", h.SetujuiPenggantian)
+			admin.PUT("/penggantian/:id/tolak", h.AdminTolakPenggantian)
+
+			admin.GET("/sanggah", h.AdminGetSanggah)
+			admin.PUT("/sanggah/:id/status", h.UbahStatusSanggah)
+
+			admin.GET("/rekapitulasi", h.GetRekapitulasi)
+			admin.POST("/rekapitulasi", h.SetTipeHonor)
+			admin.POST("/rekapitulasi/hitung-ulang", h.HitungUlangSemuaRekapitulasi)
+			admin.GET("/rekapitulasi/konsistensi", h.PeriksaKonsistensiRekapitulasi)
+			admin.PUT("/rekapitulasi/:id", h.UpdateRekapitulasi)
+			admin.DELETE("/rekapitulasi/:id", h.DeleteRekapitulasi)
+
+			admin.GET("/tarif-honor", h.GetAllTarifHonor)
+			admin.POST("/tarif-honor", h.CreateTarifHonor)
+			admin.PUT("/tarif-honor/:id", h.UpdateTarifHonor)
+			admin.DELETE("/tarif-honor/:id", h.DeleteTarifHonor)
 		}
 
 	}
@@ -0,0 +1,141 @@
+// Package sso menangani login melalui penyedia identitas OpenID Connect
+// (akun kampus) dengan alur authorization code + PKCE.
+package sso
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+var ErrEmailBelumDiverifikasi = errors.New("email akun SSO belum diverifikasi penyedia identitas")
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// KlaimNIM adalah nama klaim ID token yang berisi NIM, kosong jika
+	// penyedia tidak mengirim NIM.
+	KlaimNIM string
+}
+
+// Identitas adalah data user hasil verifikasi ID token.
+type Identitas struct {
+	Subjek string
+	Email  string
+	Nama   string
+	NIM    string
+}
+
+// Permintaan menyimpan nilai yang harus dicocokkan kembali saat callback.
+type Permintaan struct {
+	URL      string
+	State    string
+	Verifier string
+	Nonce    string
+}
+
+type Klien struct {
+	oauth    oauth2.Config
+	verifier *oidc.IDTokenVerifier
+	klaimNIM string
+}
+
+// New mengambil dokumen discovery dari issuer dan menyiapkan klien.
+func New(ctx context.Context, cfg Config) (*Klien, error) {
+	provider, err := oidc.NewProvider(ctx, cfg.Issuer)
+	if err != nil {
+		return nil, fmt.Errorf("discovery OIDC: %w", err)
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"profile", "email"}
+	}
+	scopes = append([]string{oidc.ScopeOpenID}, scopes...)
+
+	return &Klien{
+		oauth: oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint:     provider.Endpoint(),
+			Scopes:       scopes,
+		},
+		verifier: provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}),
+		klaimNIM: cfg.KlaimNIM,
+	}, nil
+}
+
+// Mulai membuat URL otorisasi beserta state, PKCE verifier, dan nonce baru.
+func (k *Klien) Mulai(state, nonce string) Permintaan {
+	verifier := oauth2.GenerateVerifier()
+	return Permintaan{
+		URL: k.oauth.AuthCodeURL(state,
+			oauth2.S256ChallengeOption(verifier),
+			oidc.Nonce(nonce),
+		),
+		State:    state,
+		Verifier: verifier,
+		Nonce:    nonce,
+	}
+}
+
+// Tukar menukar authorization code dengan token lalu memverifikasi ID token.
+func (k *Klien) Tukar(ctx context.Context, code, verifier, nonce string) (Identitas, error) {
+	tok, err := k.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return Identitas{}, fmt.Errorf("tukar code: %w", err)
+	}
+	mentah, ok := tok.Extra("id_token").(string)
+	if !ok || mentah == "" {
+		return Identitas{}, errors.New("respons token tidak berisi id_token")
+	}
+
+	idToken, err := k.verifier.Verify(ctx, mentah)
+	if err != nil {
+		return Identitas{}, fmt.Errorf("verifikasi id_token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return Identitas{}, errors.New("nonce id_token tidak cocok")
+	}
+
+	var klaim map[string]any
+	if err := idToken.Claims(&klaim); err != nil {
+		return Identitas{}, fmt.Errorf("klaim id_token: %w", err)
+	}
+	if v, ada := klaim["email_verified"].(bool); ada && !v {
+		return Identitas{}, ErrEmailBelumDiverifikasi
+	}
+
+	id := Identitas{
+		Subjek: idToken.Subject,
+		Email:  strings.ToLower(teks(klaim["email"])),
+		Nama:   teks(klaim["name"]),
+	}
+	if k.klaimNIM != "" {
+		id.NIM = teks(klaim[k.klaimNIM])
+	}
+	if id.Email == "" && id.NIM == "" {
+		return Identitas{}, errors.New("id_token tidak berisi email maupun NIM")
+	}
+	return id, nil
+}
+
+// teks membaca klaim string; NIM kadang dikirim sebagai angka JSON.
+func teks(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
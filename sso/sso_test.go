@@ -0,0 +1,106 @@
+package sso_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"forum_asisten/sso"
+	"forum_asisten/sso/ssotest"
+)
+
+func TestKlienTukar(t *testing.T) {
+	penyedia, err := ssotest.Baru("forum-asisten")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer penyedia.Close()
+
+	ctx := context.Background()
+	klien, err := sso.New(ctx, sso.Config{
+		Issuer:      penyedia.URL,
+		ClientID:    "forum-asisten",
+		RedirectURL: "http://localhost/api/sso/callback",
+		KlaimNIM:    "nim",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		nama     string
+		klaim    map[string]any
+		verifier func(sso.Permintaan) string
+		nonce    func(sso.Permintaan) string
+		want     sso.Identitas
+		wantErr  error
+		gagal    bool
+	}{
+		{
+			nama:  "email dan nim",
+			klaim: map[string]any{"email": "Budi@Kampus.ac.id", "name": "Budi", "nim": "2100018001"},
+			want:  sso.Identitas{Subjek: "user", Email: "budi@kampus.ac.id", Nama: "Budi", NIM: "2100018001"},
+		},
+		{
+			nama:  "nim berupa angka",
+			klaim: map[string]any{"nim": 2100018002},
+			want:  sso.Identitas{Subjek: "user", NIM: "2100018002"},
+		},
+		{
+			nama:    "email belum diverifikasi",
+			klaim:   map[string]any{"email": "budi@kampus.ac.id", "email_verified": false},
+			wantErr: sso.ErrEmailBelumDiverifikasi,
+		},
+		{
+			nama:  "tanpa email dan nim",
+			klaim: map[string]any{"name": "Budi"},
+			gagal: true,
+		},
+		{
+			nama:     "verifier PKCE salah",
+			klaim:    map[string]any{"email": "budi@kampus.ac.id"},
+			verifier: func(sso.Permintaan) string { return "verifier-lain-yang-cukup-panjang-untuk-pkce-0123456789" },
+			gagal:    true,
+		},
+		{
+			nama:  "nonce tidak cocok",
+			klaim: map[string]any{"email": "budi@kampus.ac.id"},
+			nonce: func(sso.Permintaan) string { return "nonce-lain" },
+			gagal: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.nama, func(t *testing.T) {
+			req := klien.Mulai("state", "nonce")
+			kode, err := penyedia.Otorisasi(req.URL, tt.klaim)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			verifier, nonce := req.Verifier, req.Nonce
+			if tt.verifier != nil {
+				verifier = tt.verifier(req)
+			}
+			if tt.nonce != nil {
+				nonce = tt.nonce(req)
+			}
+
+			got, err := klien.Tukar(ctx, kode, verifier, nonce)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+			case tt.gagal:
+				if err == nil {
+					t.Fatal("Tukar berhasil, want error")
+				}
+			case err != nil:
+				t.Fatal(err)
+			case got != tt.want:
+				t.Errorf("Tukar = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+// Package ssotest menyediakan penyedia OIDC tiruan untuk pengujian alur
+// login SSO tanpa server identitas sungguhan.
+package ssotest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const kid = "ssotest"
+
+type otorisasi struct {
+	challenge string
+	nonce     string
+	klaim     map[string]any
+}
+
+// Penyedia adalah server OIDC tiruan: discovery, JWKS, dan token endpoint
+// dengan pemeriksaan PKCE S256.
+type Penyedia struct {
+	URL      string
+	ClientID string
+
+	server *httptest.Server
+	kunci  *rsa.PrivateKey
+
+	mu   sync.Mutex
+	kode map[string]otorisasi
+}
+
+func Baru(clientID string) (*Penyedia, error) {
+	kunci, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	p := &Penyedia{ClientID: clientID, kunci: kunci, kode: map[string]otorisasi{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/jwks", p.jwks)
+	mux.HandleFunc("/token", p.token)
+	p.server = httptest.NewServer(mux)
+	p.URL = p.server.URL
+	return p, nil
+}
+
+func (p *Penyedia) Close() {
+	p.server.Close()
+}
+
+// Otorisasi meniru user yang berhasil login di halaman penyedia: membaca URL
+// otorisasi dan mengembalikan authorization code untuk klaim yang diberikan.
+func (p *Penyedia) Otorisasi(urlOtorisasi string, klaim map[string]any) (string, error) {
+	u, err := url.Parse(urlOtorisasi)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	if q.Get("client_id") != p.ClientID {
+		return "", errors.New("client_id tidak dikenal")
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		return "", errors.New("permintaan tanpa PKCE S256")
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	kode := base64.RawURLEncoding.EncodeToString(b)
+
+	p.mu.Lock()
+	p.kode[kode] = otorisasi{
+		challenge: q.Get("code_challenge"),
+		nonce:     q.Get("nonce"),
+		klaim:     klaim,
+	}
+	p.mu.Unlock()
+	return kode, nil
+}
+
+func (p *Penyedia) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                p.URL,
+		"authorization_endpoint":                p.URL + "/authorize",
+		"token_endpoint":                        p.URL + "/token",
+		"jwks_uri":                              p.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+func (p *Penyedia) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &p.kunci.PublicKey,
+		KeyID:     kid,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+func (p *Penyedia) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Code hanya berlaku sekali
+	p.mu.Lock()
+	o, ada := p.kode[r.Form.Get("code")]
+	delete(p.kode, r.Form.Get("code"))
+	p.mu.Unlock()
+
+	hash := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+	if !ada || base64.RawURLEncoding.EncodeToString(hash[:]) != o.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	klaim := map[string]any{
+		"iss":   p.URL,
+		"aud":   p.ClientID,
+		"sub":   "user",
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": o.nonce,
+	}
+	for k, v := range o.klaim {
+		klaim[k] = v
+	}
+	idToken, err := p.tandatangani(klaim)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "akses",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idToken,
+	})
+}
+
+func (p *Penyedia) tandatangani(klaim map[string]any) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: p.kunci},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid),
+	)
+	if err != nil {
+		return "", err
+	}
+	isi, err := json.Marshal(klaim)
+	if err != nil {
+		return "", err
+	}
+	jws, err := signer.Sign(isi)
+	if err != nil {
+		return "", err
+	}
+	return jws.CompactSerialize()
+}
@@ -0,0 +1,200 @@
+// Package s3test menyediakan object storage tiruan yang kompatibel dengan S3
+// untuk menguji storage.S3 tanpa MinIO sungguhan. Hanya operasi yang dipakai
+// storage.S3 yang didukung: cek/buat bucket serta put, stat, get dan delete
+// objek. Tanda tangan permintaan tidak diperiksa.
+package s3test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"forum_asisten/storage"
+)
+
+type objek struct {
+	isi         []byte
+	contentType string
+	etag        string
+	diubah      time.Time
+}
+
+// Server adalah server S3 tiruan di dalam proses.
+type Server struct {
+	Endpoint string // host:port tanpa skema, seperti S3_ENDPOINT
+
+	server *httptest.Server
+
+	mu     sync.Mutex
+	bucket map[string]map[string]objek
+}
+
+func Baru() *Server {
+	s := &Server{bucket: map[string]map[string]objek{}}
+	s.server = httptest.NewServer(http.HandlerFunc(s.layani))
+	s.Endpoint = strings.TrimPrefix(s.server.URL, "http://")
+	return s
+}
+
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Config mengembalikan konfigurasi storage.S3 yang mengarah ke server ini.
+func (s *Server) Config(bucket string) storage.S3Config {
+	return storage.S3Config{
+		Endpoint:  s.Endpoint,
+		AccessKey: "s3test",
+		SecretKey: "s3test-rahasia",
+		Bucket:    bucket,
+		Region:    "us-east-1",
+	}
+}
+
+func (s *Server) layani(w http.ResponseWriter, r *http.Request) {
+	// Gaya path: /bucket atau /bucket/key
+	bagian := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket := bagian[0]
+	if bucket == "" {
+		galat(w, r, http.StatusNotImplemented, "NotImplemented")
+		return
+	}
+	if len(bagian) == 1 || bagian[1] == "" {
+		s.layaniBucket(w, r, bucket)
+		return
+	}
+	s.layaniObjek(w, r, bucket, bagian[1])
+}
+
+func (s *Server) layaniBucket(w http.ResponseWriter, r *http.Request, bucket string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ada := s.bucket[bucket]
+
+	switch r.Method {
+	case http.MethodHead:
+		if !ada {
+			galat(w, r, http.StatusNotFound, "NoSuchBucket")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPut:
+		if ada {
+			galat(w, r, http.StatusConflict, "BucketAlreadyOwnedByYou")
+			return
+		}
+		s.bucket[bucket] = map[string]objek{}
+		w.WriteHeader(http.StatusOK)
+	default:
+		galat(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (s *Server) layaniObjek(w http.ResponseWriter, r *http.Request, bucket, key string) {
+	var isi []byte
+	if r.Method == http.MethodPut {
+		var err error
+		if isi, err = bacaIsi(r); err != nil {
+			galat(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ada := s.bucket[bucket]
+	if !ada {
+		galat(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+	o, ada := b[key]
+
+	switch r.Method {
+	case http.MethodPut:
+		sum := md5.Sum(isi)
+		o = objek{
+			isi:         isi,
+			contentType: r.Header.Get("Content-Type"),
+			etag:        `"` + hex.EncodeToString(sum[:]) + `"`,
+			diubah:      time.Now().UTC(),
+		}
+		b[key] = o
+		w.Header().Set("ETag", o.etag)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodHead, http.MethodGet:
+		if !ada {
+			galat(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", o.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(o.isi)))
+		w.Header().Set("ETag", o.etag)
+		w.Header().Set("Last-Modified", o.diubah.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(o.isi)
+		}
+	case http.MethodDelete:
+		// S3 tidak menganggap objek yang tidak ada sebagai galat
+		delete(b, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		galat(w, r, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+// bacaIsi membaca body PUT. Client S3 melalui HTTP biasa mengirim body
+// berformat aws-chunked: "<ukuran hex>[;chunk-signature=...]\r\n<data>\r\n",
+// diakhiri potongan berukuran 0 dan trailer opsional.
+func bacaIsi(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var isi bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		baris, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		ukuranHex, _, _ := strings.Cut(strings.TrimSpace(baris), ";")
+		ukuran, err := strconv.ParseInt(ukuranHex, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("ukuran potongan tidak valid: %q", baris)
+		}
+		if ukuran == 0 {
+			break
+		}
+		if _, err := io.CopyN(&isi, br, ukuran); err != nil {
+			return nil, err
+		}
+		if _, err := br.Discard(2); err != nil { // \r\n penutup potongan
+			return nil, err
+		}
+	}
+	if panjang := r.Header.Get("X-Amz-Decoded-Content-Length"); panjang != "" && panjang != strconv.Itoa(isi.Len()) {
+		return nil, fmt.Errorf("panjang isi %d, diharapkan %s", isi.Len(), panjang)
+	}
+	return isi.Bytes(), nil
+}
+
+func galat(w http.ResponseWriter, r *http.Request, status int, kode string) {
+	if r.Method == http.MethodHead {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message><Resource>%s</Resource></Error>`,
+		kode, kode, r.URL.Path)
+}
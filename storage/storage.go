@@ -0,0 +1,26 @@
+// Package storage menyediakan penyimpanan berkas unggahan (bukti presensi,
+// foto profil) di disk lokal atau object storage yang kompatibel dengan S3.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrNotFound = errors.New("berkas tidak ditemukan")
+
+// Info berisi metadata berkas yang tersimpan.
+type Info struct {
+	ContentType string
+	Size        int64
+}
+
+// Storage adalah abstraksi tempat penyimpanan berkas berdasarkan key
+// (mis. "presensi/ab/abcdef....jpg").
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, Info, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Delete(ctx context.Context, key string) error
+}
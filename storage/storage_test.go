@@ -0,0 +1,77 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"forum_asisten/storage"
+	"forum_asisten/storage/s3test"
+)
+
+// backend menjalankan test pada setiap implementasi Storage.
+func backend(t *testing.T, uji func(t *testing.T, st storage.Storage)) {
+	t.Run("local", func(t *testing.T) {
+		t.Parallel()
+		st, err := storage.NewLocal(t.TempDir())
+		if err != nil {
+			t.Fatal(err)
+		}
+		uji(t, st)
+	})
+	t.Run("s3", func(t *testing.T) {
+		t.Parallel()
+		srv := s3test.Baru()
+		t.Cleanup(srv.Close)
+		st, err := storage.NewS3(context.Background(), srv.Config("forum-asisten"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		uji(t, st)
+	})
+}
+
+func TestStorage(t *testing.T) {
+	t.Parallel()
+	backend(t, func(t *testing.T, st storage.Storage) {
+		ctx := context.Background()
+		const key = "presensi/ab/abcdef.png"
+
+		if ada, err := st.Exists(ctx, key); err != nil || ada {
+			t.Fatalf("Exists sebelum Put = %v, %v", ada, err)
+		}
+		if _, _, err := st.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+			t.Fatalf("Get sebelum Put = %v, want ErrNotFound", err)
+		}
+
+		isi := "isi berkas"
+		if err := st.Put(ctx, key, strings.NewReader(isi), int64(len(isi)), "image/png"); err != nil {
+			t.Fatal(err)
+		}
+		if ada, err := st.Exists(ctx, key); err != nil || !ada {
+			t.Fatalf("Exists setelah Put = %v, %v", ada, err)
+		}
+
+		r, info, err := st.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != isi || info.ContentType != "image/png" || info.Size != int64(len(isi)) {
+			t.Fatalf("Get = %q, %+v", got, info)
+		}
+
+		if err := st.Delete(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+		if ada, _ := st.Exists(ctx, key); ada {
+			t.Fatal("berkas masih ada setelah Delete")
+		}
+	})
+}
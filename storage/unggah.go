@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // registrasi decoder GIF
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+var (
+	ErrTerlaluBesar      = errors.New("ukuran berkas melebihi batas")
+	ErrTipeTidakDidukung = errors.New("tipe berkas tidak didukung")
+)
+
+// Batas mengatur ukuran maksimum unggahan dan dimensi hasil olahan gambar.
+type Batas struct {
+	MaksUkuran       int64 // byte
+	MaksPiksel       int   // lebar × tinggi maksimum gambar asli, diperiksa sebelum decode
+	MaksDimensi      int   // sisi terpanjang gambar setelah diolah, piksel
+	DimensiThumbnail int   // sisi terpanjang thumbnail, piksel
+}
+
+// Hasil adalah berkas yang sudah tersimpan di storage.
+type Hasil struct {
+	Key          string
+	ThumbnailKey string
+	ContentType  string
+	Ukuran       int64
+	SHA256       string
+}
+
+// Unggah membaca berkas dari r, memeriksa tipe berdasarkan isinya (bukan
+// nama berkas), mengolah ulang gambar (membuang metadata, memperkecil,
+// membuat thumbnail), lalu menyimpannya dengan nama berdasarkan hash isi
+// sehingga berkas yang sama hanya tersimpan sekali.
+func Unggah(ctx context.Context, st Storage, prefix string, r io.Reader, batas Batas, izinkan ...string) (Hasil, error) {
+	data, err := io.ReadAll(io.LimitReader(r, batas.MaksUkuran+1))
+	if err != nil {
+		return Hasil{}, err
+	}
+	if int64(len(data)) > batas.MaksUkuran {
+		return Hasil{}, ErrTerlaluBesar
+	}
+
+	contentType := http.DetectContentType(data)
+	if !diizinkan(contentType, izinkan) {
+		return Hasil{}, fmt.Errorf("%w: %s", ErrTipeTidakDidukung, contentType)
+	}
+
+	var thumbnail []byte
+	ext := ".pdf"
+	if contentType != "application/pdf" {
+		// Periksa dimensi dari header dulu: gambar kecil di disk dapat
+		// mengembang menjadi gigabyte piksel saat didecode
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return Hasil{}, fmt.Errorf("%w: gambar rusak", ErrTipeTidakDidukung)
+		}
+		if batas.MaksPiksel > 0 && int64(cfg.Width)*int64(cfg.Height) > int64(batas.MaksPiksel) {
+			return Hasil{}, fmt.Errorf("%w: gambar %dx%d piksel", ErrTerlaluBesar, cfg.Width, cfg.Height)
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Hasil{}, fmt.Errorf("%w: gambar rusak", ErrTipeTidakDidukung)
+		}
+
+		// GIF disimpan sebagai PNG (frame pertama)
+		if contentType == "image/gif" {
+			contentType = "image/png"
+		}
+		if data, err = encode(perkecil(img, batas.MaksDimensi), contentType); err != nil {
+			return Hasil{}, err
+		}
+		if thumbnail, err = encode(perkecil(img, batas.DimensiThumbnail), contentType); err != nil {
+			return Hasil{}, err
+		}
+		ext = ".jpg"
+		if contentType == "image/png" {
+			ext = ".png"
+		}
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	hasil := Hasil{
+		Key:         fmt.Sprintf("%s/%s/%s%s", prefix, hash[:2], hash, ext),
+		ContentType: contentType,
+		Ukuran:      int64(len(data)),
+		SHA256:      hash,
+	}
+	if err := simpanJikaBelumAda(ctx, st, hasil.Key, data, contentType); err != nil {
+		return Hasil{}, err
+	}
+
+	if thumbnail != nil {
+		hasil.ThumbnailKey = fmt.Sprintf("%s/%s/%s_thumb%s", prefix, hash[:2], hash, ext)
+		if err := simpanJikaBelumAda(ctx, st, hasil.ThumbnailKey, thumbnail, contentType); err != nil {
+			return Hasil{}, err
+		}
+	}
+	return hasil, nil
+}
+
+func diizinkan(contentType string, izinkan []string) bool {
+	for _, t := range izinkan {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+func simpanJikaBelumAda(ctx context.Context, st Storage, key string, data []byte, contentType string) error {
+	exists, err := st.Exists(ctx, key)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	return st.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// perkecil memperkecil gambar secara proporsional jika sisi terpanjangnya
+// melebihi maks. Gambar selalu digambar ulang agar metadata (EXIF) terbuang.
+func perkecil(img image.Image, maks int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maks > 0 && (w > maks || h > maks) {
+		if w >= h {
+			h = h * maks / w
+			w = maks
+		} else {
+			w = w * maks / h
+			h = maks
+		}
+	}
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/png" {
+		err = png.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	}
+	return buf.Bytes(), err
+}
@@ -0,0 +1,192 @@
+package storage_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"forum_asisten/storage"
+)
+
+var batasUji = storage.Batas{
+	MaksUkuran:       1 << 20,
+	MaksPiksel:       1_000_000,
+	MaksDimensi:      400,
+	DimensiThumbnail: 100,
+}
+
+var tipeGambarDanPDF = []string{"image/jpeg", "image/png", "image/gif", "application/pdf"}
+
+func gambar(t *testing.T, format string, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for x := 0; x < w; x++ {
+		img.Set(x, x*h/w, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	case "jpeg":
+		err = jpeg.Encode(&buf, img, nil)
+	case "gif":
+		err = gif.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// baca mengambil berkas tersimpan beserta content type-nya.
+func baca(t *testing.T, st storage.Storage, key string) ([]byte, string) {
+	t.Helper()
+	r, info, err := st.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%q): %v", key, err)
+	}
+	defer r.Close()
+	isi, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return isi, info.ContentType
+}
+
+func dimensi(t *testing.T, isi []byte) (int, int) {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(isi))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+func TestUnggahDitolak(t *testing.T) {
+	t.Parallel()
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<<>>\nendobj\n")
+	png := gambar(t, "png", 10, 10)
+
+	tests := []struct {
+		nama    string
+		isi     []byte
+		izinkan []string
+		galat   error
+	}{
+		{"teks biasa", []byte("bukan gambar"), tipeGambarDanPDF, storage.ErrTipeTidakDidukung},
+		{"html berekstensi gambar", []byte("<html><script>alert(1)</script></html>"), tipeGambarDanPDF, storage.ErrTipeTidakDidukung},
+		{"pdf saat hanya gambar diizinkan", pdf, []string{"image/png", "image/jpeg"}, storage.ErrTipeTidakDidukung},
+		{"png saat hanya pdf diizinkan", png, []string{"application/pdf"}, storage.ErrTipeTidakDidukung},
+		{"header png dengan isi rusak", png[:40], tipeGambarDanPDF, storage.ErrTipeTidakDidukung},
+		{"melebihi ukuran", append(pdf, bytes.Repeat([]byte{' '}, int(batasUji.MaksUkuran))...), tipeGambarDanPDF, storage.ErrTerlaluBesar},
+		{"melebihi jumlah piksel", gambar(t, "png", 2000, 501), tipeGambarDanPDF, storage.ErrTerlaluBesar},
+	}
+	backend(t, func(t *testing.T, st storage.Storage) {
+		for _, tt := range tests {
+			_, err := storage.Unggah(context.Background(), st, "presensi", bytes.NewReader(tt.isi), batasUji, tt.izinkan...)
+			if !errors.Is(err, tt.galat) {
+				t.Errorf("%s: Unggah() = %v, want %v", tt.nama, err, tt.galat)
+			}
+		}
+	})
+}
+
+func TestUnggahGambar(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		nama        string
+		isi         []byte
+		contentType string
+		ext         string
+		w, h        int // dimensi hasil olahan
+		tw, th      int // dimensi thumbnail
+	}{
+		{"png diperkecil", gambar(t, "png", 800, 400), "image/png", ".png", 400, 200, 100, 50},
+		{"jpeg potret diperkecil", gambar(t, "jpeg", 300, 600), "image/jpeg", ".jpg", 200, 400, 50, 100},
+		{"gif disimpan sebagai png", gambar(t, "gif", 40, 20), "image/png", ".png", 40, 20, 40, 20},
+	}
+	backend(t, func(t *testing.T, st storage.Storage) {
+		for _, tt := range tests {
+			hasil, err := storage.Unggah(context.Background(), st, "foto", bytes.NewReader(tt.isi), batasUji, tipeGambarDanPDF...)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.nama, err)
+			}
+			if hasil.ContentType != tt.contentType {
+				t.Errorf("%s: content type = %q, want %q", tt.nama, hasil.ContentType, tt.contentType)
+			}
+
+			isi, contentType := baca(t, st, hasil.Key)
+			if contentType != tt.contentType {
+				t.Errorf("%s: content type tersimpan = %q", tt.nama, contentType)
+			}
+			if bytes.Equal(isi, tt.isi) {
+				t.Errorf("%s: gambar tidak diolah ulang", tt.nama)
+			}
+			if w, h := dimensi(t, isi); w != tt.w || h != tt.h {
+				t.Errorf("%s: dimensi = %dx%d, want %dx%d", tt.nama, w, h, tt.w, tt.h)
+			}
+
+			want := strings.TrimSuffix(hasil.Key, tt.ext) + "_thumb" + tt.ext
+			if hasil.ThumbnailKey != want {
+				t.Errorf("%s: thumbnail key = %q, want %q", tt.nama, hasil.ThumbnailKey, want)
+			}
+			thumb, _ := baca(t, st, hasil.ThumbnailKey)
+			if w, h := dimensi(t, thumb); w != tt.tw || h != tt.th {
+				t.Errorf("%s: dimensi thumbnail = %dx%d, want %dx%d", tt.nama, w, h, tt.tw, tt.th)
+			}
+		}
+	})
+}
+
+// TestUnggahBerdasarkanIsi memastikan key diturunkan dari hash isi sehingga
+// berkas yang sama hanya tersimpan sekali.
+func TestUnggahBerdasarkanIsi(t *testing.T) {
+	t.Parallel()
+	pdf := []byte("%PDF-1.4\n% bukti izin\n")
+	sum := sha256.Sum256(pdf)
+	hash := hex.EncodeToString(sum[:])
+
+	backend(t, func(t *testing.T, st storage.Storage) {
+		ctx := context.Background()
+		pertama, err := storage.Unggah(ctx, st, "presensi", bytes.NewReader(pdf), batasUji, tipeGambarDanPDF...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "presensi/" + hash[:2] + "/" + hash + ".pdf"; pertama.Key != want {
+			t.Fatalf("key = %q, want %q", pertama.Key, want)
+		}
+		if pertama.SHA256 != hash || pertama.Ukuran != int64(len(pdf)) || pertama.ThumbnailKey != "" {
+			t.Fatalf("hasil = %+v", pertama)
+		}
+		if isi, contentType := baca(t, st, pertama.Key); !bytes.Equal(isi, pdf) || contentType != "application/pdf" {
+			t.Fatalf("isi tersimpan = %q (%s)", isi, contentType)
+		}
+
+		kedua, err := storage.Unggah(ctx, st, "presensi", bytes.NewReader(pdf), batasUji, tipeGambarDanPDF...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kedua != pertama {
+			t.Fatalf("unggahan ulang = %+v, want %+v", kedua, pertama)
+		}
+
+		lain, err := storage.Unggah(ctx, st, "presensi", bytes.NewReader(append(pdf, '\n')), batasUji, tipeGambarDanPDF...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lain.Key == pertama.Key {
+			t.Fatal("isi berbeda menghasilkan key yang sama")
+		}
+	})
+}
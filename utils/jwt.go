@@ -2,43 +2,52 @@ package utils
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte(os.Getenv("JWT_SECRET"))
+// JWT menandatangani dan memverifikasi access token dengan kunci HMAC.
+type JWT struct {
+	kunci []byte
+}
+
+// NewJWT membuat penanda tangan token dari secret JWT_SECRET.
+func NewJWT(secret string) *JWT {
+	return &JWT{kunci: []byte(secret)}
+}
 
-func GenerateJWT(userID uint, email string, nama string, nim string, role string) (string, error) {
+// Generate membuat access token berumur pendek. jti dipakai untuk mencabut
+// token saat logout, ver dicocokkan dengan versi token user sehingga semua
+// token lama gugur saat versi dinaikkan.
+func (j *JWT) Generate(userID uint, email string, nama string, nim string, role string, versi uint, ttl time.Duration) (string, error) {
+	jti, err := TokenAcak()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"user_id": userID,
 		"email":   email,
 		"nama":    nama,
 		"nim":     nim,
 		"role":    role,
-		"exp":     time.Now().Add(72 * time.Hour).Unix(),
+		"ver":     versi,
+		"jti":     jti,
+		"iat":     now.Unix(),
+		"exp":     now.Add(ttl).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
-}
-
-func ParseToken(tokenStr string) (jwt.MapClaims, error) {
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
-
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims, nil
-	} else {
-		return nil, err
-	}
+	return token.SignedString(j.kunci)
 }
 
-func VerifyToken(tokenStr string) (jwt.MapClaims, error) {
+// Verify memeriksa tanda tangan dan masa berlaku token lalu mengembalikan
+// claims-nya. Hanya HS256 yang diterima.
+func (j *JWT) Verify(tokenStr string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
-	})
+		return j.kunci, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil || !token.Valid {
 		return nil, err
 	}
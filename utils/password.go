@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// ValidasiPassword menerapkan aturan kekuatan password: 8-72 byte (batas
+// bcrypt), memuat huruf dan angka, dan tidak sama dengan identitas user.
+func ValidasiPassword(password string, identitas ...string) error {
+	if len(password) < 8 {
+		return errors.New("Password minimal 8 karakter")
+	}
+	if len(password) > 72 {
+		return errors.New("Password maksimal 72 byte")
+	}
+
+	var huruf, angka bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			huruf = true
+		case unicode.IsDigit(r):
+			angka = true
+		}
+	}
+	if !huruf || !angka {
+		return errors.New("Password harus memuat huruf dan angka")
+	}
+
+	for _, id := range identitas {
+		if id != "" && strings.EqualFold(password, id) {
+			return errors.New("Password tidak boleh sama dengan email atau NIM")
+		}
+	}
+	return nil
+}
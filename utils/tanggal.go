@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var namaHari = map[string]time.Weekday{
+	"minggu": time.Sunday,
+	"senin":  time.Monday,
+	"selasa": time.Tuesday,
+	"rabu":   time.Wednesday,
+	"kamis":  time.Thursday,
+	"jumat":  time.Friday,
+	"jum'at": time.Friday,
+	"sabtu":  time.Saturday,
+}
+
+// ParseHari mengubah nama hari dalam bahasa Indonesia (mis. "Senin") menjadi time.Weekday.
+func ParseHari(hari string) (time.Weekday, error) {
+	weekday, ok := namaHari[strings.ToLower(strings.TrimSpace(hari))]
+	if !ok {
+		return 0, fmt.Errorf("hari %q tidak dikenal", hari)
+	}
+	return weekday, nil
+}
+
+// TanggalMingguan mengembalikan setiap tanggal dengan hari yang sama
+// antara mulai dan selesai (inklusif).
+func TanggalMingguan(mulai, selesai time.Time, hari time.Weekday) []time.Time {
+	mulai = time.Date(mulai.Year(), mulai.Month(), mulai.Day(), 0, 0, 0, 0, mulai.Location())
+	selisih := (int(hari) - int(mulai.Weekday()) + 7) % 7
+
+	var hasil []time.Time
+	for t := mulai.AddDate(0, 0, selisih); !t.After(selesai); t = t.AddDate(0, 0, 7) {
+		hasil = append(hasil, t)
+	}
+	return hasil
+}
+
+// GabungTanggalJam menggabungkan tanggal pertemuan dengan jam "HH:MM" pada zona waktu lokasi.
+func GabungTanggalJam(tanggal time.Time, jam string, lokasi *time.Location) (time.Time, error) {
+	j, err := time.Parse("15:04", jam)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("format jam %q harus HH:MM", jam)
+	}
+	return time.Date(tanggal.Year(), tanggal.Month(), tanggal.Day(), j.Hour(), j.Minute(), 0, 0, lokasi), nil
+}
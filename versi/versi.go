@@ -0,0 +1,54 @@
+// Package versi menyediakan informasi build aplikasi. Versi dan Commit diisi
+// saat build lewat ldflags:
+//
+//	go build -ldflags "-X forum_asisten/versi.Versi=v1.2.0 -X forum_asisten/versi.Commit=$(git rev-parse --short HEAD)"
+//
+// Jika tidak diisi, commit dan waktu commit diambil dari info VCS yang
+// disematkan toolchain Go saat build di dalam repositori git.
+package versi
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Diisi lewat -ldflags "-X ...".
+var (
+	Versi  = "dev"
+	Commit = ""
+	Waktu  = "" // waktu build, RFC 3339
+)
+
+// Info adalah informasi build yang dilaporkan endpoint versi.
+type Info struct {
+	Versi      string `json:"versi"`
+	Commit     string `json:"commit"`
+	Waktu      string `json:"waktu,omitempty"` // waktu build, atau waktu commit dari info VCS
+	Modifikasi bool   `json:"modifikasi"`      // dibangun dari working tree yang belum di-commit
+	Go         string `json:"go"`
+}
+
+// Baca mengembalikan informasi build binary yang sedang berjalan.
+func Baca() Info {
+	info := Info{Versi: Versi, Commit: Commit, Waktu: Waktu, Go: runtime.Version()}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Waktu == "" {
+				info.Waktu = s.Value
+			}
+		case "vcs.modified":
+			info.Modifikasi = s.Value == "true"
+		}
+	}
+	return info
+}